@@ -1,16 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kennyg37/wrapperX/backend/internal/config"
 	"github.com/kennyg37/wrapperX/backend/internal/database"
 	"github.com/kennyg37/wrapperX/backend/internal/handlers"
 	"github.com/kennyg37/wrapperX/backend/internal/middleware"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
 	"github.com/kennyg37/wrapperX/backend/internal/services"
 )
 
@@ -37,14 +40,44 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	if err := db.BackfillScenarioHashes(models.ScenarioHash); err != nil {
+		log.Fatalf("Failed to backfill scenario hashes: %v", err)
+	}
+
 	// Initialize services and handlers
-	openaiService := services.NewOpenAIService(cfg.OpenAIAPIKey)
+	openaiOptions := services.OpenAIOptions{
+		RedactLogs:     cfg.LogRedact,
+		UsageRecorder:  db,
+		UseNumber:      cfg.JSONUseNumber,
+		LogPromptCache: cfg.LogPromptCache,
+		DefaultModel:   cfg.OpenAIModel,
+		Temperature:    &cfg.OpenAITemperature,
+		MaxAttempts:    cfg.OpenAIMaxAttempts,
+
+		ChunkConcurrency: cfg.GenerateChunkConcurrency,
+	}
+	if cfg.AzureOpenAIEndpoint != "" {
+		log.Printf("Using Azure OpenAI deployment %s at %s", cfg.AzureOpenAIDeployment, cfg.AzureOpenAIEndpoint)
+		openaiOptions.Azure = &services.AzureOptions{
+			Endpoint:   cfg.AzureOpenAIEndpoint,
+			Deployment: cfg.AzureOpenAIDeployment,
+			APIVersion: cfg.AzureOpenAIAPIVersion,
+		}
+	}
+	if cfg.OpenAIMode == config.OpenAIModeMock {
+		openaiOptions.Mock = &services.MockOptions{
+			Latency:     cfg.OpenAIMockLatency,
+			FailureRate: cfg.OpenAIMockFailureRate,
+		}
+	}
+	openaiService := services.NewOpenAIService(cfg.OpenAIAPIKey, openaiOptions)
 	exportService := services.NewExportService()
+	fpeService := services.NewFPEService(cfg.FPEKey)
 
-	handler := handlers.NewHandler(db, openaiService, exportService)
+	handler := handlers.NewHandler(cfg, db, handlers.NewGenerator(cfg, openaiService, openaiOptions), exportService, fpeService)
 
 	app := fiber.New(fiber.Config{
-		AppName: "Mock Data Generator API v1.0",
+		AppName: handlers.ServiceName + " v" + handlers.Version,
 		ErrorHandler: customErrorHandler,
 
 		// BodyLimit: maximum request body size (10MB)
@@ -52,22 +85,79 @@ func main() {
 	})
 
 
+	app.Use(middleware.RequestID())
 	app.Use(middleware.Recovery())
-	app.Use(middleware.Logger())
+	app.Use(middleware.Logger(cfg.LogFormat))
+	if len(cfg.CORSOrigins) == 1 && cfg.CORSOrigins[0] == config.AnyOrigin {
+		log.Println("⚠️ CORS_ORIGINS=* lets any site call the API; credentialed requests are disabled")
+	}
 	app.Use(middleware.CORS(cfg.CORSOrigins))
+	if cfg.EnableCompression {
+		app.Use(middleware.Compression())
+	}
 
-	// API routes
-	api := app.Group("/api")
-
-	api.Get("/health", handler.HealthCheck)
-
-	api.Post("/generate", handler.GenerateMockData)
-	api.Get("/requests", handler.ListGenerationRequests)
-	api.Get("/requests/:id", handler.GetGenerationRequest)
+	// Landing document listing the available endpoints
+	app.Get("/", handler.Root)
 
-	api.Get("/data/:id", handler.GetMockData)
-	api.Get("/data/:id/export", handler.ExportMockData)
+	if len(cfg.APIKeys) == 0 {
+		log.Println("⚠️ API_KEYS is not set, the API is open to anyone who can reach it")
+	}
 
+	// API routes
+	api := app.Group("/api", middleware.APIKeyAuth(cfg.APIKeys), middleware.RequireContentType(cfg.AcceptedContentTypes))
+
+	// Server-side deadlines: generous for generation, tight for reads
+	generateTimeout := middleware.Timeout(cfg.GenerateTimeout)
+	readTimeout := middleware.Timeout(cfg.ReadTimeout)
+
+	// Probes: liveness only needs the process, readiness also the database
+	// and generator; /health predates them and stays as a readiness alias
+	api.Get("/live", handler.LivenessCheck)
+	api.Get("/ready", handler.ReadinessCheck)
+	api.Get("/health", handler.ReadinessCheck)
+	api.Get("/capabilities", handler.GetCapabilities)
+
+	api.Post("/generate", generateTimeout, handler.GenerateMockData)
+	api.Get("/requests", readTimeout, handler.ListGenerationRequests)
+	api.Get("/requests/:id", readTimeout, handler.GetGenerationRequest)
+	api.Post("/requests/:id/pin", readTimeout, handler.PinRequest)
+	api.Post("/requests/:id/unpin", readTimeout, handler.UnpinRequest)
+	api.Post("/requests/:id/regenerate", generateTimeout, handler.RegenerateRequest)
+
+	api.Get("/data/:id", readTimeout, handler.GetMockData)
+	api.Get("/data/:id/preview", readTimeout, handler.PreviewMockData)
+	api.Get("/data/:id/export", readTimeout, handler.ExportMockData)
+	api.Get("/data/:id/export/all", readTimeout, handler.ExportAllFormats)
+	api.Post("/data/:id/export/template", readTimeout, handler.ExportTemplate)
+	api.Get("/data/:id/quality", readTimeout, handler.GetQuality)
+	api.Post("/data/:id/sql/validate", readTimeout, handler.ValidateSQL)
+	api.Patch("/data/:id/coerce", readTimeout, handler.CoerceFields)
+	api.Post("/data/:id/infer-types", readTimeout, handler.InferFieldTypes)
+	api.Get("/data/:id/versions/export", readTimeout, handler.ExportVersions)
+	api.Get("/export/bundle", readTimeout, handler.ExportBundle)
+	api.Post("/export/bundle", readTimeout, handler.StartBundleExport)
+	api.Get("/export/merged", readTimeout, handler.ExportMerged)
+
+	api.Get("/jobs/:id", readTimeout, handler.GetJob)
+	api.Get("/jobs/:id/result", readTimeout, handler.GetJobResult)
+	api.Get("/scenarios/popular", readTimeout, handler.PopularScenarios)
+
+	// Admin routes (require X-Admin-Key)
+	admin := api.Group("/admin", middleware.AdminAuth(cfg.AdminAPIKey))
+	admin.Post("/regenerate", handler.RegenerateRequests)
+	admin.Get("/validation-stats", handler.ValidationStats)
+	admin.Get("/usage", readTimeout, handler.GetUsageSummary)
+	admin.Delete("/requests", handler.PurgeRequests)
+
+
+	// Background retention cleanup (skips pinned requests)
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	if cfg.RetentionDays > 0 {
+		retention := time.Duration(cfg.RetentionDays) * 24 * time.Hour
+		go db.RunRetentionJanitor(janitorCtx, retention, time.Hour)
+		log.Printf("Retention: purging unpinned requests older than %d days", cfg.RetentionDays)
+	}
 
 	// Channel to listen for shutdown signal
 	quit := make(chan os.Signal, 1)
@@ -91,6 +181,17 @@ func main() {
 		log.Printf("Server shutdown error: %v", err)
 	}
 
+	// Finish the generations already accepted, up to SHUTDOWN_TIMEOUT
+	shutdownCtx := context.Background()
+	if cfg.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, cfg.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := handler.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Generation queue shutdown error: %v", err)
+	}
+
 	log.Println("Server stopped gracefully")
 }
 
@@ -106,11 +207,13 @@ func customErrorHandler(c *fiber.Ctx, err error) error {
 		message = e.Message
 	}
 
-	log.Printf("Error: %v", err)
+	requestID := middleware.GetRequestID(c)
+	log.Printf("Error [%s]: %v", requestID, err)
 
 	return c.Status(code).JSON(fiber.Map{
-		"error":   message,
-		"message": err.Error(),
+		"error":      message,
+		"message":    err.Error(),
+		"request_id": requestID,
 	})
 }
 
@@ -2,21 +2,180 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// OpenAI modes accepted by OPENAI_MODE
+const (
+	OpenAIModeLive = "live"
+	OpenAIModeMock = "mock"
+)
+
+// Generators accepted by GENERATOR_PROVIDER
+const (
+	GeneratorOpenAI    = "openai"
+	GeneratorAnthropic = "anthropic"
+	GeneratorOllama    = "ollama"
+	GeneratorTemplate  = "template"
+)
+
+// Request log formats accepted by LOG_FORMAT
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Row count policies accepted by ROW_COUNT_POLICY
+const (
+	RowCountWarn     = "warn"
+	RowCountTruncate = "truncate"
+	RowCountTopUp    = "topup"
+)
+
 type Config struct {
 	Port        string
 	Environment string
 
 	OpenAIAPIKey string
 
+	// OpenAIModel is the chat model used when a request does not choose one
+	OpenAIModel string
+
+	// OpenAITemperature is the sampling temperature when a request sets none
+	OpenAITemperature float64
+
+	// OpenAIMaxAttempts bounds the calls per completion when OpenAI answers
+	// with a rate limit or server error
+	OpenAIMaxAttempts int
+
+	// GenerateChunkConcurrency bounds the chunks of a large request that
+	// are generated in parallel
+	GenerateChunkConcurrency int
+
 	Database DatabaseConfig
 
 	CORSOrigins []string
+
+	// AdminAPIKey protects admin-only operations; empty disables them
+	AdminAPIKey string
+
+	// APIKeys are the keys accepted from API clients; empty leaves the API open
+	APIKeys []string
+
+	// FPEKey enables reversible format-preserving encryption of fields
+	FPEKey string
+
+	// RegenerateConcurrency bounds parallel generations in bulk regenerate
+	RegenerateConcurrency int
+
+	// GenerateWorkers is the number of background workers running generate
+	// requests; GenerateQueueSize bounds the requests waiting for one
+	GenerateWorkers   int
+	GenerateQueueSize int
+
+	// ExportConcurrency bounds parallel dataset serialization in bundle exports
+	ExportConcurrency int
+
+	// LogRedact hides scenario and response text in logs
+	LogRedact bool
+
+	// LogFormat is "text" or "json" (one JSON object per request) for the request log
+	LogFormat string
+
+	// AcceptedContentTypes lists media types allowed for request bodies
+	AcceptedContentTypes []string
+
+	// Scenario keyword policy; deny wins, a non-empty allow list restricts
+	// generation to matching topics
+	ScenarioAllow []string
+	ScenarioDeny  []string
+
+	// JSONUseNumber keeps generated numbers exact instead of float64
+	JSONUseNumber bool
+
+	// LogPromptCache logs cached prompt tokens of every OpenAI call
+	LogPromptCache bool
+
+	// Generator is "openai", "anthropic", "ollama" for a local model, or
+	// "template" for deterministic data from field-name heuristics without
+	// any API calls
+	Generator string
+
+	// Anthropic settings, used with GENERATOR_PROVIDER=anthropic
+	AnthropicAPIKey string
+	AnthropicModel  string
+
+	// Azure OpenAI settings; with an endpoint set, OpenAI calls go to the
+	// deployment using OPENAI_API_KEY, and OPENAI_MODEL names its model
+	AzureOpenAIEndpoint   string
+	AzureOpenAIDeployment string
+	AzureOpenAIAPIVersion string
+
+	// Ollama settings, used with GENERATOR_PROVIDER=ollama; OllamaHost is the base
+	// URL of a server with an OpenAI-compatible chat completions endpoint
+	OllamaHost  string
+	OllamaModel string
+
+	// OpenAIMode is "live" or "mock"; mock answers with deterministic local
+	// data after OpenAIMockLatency and fails OpenAIMockFailureRate of calls
+	OpenAIMode            string
+	OpenAIMockLatency     time.Duration
+	OpenAIMockFailureRate float64
+
+	// MaxScenarioLength caps the scenario description in characters
+	MaxScenarioLength int
+
+	// MaxRowCount caps the rows a single request may generate
+	MaxRowCount int
+
+	// GenerationCacheTTL keeps generated data for identical requests this
+	// long (0 disables the cache), at most GenerationCacheSize entries
+	GenerationCacheTTL  time.Duration
+	GenerationCacheSize int
+
+	// FallbackEnabled serves placeholder data when OpenAI fails
+	FallbackEnabled bool
+
+	// RowCountPolicy handles model output with more or fewer rows than
+	// requested: warn keeps it, truncate drops extras, topup also
+	// generates the missing rows
+	RowCountPolicy string
+
+	// DiversityThreshold flags non-categorical fields whose distinct-value
+	// ratio is lower; DiversityRegenerate refills those fields once
+	DiversityThreshold  float64
+	DiversityRegenerate bool
+
+	// FieldPromptsEnabled allows the token-heavy per-field prompt mode
+	FieldPromptsEnabled bool
+
+	// FieldPromptTokenBudget caps the estimated extra tokens per request
+	FieldPromptTokenBudget int
+
+	// EnableCompression compresses responses for clients that accept it
+	EnableCompression bool
+
+	// RetentionDays purges unpinned requests older than this; zero keeps everything
+	RetentionDays int
+
+	// GenerateTimeout bounds generation routes; zero disables the limit
+	GenerateTimeout time.Duration
+
+	// OpenAITimeout bounds the generator calls of a single generation,
+	// retries included; zero disables the limit
+	OpenAITimeout time.Duration
+
+	// ReadTimeout bounds read-only routes; zero disables the limit
+	ReadTimeout time.Duration
+
+	// ShutdownTimeout bounds how long shutdown waits for queued generations
+	ShutdownTimeout time.Duration
 }
 
 type DatabaseConfig struct {
@@ -36,6 +195,26 @@ func Load() (*Config, error) {
 		Port:         getEnv("PORT", "3000"),
 		Environment:  getEnv("ENVIRONMENT", "development"),
 		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:  getEnv("OPENAI_MODEL", "gpt-3.5-turbo"),
+
+		OpenAITemperature: getEnvFloat("OPENAI_TEMPERATURE", 0.7),
+		OpenAIMaxAttempts: getEnvInt("OPENAI_MAX_ATTEMPTS", 3),
+
+		GenerateChunkConcurrency: getEnvInt("GENERATE_CHUNK_CONCURRENCY", 4),
+
+		Generator:       getEnv("GENERATOR_PROVIDER", getEnv("GENERATOR", GeneratorOpenAI)), // GENERATOR is the deprecated name
+		AnthropicAPIKey: getEnv("ANTHROPIC_API_KEY", ""),
+		AnthropicModel:  getEnv("ANTHROPIC_MODEL", "claude-3-5-haiku-latest"),
+		AzureOpenAIEndpoint:   getEnv("AZURE_OPENAI_ENDPOINT", ""),
+		AzureOpenAIDeployment: getEnv("AZURE_OPENAI_DEPLOYMENT", ""),
+		AzureOpenAIAPIVersion: getEnv("AZURE_OPENAI_API_VERSION", "2024-02-01"),
+
+		OllamaHost:      getEnv("OLLAMA_HOST", "http://localhost:11434"),
+		OllamaModel:     getEnv("OLLAMA_MODEL", "llama3.1"),
+
+		OpenAIMode:            getEnv("OPENAI_MODE", OpenAIModeLive),
+		OpenAIMockLatency:     getEnvDuration("OPENAI_MOCK_LATENCY", 800*time.Millisecond),
+		OpenAIMockFailureRate: getEnvFloat("OPENAI_MOCK_FAILURE_RATE", 0),
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
@@ -44,9 +223,54 @@ func Load() (*Config, error) {
 			DBName:   getEnv("DB_NAME", "mockdata_generator"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
-		CORSOrigins: strings.Split(getEnv("CORS_ORIGINS", "http://localhost:5173"), ","),
+		AdminAPIKey: getEnv("ADMIN_API_KEY", ""),
+		FPEKey:      getEnv("FPE_KEY", ""),
+
+		RegenerateConcurrency: getEnvInt("REGENERATE_CONCURRENCY", 2),
+		GenerateWorkers:       getEnvInt("GENERATE_WORKERS", 4),
+		GenerateQueueSize:     getEnvInt("GENERATE_QUEUE_SIZE", 100),
+		ExportConcurrency:     getEnvInt("EXPORT_CONCURRENCY", 4),
+		RetentionDays:         getEnvInt("RETENTION_DAYS", 0),
+		EnableCompression:     getEnvBool("ENABLE_COMPRESSION", true),
+		JSONUseNumber:         getEnvBool("JSON_USE_NUMBER", true),
+		LogPromptCache:        getEnvBool("LOG_PROMPT_CACHE", false),
+		LogFormat:             getEnv("LOG_FORMAT", LogFormatText),
+
+		MaxScenarioLength: getEnvInt("MAX_SCENARIO_LENGTH", 2000),
+		MaxRowCount:       getEnvInt("MAX_ROW_COUNT", 1000),
+		FallbackEnabled:   getEnvBool("FALLBACK_ENABLED", false),
+
+		GenerationCacheTTL:  getEnvDuration("GENERATION_CACHE_TTL", 15*time.Minute),
+		GenerationCacheSize: getEnvInt("GENERATION_CACHE_SIZE", 100),
+
+		RowCountPolicy:    getEnv("ROW_COUNT_POLICY", RowCountWarn),
+
+		DiversityThreshold:  getEnvFloat("DIVERSITY_THRESHOLD", 0.3),
+		DiversityRegenerate: getEnvBool("DIVERSITY_REGENERATE", false),
+
+		FieldPromptsEnabled:    getEnvBool("FIELD_PROMPTS_ENABLED", false),
+		FieldPromptTokenBudget: getEnvInt("FIELD_PROMPT_TOKEN_BUDGET", 20000),
+
+		GenerateTimeout: getEnvDuration("GENERATE_TIMEOUT", 2*time.Minute),
+		OpenAITimeout:   getEnvDuration("OPENAI_TIMEOUT", 60*time.Second),
+		ReadTimeout:     getEnvDuration("READ_TIMEOUT", 10*time.Second),
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
 	}
 
+	config.AcceptedContentTypes = splitList(getEnv("ACCEPTED_CONTENT_TYPES", "application/json"))
+	config.APIKeys = splitList(getEnv("API_KEYS", ""))
+	config.ScenarioAllow = splitList(getEnv("SCENARIO_ALLOW", ""))
+	config.ScenarioDeny = splitList(getEnv("SCENARIO_DENY", ""))
+
+	// Verbose logs in development, redacted by default in production
+	config.LogRedact = getEnvBool("LOG_REDACT", config.Environment == "production")
+
+	origins, err := ParseCORSOrigins(getEnv("CORS_ORIGINS", "http://localhost:5173"))
+	if err != nil {
+		return nil, err
+	}
+	config.CORSOrigins = origins
+
 	// Validate critical configuration
 	if err := config.Validate(); err != nil {
 		return nil, err
@@ -56,17 +280,199 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) Validate() error {
-	if c.OpenAIAPIKey == "" {
-		return fmt.Errorf("OPENAI_API_KEY is required")
+	switch c.Generator {
+	case GeneratorOpenAI, GeneratorTemplate:
+	case GeneratorAnthropic:
+		if c.AnthropicAPIKey == "" {
+			return fmt.Errorf("ANTHROPIC_API_KEY is required with GENERATOR_PROVIDER=%s", GeneratorAnthropic)
+		}
+	case GeneratorOllama:
+		if host, err := url.Parse(c.OllamaHost); err != nil || (host.Scheme != "http" && host.Scheme != "https") || host.Host == "" {
+			return fmt.Errorf("OLLAMA_HOST must be an http(s) URL, got %q", c.OllamaHost)
+		}
+	default:
+		return fmt.Errorf("GENERATOR_PROVIDER must be %s, %s, %s or %s", GeneratorOpenAI, GeneratorAnthropic, GeneratorOllama, GeneratorTemplate)
+	}
+
+	if c.AzureOpenAIEndpoint != "" {
+		if endpoint, err := url.Parse(c.AzureOpenAIEndpoint); err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+			return fmt.Errorf("AZURE_OPENAI_ENDPOINT must be an https URL, got %q", c.AzureOpenAIEndpoint)
+		}
+		if c.AzureOpenAIDeployment == "" {
+			return fmt.Errorf("AZURE_OPENAI_DEPLOYMENT is required with AZURE_OPENAI_ENDPOINT")
+		}
+	}
+
+	switch c.OpenAIMode {
+	case OpenAIModeLive:
+		// Other generators run without OpenAI
+		if c.OpenAIAPIKey == "" && c.Generator == GeneratorOpenAI {
+			return fmt.Errorf("OPENAI_API_KEY is required")
+		}
+	case OpenAIModeMock:
+		if c.Environment == "production" {
+			return fmt.Errorf("OPENAI_MODE=mock is not allowed in production")
+		}
+		if c.OpenAIMockLatency < 0 {
+			return fmt.Errorf("OPENAI_MOCK_LATENCY must not be negative")
+		}
+		if c.OpenAIMockFailureRate < 0 || c.OpenAIMockFailureRate > 1 {
+			return fmt.Errorf("OPENAI_MOCK_FAILURE_RATE must be between 0 and 1")
+		}
+	default:
+		return fmt.Errorf("OPENAI_MODE must be %s or %s", OpenAIModeLive, OpenAIModeMock)
 	}
 
 	if c.Database.Password == "" {
 		return fmt.Errorf("DB_PASSWORD is required")
 	}
 
+	if len(c.AcceptedContentTypes) == 0 {
+		return fmt.Errorf("ACCEPTED_CONTENT_TYPES must list at least one media type")
+	}
+
+	if c.RegenerateConcurrency < 1 {
+		return fmt.Errorf("REGENERATE_CONCURRENCY must be at least 1")
+	}
+
+	if c.GenerateWorkers < 1 || c.GenerateQueueSize < 1 {
+		return fmt.Errorf("GENERATE_WORKERS and GENERATE_QUEUE_SIZE must be at least 1")
+	}
+
+	if c.ExportConcurrency < 1 {
+		return fmt.Errorf("EXPORT_CONCURRENCY must be at least 1")
+	}
+
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("RETENTION_DAYS must not be negative")
+	}
+
+	if c.MaxScenarioLength < 1 {
+		return fmt.Errorf("MAX_SCENARIO_LENGTH must be at least 1")
+	}
+
+	if c.OpenAITemperature < 0 || c.OpenAITemperature > 2 {
+		return fmt.Errorf("OPENAI_TEMPERATURE must be between 0 and 2")
+	}
+
+	if c.OpenAIMaxAttempts < 1 {
+		return fmt.Errorf("OPENAI_MAX_ATTEMPTS must be at least 1")
+	}
+
+	if c.GenerateChunkConcurrency < 1 {
+		return fmt.Errorf("GENERATE_CHUNK_CONCURRENCY must be at least 1")
+	}
+
+	if c.GenerationCacheTTL < 0 || c.GenerationCacheSize < 0 {
+		return fmt.Errorf("GENERATION_CACHE_TTL and GENERATION_CACHE_SIZE must not be negative")
+	}
+
+	if c.MaxRowCount < 1 {
+		return fmt.Errorf("MAX_ROW_COUNT must be at least 1")
+	}
+
+	switch c.LogFormat {
+	case LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("LOG_FORMAT must be %s or %s", LogFormatText, LogFormatJSON)
+	}
+
+	switch c.RowCountPolicy {
+	case RowCountWarn, RowCountTruncate, RowCountTopUp:
+	default:
+		return fmt.Errorf("ROW_COUNT_POLICY must be %s, %s or %s", RowCountWarn, RowCountTruncate, RowCountTopUp)
+	}
+
+	if c.DiversityThreshold < 0 || c.DiversityThreshold > 1 {
+		return fmt.Errorf("DIVERSITY_THRESHOLD must be between 0 and 1")
+	}
+
+	if c.FieldPromptTokenBudget < 0 {
+		return fmt.Errorf("FIELD_PROMPT_TOKEN_BUDGET must not be negative")
+	}
+
+	if c.GenerateTimeout < 0 || c.ReadTimeout < 0 || c.OpenAITimeout < 0 {
+		return fmt.Errorf("GENERATE_TIMEOUT, READ_TIMEOUT and OPENAI_TIMEOUT must not be negative")
+	}
+
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("SHUTDOWN_TIMEOUT must not be negative")
+	}
+
 	return nil
 }
 
+// AnyOrigin as the only CORS origin allows every origin, without credentials
+const AnyOrigin = "*"
+
+/*
+ParseCORSOrigins splits a comma-separated origin list, trimming whitespace
+and dropping empty entries. Each origin must look like scheme://host[:port]
+with an http or https scheme; a trailing slash is removed and the scheme and
+host are lowercased so they match the browser's Origin header exactly.
+
+AnyOrigin is accepted only on its own; mixed with other origins it would
+silently override them.
+*/
+func ParseCORSOrigins(raw string) ([]string, error) {
+	origins := []string{}
+	wildcard := false
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if entry == AnyOrigin {
+			wildcard = true
+			continue
+		}
+
+		origin, err := normalizeOrigin(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CORS origin %q: %w", entry, err)
+		}
+		origins = append(origins, origin)
+	}
+
+	if wildcard {
+		if len(origins) > 0 {
+			return nil, fmt.Errorf("CORS_ORIGINS must be either %q or a list of origins, not both", AnyOrigin)
+		}
+		return []string{AnyOrigin}, nil
+	}
+
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("CORS_ORIGINS must contain at least one origin")
+	}
+
+	return origins, nil
+}
+
+// normalizeOrigin validates a single origin and returns its canonical form
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+	if err != nil {
+		return "", err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("scheme must be http or https")
+	}
+
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("host is required")
+	}
+
+	if u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("origin must be scheme://host[:port] without a path, query or credentials")
+	}
+
+	return scheme + "://" + strings.ToLower(u.Host), nil
+}
+
 // returns the PostgreSQL connection string
 func (c *Config) GetDatabaseDSN() string {
 	return fmt.Sprintf(
@@ -87,3 +493,70 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// retrieves an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// retrieves a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// retrieves a float environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// retrieves a duration environment variable (e.g. "30s", "2m") or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return parsed
+}
+
+// splitList splits a comma-separated value, trimming spaces and dropping empty entries
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
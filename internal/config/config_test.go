@@ -0,0 +1,111 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseCORSOrigins tests normalization of messy origin lists
+func TestParseCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Single origin", "http://localhost:5173", []string{"http://localhost:5173"}},
+		{"Spaces after commas", "http://a.com, http://b.com", []string{"http://a.com", "http://b.com"}},
+		{"Surrounding whitespace", "  https://a.com  ,\thttps://b.com\n", []string{"https://a.com", "https://b.com"}},
+		{"Empty entries", "http://a.com,,, ,http://b.com,", []string{"http://a.com", "http://b.com"}},
+		{"Trailing slash", "https://app.example.com/", []string{"https://app.example.com"}},
+		{"Uppercase scheme and host", "HTTPS://App.Example.COM:8443", []string{"https://app.example.com:8443"}},
+		{"Wildcard", " * ", []string{AnyOrigin}},
+		{"Repeated wildcard", "*,*", []string{AnyOrigin}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origins, err := ParseCORSOrigins(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, origins)
+		})
+	}
+}
+
+// TestParseCORSOrigins_Invalid tests rejection of malformed origins
+func TestParseCORSOrigins_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Empty", ""},
+		{"Only separators", " , ,"},
+		{"Missing scheme", "localhost:5173"},
+		{"Unsupported scheme", "ftp://example.com"},
+		{"Missing host", "http://"},
+		{"With path", "http://example.com/app"},
+		{"With query", "http://example.com?x=1"},
+		{"Bad port", "http://example.com:abc"},
+		{"One bad among good", "http://a.com, not a url"},
+		{"Wildcard with origins", "*, http://a.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCORSOrigins(tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestLoad_AzureOpenAI tests reading and validating the Azure OpenAI settings
+func TestLoad_AzureOpenAI(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "azure-key")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "https://my-resource.openai.azure.com")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "mockdata-gpt4o")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://my-resource.openai.azure.com", cfg.AzureOpenAIEndpoint)
+	assert.Equal(t, "mockdata-gpt4o", cfg.AzureOpenAIDeployment)
+	assert.Equal(t, "2024-02-01", cfg.AzureOpenAIAPIVersion)
+
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "")
+	_, err = Load()
+	assert.ErrorContains(t, err, "AZURE_OPENAI_DEPLOYMENT is required")
+
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "mockdata-gpt4o")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "my-resource.openai.azure.com")
+	_, err = Load()
+	assert.ErrorContains(t, err, "AZURE_OPENAI_ENDPOINT must be an https URL")
+}
+
+// TestLoad_GeneratorProvider tests selecting the generator, including the
+// deprecated GENERATOR name
+func TestLoad_GeneratorProvider(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "key")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("GENERATOR_PROVIDER", GeneratorTemplate)
+	t.Setenv("GENERATOR", "")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, GeneratorTemplate, cfg.Generator)
+
+	t.Setenv("GENERATOR_PROVIDER", "")
+	t.Setenv("GENERATOR", GeneratorOllama)
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, GeneratorOllama, cfg.Generator, "GENERATOR is read when GENERATOR_PROVIDER is unset")
+
+	t.Setenv("GENERATOR_PROVIDER", GeneratorTemplate)
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, GeneratorTemplate, cfg.Generator, "GENERATOR_PROVIDER wins")
+
+	t.Setenv("GENERATOR_PROVIDER", "claude")
+	_, err = Load()
+	assert.ErrorContains(t, err, "GENERATOR_PROVIDER must be")
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -39,6 +40,106 @@ func (db *DB) Close() error {
 }
 
 
+// RecordUsage stores the token usage of a single OpenAI call
+func (db *DB) RecordUsage(ctx context.Context, model string, promptTokens, completionTokens, totalTokens int) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO openai_usage (model, prompt_tokens, completion_tokens, total_tokens)
+		 VALUES ($1, $2, $3, $4)`,
+		model,
+		promptTokens,
+		completionTokens,
+		totalTokens,
+	)
+	return err
+}
+
+
+/*
+BackfillScenarioHashes fills scenario_hash for requests created before the
+column existed. The hash function is passed in so normalization lives in one
+place (models.ScenarioHash).
+*/
+func (db *DB) BackfillScenarioHashes(hash func(scenario string) string) error {
+	rows, err := db.Query(`SELECT id, scenario FROM generation_requests WHERE scenario_hash IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to load requests without scenario hash: %w", err)
+	}
+
+	type pending struct {
+		id       int64
+		scenario string
+	}
+	var missing []pending
+
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.scenario); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan request: %w", err)
+		}
+		missing = append(missing, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range missing {
+		_, err := db.Exec(`UPDATE generation_requests SET scenario_hash = $1 WHERE id = $2`, hash(p.scenario), p.id)
+		if err != nil {
+			return fmt.Errorf("failed to backfill scenario hash: %w", err)
+		}
+	}
+
+	if len(missing) > 0 {
+		log.Printf("Backfilled scenario hashes for %d requests", len(missing))
+	}
+	return nil
+}
+
+// PurgeRequestsBefore deletes requests (and, by cascade, their datasets)
+// created before cutoff. Pinned requests are kept unless force is set.
+func (db *DB) PurgeRequestsBefore(ctx context.Context, cutoff time.Time, force bool) (int64, error) {
+	result, err := db.ExecContext(ctx, purgeQuery(force), cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge requests: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// purgeQuery builds the delete statement for PurgeRequestsBefore
+func purgeQuery(force bool) string {
+	query := `DELETE FROM generation_requests WHERE created_at < $1`
+	if !force {
+		query += ` AND NOT pinned`
+	}
+	return query
+}
+
+/*
+RunRetentionJanitor deletes unpinned requests older than retention every
+interval until ctx is cancelled. Pinned requests are never purged here.
+*/
+func (db *DB) RunRetentionJanitor(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := db.PurgeRequestsBefore(ctx, time.Now().Add(-retention), false)
+		if err != nil {
+			log.Printf("Retention janitor: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Retention janitor: purged %d expired requests", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // RunMigrations executes the database migrations
 func (db *DB) RunMigrations() error {
 	log.Println("Running database migrations...")
@@ -58,6 +159,108 @@ func (db *DB) RunMigrations() error {
 		return fmt.Errorf("failed to create generation_requests table: %w", err)
 	}
 
+	// Pinned requests are kept by retention cleanup and bulk deletes
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add pinned column: %w", err)
+	}
+
+	// Set when the data came from the offline fallback instead of the model
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS degraded BOOLEAN NOT NULL DEFAULT FALSE
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add degraded column: %w", err)
+	}
+
+	// Rows actually stored, which can differ from row_count when the model
+	// returned the wrong number; NULL until the request completes
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS actual_row_count INTEGER
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add actual_row_count column: %w", err)
+	}
+
+	// Explicit schema of the request, reused when it is regenerated; NULL
+	// without one
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS schema_fields JSONB
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add schema_fields column: %w", err)
+	}
+
+	// Rows changed to meet the schema's constraints; NULL without a schema
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS adjusted_row_count INTEGER
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add adjusted_row_count column: %w", err)
+	}
+
+	// Why a failed request failed; NULL otherwise
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS error_message TEXT
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add error_message column: %w", err)
+	}
+
+	// Machine-readable kind of failure (timeout, generation_failed); NULL
+	// unless failed
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS error_code VARCHAR(32)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add error_code column: %w", err)
+	}
+
+	// Locale the data was generated for; NULL for the default
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS locale VARCHAR(35)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add locale column: %w", err)
+	}
+
+	// Model that generated the data; requests from before the column
+	// existed all used gpt-3.5-turbo
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS model VARCHAR(100) NOT NULL DEFAULT 'gpt-3.5-turbo'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add model column: %w", err)
+	}
+
+	// Hash of the normalized scenario, grouping "Users" and "users "
+	_, err = db.Exec(`
+		ALTER TABLE generation_requests
+		ADD COLUMN IF NOT EXISTS scenario_hash VARCHAR(64)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add scenario_hash column: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_generation_requests_scenario_hash
+		ON generation_requests(scenario_hash)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
 
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS mock_datasets (
@@ -72,6 +275,34 @@ func (db *DB) RunMigrations() error {
 		return fmt.Errorf("failed to create mock_datasets table: %w", err)
 	}
 
+	// Fields stored with format-preserving encryption
+	_, err = db.Exec(`
+		ALTER TABLE mock_datasets
+		ADD COLUMN IF NOT EXISTS encrypted_fields TEXT[] NOT NULL DEFAULT '{}'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add encrypted_fields column: %w", err)
+	}
+
+	// Per-row last-modified times (JSON array aligned with data) for
+	// incremental exports
+	_, err = db.Exec(`
+		ALTER TABLE mock_datasets
+		ADD COLUMN IF NOT EXISTS row_updated_at JSONB NOT NULL DEFAULT '[]'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add row_updated_at column: %w", err)
+	}
+
+	// Column types inferred (or corrected) once and reused by exports
+	_, err = db.Exec(`
+		ALTER TABLE mock_datasets
+		ADD COLUMN IF NOT EXISTS field_types JSONB NOT NULL DEFAULT '{}'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add field_types column: %w", err)
+	}
+
 	// Create index on request_id for faster lookups
 	_, err = db.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_mock_datasets_request_id
@@ -81,6 +312,80 @@ func (db *DB) RunMigrations() error {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
+	// Validation failures are recorded for analytics only; the scenario
+	// text itself is never stored, just its length
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS validation_failures (
+			id SERIAL PRIMARY KEY,
+			rule VARCHAR(100) NOT NULL,
+			scenario_length INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create validation_failures table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_validation_failures_created_at
+		ON validation_failures(created_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Token usage per OpenAI call, for cost reporting
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS openai_usage (
+			id SERIAL PRIMARY KEY,
+			model VARCHAR(100) NOT NULL,
+			prompt_tokens INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			total_tokens INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create openai_usage table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_openai_usage_created_at
+		ON openai_usage(created_at)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	// Background jobs spanning several generation requests
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			id SERIAL PRIMARY KEY,
+			kind VARCHAR(50) NOT NULL,
+			status VARCHAR(50) NOT NULL DEFAULT 'pending',
+			total INTEGER NOT NULL DEFAULT 0,
+			completed INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			result_request_ids INTEGER[] NOT NULL DEFAULT '{}',
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			finished_at TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create jobs table: %w", err)
+	}
+
+	// Output of jobs that produce a file, such as bundle exports
+	_, err = db.Exec(`
+		ALTER TABLE jobs
+		ADD COLUMN IF NOT EXISTS result BYTEA
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add job result column: %w", err)
+	}
+
 	// Create updated_at trigger function
 	_, err = db.Exec(`
 		CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -107,6 +412,17 @@ func (db *DB) RunMigrations() error {
 		return fmt.Errorf("failed to create trigger: %w", err)
 	}
 
+	_, err = db.Exec(`
+		DROP TRIGGER IF EXISTS update_jobs_updated_at ON jobs;
+		CREATE TRIGGER update_jobs_updated_at
+		BEFORE UPDATE ON jobs
+		FOR EACH ROW
+		EXECUTE FUNCTION update_updated_at_column();
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create trigger: %w", err)
+	}
+
 	log.Println("✅ Database migrations completed successfully")
 	return nil
 }
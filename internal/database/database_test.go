@@ -0,0 +1,20 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPurgeQuery tests that retention cleanup and bulk deletes skip pinned requests
+func TestPurgeQuery(t *testing.T) {
+	assert.Equal(t,
+		`DELETE FROM generation_requests WHERE created_at < $1 AND NOT pinned`,
+		purgeQuery(false),
+		"Janitor and default bulk delete must keep pinned requests")
+
+	assert.Equal(t,
+		`DELETE FROM generation_requests WHERE created_at < $1`,
+		purgeQuery(true),
+		"Forced bulk delete includes pinned requests")
+}
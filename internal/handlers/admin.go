@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/lib/pq"
+)
+
+// Upper bound on how many requests one bulk regenerate call may re-run
+const maxRegenerateLimit = 100
+
+// regenerateSource is a stored request selected for regeneration
+type regenerateSource struct {
+	id              int64
+	scenario        string
+	rowCount        int
+	locale          string
+	encryptedFields []string
+	schema          []models.SchemaField
+}
+
+/*
+RegenerateRequests handles POST /api/admin/regenerate
+
+Re-runs stored requests with a different model, creating a new request for
+each one so the originals are kept intact. Each keeps its locale and
+explicit fields.
+
+The work runs in the background: the endpoint responds 202 with a job and
+clients poll GET /api/jobs/:id for progress and the new request ids.
+
+Query parameters:
+- model: model to generate with (required, one of the request models)
+- status: only regenerate requests with this status (default: completed)
+- limit: maximum number of requests to regenerate (default: 20, max: 100)
+
+Generations run with bounded concurrency (REGENERATE_CONCURRENCY) and the
+limit caps how many OpenAI calls a single invocation can make.
+*/
+func (h *Handler) RegenerateRequests(c *fiber.Ctx) error {
+	// Copied: Fiber reuses the query buffer once the handler returns
+	model := strings.Clone(c.Query("model"))
+	status := c.Query("status", models.StatusCompleted)
+	limit := c.QueryInt("limit", 20)
+
+	if model == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: "model is required",
+		})
+	}
+
+	if !services.IsRequestModel(model) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("%s: '%s'. Use: %s", models.ErrInvalidModel.Error(), model, strings.Join(services.RequestModels(), ", ")),
+		})
+	}
+
+	if !models.IsValidStatus(status) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("Unknown status '%s'", status),
+		})
+	}
+
+	if limit < 1 || limit > maxRegenerateLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("limit must be between 1 and %d", maxRegenerateLimit),
+		})
+	}
+
+	sources, err := h.findRegenerateSources(status, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	jobID, err := h.createJob(c.UserContext(), models.JobKindRegenerate, len(sources))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Bulk regenerate job %d: %d requests with status %s using %s", jobID, len(sources), status, model)
+
+	err = h.jobs.start(func(ctx context.Context) {
+		h.runRegenerateJob(ctx, jobID, sources, services.GenerateOptions{Model: model})
+	})
+	if err != nil {
+		h.finishJob(jobID, err.Error())
+		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
+			Error:   "Service unavailable",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"job_id":  jobID,
+		"status":  models.StatusProcessing,
+		"total":   len(sources),
+		"message": fmt.Sprintf("Poll /api/jobs/%d for progress", jobID),
+	})
+}
+
+// runRegenerateJob regenerates sources with a bounded worker pool,
+// recording each finished generation on the job; sources not started when
+// ctx ends are left out and the job fails
+func (h *Handler) runRegenerateJob(ctx context.Context, jobID int64, sources []regenerateSource, opts services.GenerateOptions) {
+	sem := make(chan struct{}, h.cfg.RegenerateConcurrency)
+	var wg sync.WaitGroup
+
+	for _, source := range sources {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+
+		go func(source regenerateSource) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Detached from the HTTP request, but still bounded per generation
+			ctx := ctx
+			if h.cfg.GenerateTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, h.cfg.GenerateTimeout)
+				defer cancel()
+			}
+
+			// Each request keeps the locale and schema it was generated for
+			opts := opts
+			opts.Locale = source.locale
+			opts.Schema = source.schema
+
+			newID, err := h.createGenerationRequest(source.scenario, source.rowCount, h.modelName(opts), source.locale, source.schema)
+			if err == nil {
+				_, err = h.generateDataset(ctx, newID, source.scenario, source.rowCount, opts, source.encryptedFields, false)
+			}
+
+			if err != nil {
+				log.Printf("Regenerate job %d: request %d failed: %v", jobID, source.id, err)
+			}
+			h.recordJobStep(jobID, newID, err == nil)
+		}(source)
+	}
+
+	wg.Wait()
+	if ctx.Err() != nil {
+		h.finishJob(jobID, "interrupted by server shutdown")
+		log.Printf("Bulk regenerate job %d interrupted by shutdown", jobID)
+		return
+	}
+	h.finishJob(jobID, "")
+
+	log.Printf("Bulk regenerate job %d finished", jobID)
+}
+
+// findRegenerateSources selects the oldest requests with the given status
+func (h *Handler) findRegenerateSources(status string, limit int) ([]regenerateSource, error) {
+	rows, err := h.db.Query(
+		`SELECT r.id, r.scenario, r.row_count, COALESCE(r.locale, ''), COALESCE(d.encrypted_fields, '{}'), r.schema_fields
+		 FROM generation_requests r
+		 LEFT JOIN mock_datasets d ON d.request_id = r.id
+		 WHERE r.status = $1
+		 ORDER BY r.id
+		 LIMIT $2`,
+		status,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := []regenerateSource{}
+	for rows.Next() {
+		var source regenerateSource
+		var schemaJSON []byte
+		if err := rows.Scan(&source.id, &source.scenario, &source.rowCount, &source.locale, pq.Array(&source.encryptedFields), &schemaJSON); err != nil {
+			return nil, err
+		}
+		if source.schema, err = decodeSchemaFields(schemaJSON); err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, rows.Err()
+}
+
+/*
+PurgeRequests handles DELETE /api/admin/requests?before=2024-01-01
+
+Bulk-deletes requests (and their datasets) created before the given date.
+Pinned requests are kept unless force=true.
+
+Query parameters:
+- before: RFC3339 timestamp or YYYY-MM-DD date (required)
+- force: also delete pinned requests (default: false)
+*/
+func (h *Handler) PurgeRequests(c *fiber.Ctx) error {
+	raw := c.Query("before")
+	if raw == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: "before is required",
+		})
+	}
+
+	before, err := parseTimestamp(raw)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+	}
+
+	force := c.QueryBool("force")
+
+	deleted, err := h.db.PurgeRequestsBefore(c.UserContext(), before, force)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Bulk delete: %d requests created before %s (force=%t)", deleted, before.Format(time.RFC3339), force)
+
+	return c.JSON(fiber.Map{
+		"deleted": deleted,
+		"force":   force,
+	})
+}
+
+/*
+ValidationStats handles GET /api/admin/validation-stats
+
+Summarizes rejected generate requests by rule so we can see which parts of
+the generation form trip users up.
+
+Query parameters:
+- days: how far back to look (default: 30)
+*/
+func (h *Handler) ValidationStats(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 30)
+	if days < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: "days must be at least 1",
+		})
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+
+	rows, err := h.db.Query(
+		`SELECT rule, COUNT(*), AVG(scenario_length), MAX(created_at)
+		 FROM validation_failures
+		 WHERE created_at >= $1
+		 GROUP BY rule
+		 ORDER BY COUNT(*) DESC`,
+		since,
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+	defer rows.Close()
+
+	stats := []models.ValidationStat{}
+	total := 0
+
+	for rows.Next() {
+		var stat models.ValidationStat
+		if err := rows.Scan(&stat.Rule, &stat.Count, &stat.AvgScenarioLength, &stat.LastSeen); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Failed to scan row",
+				Message: err.Error(),
+			})
+		}
+		total += stat.Count
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"since": since,
+		"total": total,
+		"rules": stats,
+	})
+}
+
+// recordValidationFailure stores a rejected request for analytics.
+// Failures to record are logged and never affect the response.
+func (h *Handler) recordValidationFailure(rule string, scenarioLength int) {
+	_, err := h.db.Exec(
+		`INSERT INTO validation_failures (rule, scenario_length) VALUES ($1, $2)`,
+		rule,
+		scenarioLength,
+	)
+	if err != nil {
+		log.Printf("Failed to record validation failure: %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+// Upper bound on how many datasets one bundle may contain
+const maxBundleSize = 50
+
+// bundleRequest is a validated bundle export
+type bundleRequest struct {
+	ids             []int64
+	format          string
+	extension       string
+	table           string
+	opts            services.ExportOptions
+	includeMetadata bool
+}
+
+// parseBundleRequest validates the query of a bundle export. When it is
+// invalid the 400 response is sent and req is nil; err is then the result
+// of sending it.
+func parseBundleRequest(c *fiber.Ctx) (req *bundleRequest, err error) {
+	ids, err := parseBundleIDs(c.Query("ids"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid ids",
+			Message: err.Error(),
+		})
+	}
+
+	// Copied: Fiber reuses the query buffer once the handler returns, and
+	// background bundles outlive it
+	format := strings.Clone(c.Query("format", "json"))
+	extension, err := services.FormatExtension(format)
+	if err != nil {
+		return nil, exportError(c, err)
+	}
+
+	opts, err := exportOptionsFromQuery(c)
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: err.Error(),
+		})
+	}
+
+	return &bundleRequest{
+		ids:             ids,
+		format:          format,
+		extension:       extension,
+		table:           strings.Clone(c.Query("table")),
+		opts:            detachOptions(opts),
+		includeMetadata: c.QueryBool("include_metadata"),
+	}, nil
+}
+
+// detachOptions copies the strings of export options parsed from a query,
+// which point into a buffer Fiber reuses once the handler returns
+func detachOptions(opts services.ExportOptions) services.ExportOptions {
+	for _, value := range []*string{&opts.TrueLabel, &opts.FalseLabel, &opts.ConflictColumn, &opts.PrimaryKey, &opts.Dialect, &opts.SQLMode, &opts.StrictFields, &opts.TypeName, &opts.ArchiveName, &opts.NullAs} {
+		*value = strings.Clone(*value)
+	}
+	return opts
+}
+
+/*
+ExportBundle handles GET /api/export/bundle?ids=1,2,3&format=csv
+
+Exports several datasets as one zip archive with a file per dataset,
+in the order the ids were given. Datasets are serialized in parallel with
+at most EXPORT_CONCURRENCY workers.
+
+Query parameters:
+- ids: comma-separated request ids (required, max 50)
+- format, table and the value options of the single dataset export
+*/
+func (h *Handler) ExportBundle(c *fiber.Ctx) error {
+	req, err := parseBundleRequest(c)
+	if req == nil {
+		return err
+	}
+
+	archive, err := h.buildBundle(c.UserContext(), req, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: err.Error(),
+		})
+	}
+
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", "attachment; filename="+bundleFileName)
+
+	return c.Send(archive)
+}
+
+// bundleFileName is the download name of bundle archives
+const bundleFileName = "mockdata-bundle.zip"
+
+/*
+StartBundleExport handles POST /api/export/bundle?ids=1,2,3&format=csv
+
+Builds the same archive as ExportBundle as a background job, for bundles
+too large to wait for: it responds 202 with a job, GET /api/jobs/:id counts
+the exported datasets, and the archive is downloaded from
+GET /api/jobs/:id/result once the job has completed.
+*/
+func (h *Handler) StartBundleExport(c *fiber.Ctx) error {
+	req, err := parseBundleRequest(c)
+	if req == nil {
+		return err
+	}
+
+	jobID, err := h.createJob(c.UserContext(), models.JobKindBundle, len(req.ids))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	err = h.jobs.start(func(ctx context.Context) {
+		h.runBundleJob(ctx, jobID, req)
+	})
+	if err != nil {
+		h.finishJob(jobID, err.Error())
+		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
+			Error:   "Service unavailable",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"job_id":  jobID,
+		"status":  models.StatusProcessing,
+		"total":   len(req.ids),
+		"message": fmt.Sprintf("Poll /api/jobs/%d for progress", jobID),
+	})
+}
+
+// runBundleJob builds a bundle, counting each exported dataset on the job,
+// and stores the archive as the job's result
+func (h *Handler) runBundleJob(ctx context.Context, jobID int64, req *bundleRequest) {
+	archive, err := h.buildBundle(ctx, req, func(requestID int64, err error) {
+		h.recordJobStep(jobID, requestID, err == nil)
+	})
+	if err == nil {
+		err = h.storeJobResult(jobID, archive)
+	}
+
+	if err != nil {
+		log.Printf("Bundle job %d failed: %v", jobID, err)
+		h.finishJob(jobID, err.Error())
+		return
+	}
+	h.finishJob(jobID, "")
+}
+
+// buildBundle renders the datasets of req into a zip archive; progress,
+// when set, is called as each dataset is rendered
+func (h *Handler) buildBundle(ctx context.Context, req *bundleRequest, progress func(requestID int64, err error)) ([]byte, error) {
+	files := make([]services.BundleFile, len(req.ids))
+
+	for i, id := range req.ids {
+		id := id
+		files[i] = services.BundleFile{
+			Name: fmt.Sprintf("mockdata-%d.%s", id, req.extension),
+			Render: func() ([]byte, error) {
+				content, err := h.renderBundleFile(ctx, req, id)
+				if progress != nil {
+					progress(id, err)
+				}
+				return content, err
+			},
+		}
+	}
+
+	return services.BuildZip(files, h.cfg.ExportConcurrency)
+}
+
+// renderBundleFile exports the dataset of one bundle entry
+func (h *Handler) renderBundleFile(ctx context.Context, req *bundleRequest, id int64) ([]byte, error) {
+	dataset, err := h.loadDataset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	datasetOpts := req.opts
+	datasetOpts.FieldTypes = dataset.FieldTypes
+
+	if req.includeMetadata {
+		datasetOpts.Metadata, err = h.loadExportMetadata(ctx, dataset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tableName, err := h.exportTableName(ctx, req.table, req.format, dataset.RequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.exportService.WithOptions(datasetOpts).Export(req.format, dataset.Data, dataset.FieldNames, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// unsupportedFormatsHeader lists the requested formats left out of an
+// all-formats export
+const unsupportedFormatsHeader = "X-Unsupported-Formats"
+
+/*
+ExportAllFormats handles GET /api/data/:id/export/all?formats=csv,json,sql
+
+Exports one dataset as a zip archive with a file per format
+(mockdata-<id>.<ext>), in the order the formats were given. Unknown formats
+are skipped and listed in the X-Unsupported-Formats header; the request only
+fails when none of the formats is supported.
+
+Query parameters:
+- formats: comma-separated format names (default: every format)
+- table and the value options of the single dataset export
+*/
+func (h *Handler) ExportAllFormats(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	raw := c.Query("formats")
+	formats, unsupported := services.ParseFormatList(raw)
+
+	// Without a list every format is included, except zip itself
+	if strings.TrimSpace(raw) == "" {
+		formats, _ = services.ParseFormatList(strings.Join(h.exportService.GetAvailableFormats(), ","))
+	}
+
+	if len(formats) == 0 {
+		return exportError(c, fmt.Errorf("%w: '%s'", services.ErrUnsupportedFormat, strings.Join(unsupported, "', '")))
+	}
+
+	opts, err := exportOptionsFromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.UserContext()
+
+	dataset, err := h.loadDataset(ctx, id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	opts.FieldTypes = dataset.FieldTypes
+
+	if c.QueryBool("include_metadata") {
+		opts.Metadata, err = h.loadExportMetadata(ctx, dataset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	// The scenario is only looked up when the archive contains a table format
+	tableFormat := "json"
+	for _, format := range formats {
+		if tableFormats[format] {
+			tableFormat = format
+		}
+	}
+
+	tableName, err := h.exportTableName(ctx, c.Query("table"), tableFormat, dataset.RequestID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	baseName := fmt.Sprintf("mockdata-%s", requestID)
+	archive, err := h.exportService.WithOptions(opts).ExportFormats(formats, dataset.Data, dataset.FieldNames, tableName, baseName, h.cfg.ExportConcurrency)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	if len(unsupported) > 0 {
+		c.Set(unsupportedFormatsHeader, strings.Join(unsupported, ","))
+	}
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", baseName))
+
+	return c.Send(archive)
+}
+
+// parseBundleIDs parses a comma-separated list of distinct request ids for
+// bundle and merged exports
+func parseBundleIDs(raw string) ([]int64, error) {
+	ids := []int64{}
+	seen := map[int64]bool{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("'%s' is not a valid request id", part)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("ids is required")
+	}
+
+	if len(ids) > maxBundleSize {
+		return nil, fmt.Errorf("at most %d datasets can be exported together", maxBundleSize)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseBundleIDs tests parsing of bundle id lists
+func TestParseBundleIDs(t *testing.T) {
+	ids, err := parseBundleIDs("3, 1,2,,3")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1, 2}, ids, "Order is kept and duplicates dropped")
+
+	for _, raw := range []string{"", " , ", "1,abc", "0", "-4"} {
+		_, err := parseBundleIDs(raw)
+		assert.Error(t, err, raw)
+	}
+
+	tooMany := make([]string, maxBundleSize+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprint(i + 1)
+	}
+	_, err = parseBundleIDs(strings.Join(tooMany, ","))
+	assert.Error(t, err)
+}
+
+// TestStartBundleExport tests building a bundle as a background job
+func TestStartBundleExport(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, []map[string]interface{}{{"id": float64(1), "name": "Ann"}}, []string{"id", "name"})
+	serve := fake.query
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "INSERT INTO jobs") {
+			return []string{"id"}, [][]driver.Value{{int64(9)}}, nil
+		}
+		return serve(query, args)
+	}
+
+	cfg := &config.Config{GenerateWorkers: 1, GenerateQueueSize: 1, ExportConcurrency: 2}
+	h := NewHandler(cfg, db, &fakeGenerator{}, services.NewExportService(), nil)
+
+	app := fiber.New()
+	app.Post("/api/export/bundle", h.StartBundleExport)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/export/bundle?ids=1,2&format=csv", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+	var body struct {
+		JobID int64 `json:"job_id"`
+		Total int   `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, int64(9), body.JobID)
+	assert.Equal(t, 2, body.Total)
+
+	require.NoError(t, h.Shutdown(context.Background()))
+
+	assert.Len(t, fake.executed("completed = completed + 1"), 2, "Each dataset counts as a step")
+
+	stored := fake.executed("UPDATE jobs SET result")
+	require.Len(t, stored, 1)
+	archive, ok := stored[0].args[0].([]byte)
+	require.True(t, ok)
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 2)
+	assert.Equal(t, "mockdata-1.csv", reader.File[0].Name)
+	assert.Equal(t, "mockdata-2.csv", reader.File[1].Name)
+
+	finished := fake.executed("UPDATE jobs SET status")
+	require.Len(t, finished, 1)
+	assert.Equal(t, []driver.Value{models.StatusCompleted, ""}, finished[0].args[:2])
+
+	resp, err = app.Test(httptest.NewRequest("POST", "/api/export/bundle?ids=abc", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "Invalid bundles are rejected before a job is created")
+}
+
+// TestGetJobResult tests downloading the result of a finished job
+func TestGetJobResult(t *testing.T) {
+	fake, db := newFakeDB(t)
+	status, result := models.StatusProcessing, []byte(nil)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"status", "result"}, [][]driver.Value{{status, result}}, nil
+	}
+
+	app := fiber.New()
+	app.Get("/api/jobs/:id/result", (&Handler{db: db}).GetJobResult)
+
+	get := func() *http.Response {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/jobs/9/result", nil))
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, fiber.StatusConflict, get().StatusCode, "Still running")
+
+	status = models.StatusFailed
+	assert.Equal(t, fiber.StatusNotFound, get().StatusCode, "Nothing to download")
+
+	status, result = models.StatusCompleted, []byte("PK\x05\x06")
+	resp := get()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, result, content)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// Cache-Control values for dataset responses
+const (
+	cacheNoStore        = "no-store"
+	cachePrivateNoStore = "private, no-store"
+	cachePrivateNoCache = "private, no-cache"
+)
+
+/*
+cacheControlFor returns the Cache-Control value for data of a request.
+
+Completed datasets can still change (coercion and type inference rewrite
+them), so clients may keep a copy but must revalidate it with the ETag on
+every use. Responses can sit behind API keys, so shared caches must not
+store them. Anything still in progress must not be cached at all.
+*/
+func cacheControlFor(status string) string {
+	if status != models.StatusCompleted {
+		return cacheNoStore
+	}
+	return cachePrivateNoCache
+}
+
+// computeETag returns a strong ETag for a response body
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// sendCacheable sends a completed dataset response with caching headers,
+// answering a matching conditional request with 304 Not Modified
+func sendCacheable(c *fiber.Ctx, body []byte, cacheControl string) error {
+	etag := computeETag(body)
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	c.Set(fiber.HeaderETag, etag)
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.Send(body)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCacheControlFor tests Cache-Control values by request status
+func TestCacheControlFor(t *testing.T) {
+	assert.Equal(t, "private, no-cache", cacheControlFor(models.StatusCompleted), "Completed data can change and may be authenticated")
+	assert.Equal(t, "no-store", cacheControlFor(models.StatusPending))
+	assert.Equal(t, "no-store", cacheControlFor(models.StatusProcessing))
+	assert.Equal(t, "no-store", cacheControlFor(models.StatusFailed))
+}
+
+// TestEtagMatches tests If-None-Match parsing
+func TestEtagMatches(t *testing.T) {
+	etag := computeETag([]byte("data"))
+
+	assert.True(t, etagMatches(etag, etag))
+	assert.True(t, etagMatches(`"other", `+etag, etag))
+	assert.True(t, etagMatches("W/"+etag, etag), "Weak comparison")
+	assert.True(t, etagMatches("*", etag))
+	assert.False(t, etagMatches(`"other"`, etag))
+	assert.False(t, etagMatches("", etag))
+}
+
+// TestSendCacheable tests caching headers and conditional requests
+func TestSendCacheable(t *testing.T) {
+	app := fiber.New()
+	app.Get("/data", func(c *fiber.Ctx) error {
+		return sendCacheable(c, []byte(`{"data":[]}`), cacheControlFor(models.StatusCompleted))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/data", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/data", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+}
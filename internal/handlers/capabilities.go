@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+GetCapabilities handles GET /api/capabilities
+
+Lists the options clients can offer in a generation or export form. Every
+list comes from the table the feature itself validates against, so it
+cannot drift from what the API accepts. Server toggles are included so a
+form can hide disabled features.
+*/
+func (h *Handler) GetCapabilities(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"export_formats":  h.exportService.GetAvailableFormats(),
+		"bool_formats":    services.BoolPresetNames(),
+		"coerce_types":    services.CoerceTargets(),
+		"edge_case_kinds": models.EdgeCaseKinds,
+		"field_types":     models.SchemaTypes,
+		"sql_dialects":    services.SQLDialects(),
+		"models":          services.KnownModels(),
+		"request_models":  services.RequestModels(),
+		"limits": fiber.Map{
+			"min_row_count":        models.MinRowCount,
+			"max_row_count":        h.cfg.MaxRowCount,
+			"max_scenario_length":  h.cfg.MaxScenarioLength,
+			"max_field_prompts":    models.MaxFieldPrompts,
+			"max_edge_case_fields": models.MaxEdgeCaseFields,
+			"max_pattern_fields":   models.MaxPatternFields,
+			"max_pattern_length":   models.MaxPatternLength,
+			"max_schema_fields":    models.MaxSchemaFields,
+		},
+		"features": fiber.Map{
+			"field_prompts": h.cfg.FieldPromptsEnabled,
+			"fallback":      h.cfg.FallbackEnabled,
+			"encryption":    h.fpeService != nil,
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCapabilities tests that capabilities come from the feature registries
+func TestGetCapabilities(t *testing.T) {
+	h := &Handler{
+		cfg:           &config.Config{MaxScenarioLength: 500, MaxRowCount: 10000, FieldPromptsEnabled: true},
+		exportService: services.NewExportService(),
+	}
+
+	app := fiber.New()
+	app.Get("/api/capabilities", h.GetCapabilities)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/capabilities", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var caps struct {
+		ExportFormats []string                         `json:"export_formats"`
+		BoolFormats   []string                         `json:"bool_formats"`
+		CoerceTypes   []string                         `json:"coerce_types"`
+		EdgeCaseKinds []string                         `json:"edge_case_kinds"`
+		SQLDialects   []string                         `json:"sql_dialects"`
+		Models        map[string]services.Capabilities `json:"models"`
+		RequestModels []string                         `json:"request_models"`
+		Limits        map[string]int                   `json:"limits"`
+		Features      map[string]bool                  `json:"features"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&caps))
+
+	assert.Equal(t, services.NewExportService().GetAvailableFormats(), caps.ExportFormats)
+	assert.Equal(t, services.BoolPresetNames(), caps.BoolFormats)
+	assert.Equal(t, []string{services.CoerceBoolean, services.CoerceNumber, services.CoerceString}, caps.CoerceTypes)
+	assert.Equal(t, models.EdgeCaseKinds, caps.EdgeCaseKinds)
+	assert.Equal(t, []string{services.SQLDialectMySQL, services.SQLDialectPostgres}, caps.SQLDialects)
+	assert.Equal(t, services.ModelCapabilities("gpt-4o"), caps.Models["gpt-4o"])
+	assert.Equal(t, services.RequestModels(), caps.RequestModels)
+	assert.Equal(t, 500, caps.Limits["max_scenario_length"])
+	assert.Equal(t, 10000, caps.Limits["max_row_count"])
+	assert.Equal(t, map[string]bool{"field_prompts": true, "fallback": false, "encryption": false}, caps.Features)
+}
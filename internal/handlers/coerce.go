@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+// Upper bound on the failures listed in a coercion error response
+const maxCoercionFailures = 100
+
+/*
+CoerceFields handles PATCH /api/data/:id/coerce
+
+Converts whole columns of the stored dataset to another type, e.g. to keep
+zip codes as strings even though they are all digits:
+
+	{"fields": {"zip": "string", "price": "number", "active": "boolean"}}
+
+Nothing is changed if any value cannot be converted; the 422 response lists
+the failing values. On success the stored column types of the coerced
+fields are updated and changed rows count as modified for incremental
+exports.
+*/
+func (h *Handler) CoerceFields(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	var req models.CoerceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+	}
+
+	if len(req.Fields) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid fields",
+			Message: "fields must map at least one field to a type",
+		})
+	}
+
+	dataset, err := h.loadDataset(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	// Masked values only keep their shape, converting them would corrupt them
+	for field := range req.Fields {
+		if len(intersectFields([]string{field}, dataset.EncryptedFields)) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid fields",
+				Message: fmt.Sprintf("'%s' is encrypted and cannot be coerced", field),
+			})
+		}
+	}
+
+	result, err := services.CoerceColumns(dataset.Data, dataset.FieldNames, req.Fields)
+	if errors.Is(err, services.ErrInvalidExportOption) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid fields",
+			Message: err.Error(),
+		})
+	}
+
+	if errors.Is(err, services.ErrUncoercible) {
+		failures := result.Failures
+		if len(failures) > maxCoercionFailures {
+			failures = failures[:maxCoercionFailures]
+		}
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":    "Uncoercible values",
+			"message":  err.Error(),
+			"failures": failures,
+		})
+	}
+
+	fieldTypes := dataset.FieldTypes
+	if fieldTypes == nil {
+		fieldTypes = map[string]string{}
+	}
+	for field, colType := range result.FieldTypes {
+		fieldTypes[field] = colType
+	}
+
+	// Keep per-row change times aligned with the data
+	rowTimes := dataset.RowUpdatedAt
+	if len(rowTimes) != len(dataset.Data) {
+		rowTimes = uniformRowTimes(len(dataset.Data), dataset.CreatedAt)
+	}
+	now := time.Now()
+	for _, i := range result.ChangedRows {
+		rowTimes[i] = now
+	}
+
+	dataJSON, err := json.Marshal(dataset.Data)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Failed to serialize data",
+			Message: err.Error(),
+		})
+	}
+	rowTimesJSON, err := json.Marshal(rowTimes)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Failed to serialize data",
+			Message: err.Error(),
+		})
+	}
+	typesJSON, err := json.Marshal(fieldTypes)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Failed to serialize field types",
+			Message: err.Error(),
+		})
+	}
+
+	_, err = h.db.ExecContext(
+		c.UserContext(),
+		`UPDATE mock_datasets SET data = $1, row_updated_at = $2, field_types = $3 WHERE id = $4`,
+		dataJSON,
+		rowTimesJSON,
+		typesJSON,
+		dataset.ID,
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"request_id":   dataset.RequestID,
+		"rows_changed": len(result.ChangedRows),
+		"field_types":  fieldTypes,
+	})
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetMockData_Filter tests filtering dataset rows by field values
+func TestGetMockData_Filter(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "name": "Ann", "city": "London"},
+		{"id": 2, "name": "Bo", "city": "Paris"},
+		{"id": 3, "name": "Cy", "city": "London"},
+	}
+
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, data, []string{"id", "name", "city"})
+
+	app := fiber.New()
+	h := &Handler{cfg: &config.Config{}, db: db}
+	app.Get("/api/data/:id", h.GetMockData)
+	app.Get("/api/data/:id/preview", h.PreviewMockData)
+
+	get := func(path string) (int, models.DataResponse) {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+
+		var body models.DataResponse
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		}
+		return resp.StatusCode, body
+	}
+
+	status, body := get("/api/data/1?filter=city=London")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 2, body.RowCount)
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, "Ann", body.Data[0]["name"])
+	assert.Equal(t, "Cy", body.Data[1]["name"])
+
+	_, body = get("/api/data/1?filter=city=London&filter=id=3")
+	require.Len(t, body.Data, 1, "Filters are combined")
+	assert.Equal(t, "Cy", body.Data[0]["name"])
+
+	_, body = get("/api/data/1?filter=city=Rome")
+	assert.Equal(t, 0, body.RowCount)
+	assert.Empty(t, body.Data)
+
+	_, body = get("/api/data/1/preview?rows=1&filter=city=London")
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, 2, body.RowCount, "Previews count the matching rows")
+
+	status, _ = get("/api/data/1?filter=country=UK")
+	assert.Equal(t, fiber.StatusBadRequest, status, "Unknown fields are rejected")
+
+	status, _ = get("/api/data/1?filter=London")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+// TestGetMockData_StoredFieldTypes tests that stored column types are reported over inferred ones
+func TestGetMockData_StoredFieldTypes(t *testing.T) {
+	data := []map[string]interface{}{
+		{"zip": "02134", "price": "12.50", "name": "Ann"},
+	}
+
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, data, []string{"zip", "price", "name"})
+
+	serveDataset := fake.query
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		columns, rows, err := serveDataset(query, args)
+		if strings.Contains(query, "FROM mock_datasets") {
+			rows[0][6] = []byte(`{"zip":"TEXT","price":"NUMERIC"}`)
+		}
+		return columns, rows, err
+	}
+
+	app := fiber.New()
+	h := &Handler{cfg: &config.Config{}, db: db}
+	app.Get("/api/data/:id", h.GetMockData)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/data/1", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body models.DataResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, map[string]string{
+		"zip":   services.ValueTypeString,
+		"price": services.ValueTypeNumber,
+		"name":  services.ValueTypeString,
+	}, body.FieldTypes, "Fields without a stored type are inferred")
+}
+
+// TestExportMockData_Fields tests exporting a subset of the columns
+func TestExportMockData_Fields(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "name": "Ann", "email": "ann@example.com"},
+		{"id": 2, "name": "Bo", "email": "bo@example.com"},
+	}
+
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, data, []string{"id", "name", "email"})
+
+	app := fiber.New()
+	h := &Handler{cfg: &config.Config{}, db: db, exportService: services.NewExportService()}
+	app.Get("/api/data/:id/export", h.ExportMockData)
+
+	export := func(query string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/data/1/export?"+query, nil))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := export("format=csv&fields=id,name")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "id,name\n1,Ann\n2,Bo\n", body)
+
+	status, body = export("format=csv&fields=name,id")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "name,id\nAnn,1\nBo,2\n", body, "The requested order is kept")
+
+	status, body = export("format=sql&table=people&fields=id,name")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.NotContains(t, body, "email")
+	assert.Contains(t, body, "Ann")
+
+	status, _ = export("format=csv&fields=id,phone")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+// TestExportMockData_Metadata tests the generation metadata of exports
+func TestExportMockData_Metadata(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, []map[string]interface{}{{"id": 1, "name": "Ann"}}, []string{"id", "name"})
+
+	generated := time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)
+	serveDataset := fake.query
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "SELECT scenario, generated_at, model") {
+			return []string{"scenario", "generated_at", "model"}, [][]driver.Value{{"cities", generated, "gpt-4o"}}, nil
+		}
+		return serveDataset(query, args)
+	}
+
+	app := fiber.New()
+	h := &Handler{cfg: &config.Config{}, db: db, exportService: services.NewExportService()}
+	app.Get("/api/data/:id/export", h.ExportMockData)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/data/1/export?format=json&include_metadata=true", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Meta services.ExportMetadata `json:"_meta"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "cities", body.Meta.Scenario)
+	assert.Equal(t, "gpt-4o", body.Meta.Model)
+	assert.Equal(t, generated, body.Meta.GeneratedAt.UTC())
+	assert.Equal(t, 1, body.Meta.RowCount)
+}
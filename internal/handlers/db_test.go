@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/database"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatement is a statement run against a fakeDB
+type fakeStatement struct {
+	query string
+	args  []driver.Value
+}
+
+/*
+fakeDB is an in-process database/sql driver for handler tests, so they can
+run without PostgreSQL. Every statement is recorded; queries are answered
+by the query function, which returns column names and rows, and Exec
+calls succeed unless exec returns an error.
+*/
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []fakeStatement
+
+	query func(query string, args []driver.Value) ([]string, [][]driver.Value, error)
+	exec  func(query string, args []driver.Value) error
+}
+
+// requestColumns are the generation_requests columns read by the request endpoints
+var requestColumns = []string{"id", "scenario", "row_count", "status", "generated_at", "created_at", "updated_at", "pinned", "degraded", "model", "actual_row_count", "adjusted_row_count", "error_message", "error_code", "locale"}
+
+// requestRow returns a stored request in requestColumns order
+func requestRow(id int64, scenario string, rowCount int64, status string, created time.Time) []driver.Value {
+	return []driver.Value{id, scenario, rowCount, status, nil, created, created, false, false, "gpt-3.5-turbo", nil, nil, nil, nil, nil}
+}
+
+// serveDataset answers the queries of the data endpoints with a completed
+// request that has data with fields
+func (f *fakeDB) serveDataset(t *testing.T, data []map[string]interface{}, fields []string) {
+	dataJSON, err := json.Marshal(data)
+	require.NoError(t, err)
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	f.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "FROM mock_datasets") {
+			columns := []string{"id", "request_id", "data", "field_names", "encrypted_fields", "row_updated_at", "field_types", "created_at"}
+			row := []driver.Value{int64(1), int64(1), dataJSON, []byte("{" + strings.Join(fields, ",") + "}"), []byte("{}"), []byte("[]"), []byte("null"), created}
+			return columns, [][]driver.Value{row}, nil
+		}
+		return []string{"scenario", "status"}, [][]driver.Value{{"cities", models.StatusCompleted}}, nil
+	}
+}
+
+// newFakeDB returns a fake and a *database.DB backed by it
+func newFakeDB(t *testing.T) (*fakeDB, *database.DB) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+	return fake, &database.DB{DB: db}
+}
+
+// executed returns the recorded statements containing substr
+func (f *fakeDB) executed(substr string) []fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matches []fakeStatement
+	for _, statement := range f.statements {
+		if strings.Contains(statement.query, substr) {
+			matches = append(matches, statement)
+		}
+	}
+	return matches
+}
+
+func (f *fakeDB) record(query string, named []driver.NamedValue) []driver.Value {
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+
+	f.mu.Lock()
+	f.statements = append(f.statements, fakeStatement{query: query, args: args})
+	f.mu.Unlock()
+	return args
+}
+
+// Connect implements driver.Connector
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+
+// Driver implements driver.Connector
+func (f *fakeDB) Driver() driver.Driver { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDB: prepared statements are not supported")
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fakeDB: transactions are not supported")
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	args := c.db.record(query, named)
+	if c.db.exec != nil {
+		if err := c.db.exec(query, args); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := c.db.record(query, named)
+	if c.db.query == nil {
+		return &fakeRows{}, nil
+	}
+	columns, rows, err := c.db.query(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingGenerator always returns err
+type failingGenerator struct {
+	err error
+}
+
+func (f failingGenerator) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts services.GenerateOptions) ([]map[string]interface{}, []string, error) {
+	return nil, nil, f.err
+}
+
+// TestGenerateDataset_StoresFailureReason tests that failed requests keep the error
+func TestGenerateDataset_StoresFailureReason(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := &Handler{cfg: &config.Config{}, db: db, generator: failingGenerator{errors.New("rate limit exceeded")}}
+
+	_, err := h.generateDataset(context.Background(), 42, "users", 3, services.GenerateOptions{}, nil, false)
+	assert.EqualError(t, err, "Failed to generate data: rate limit exceeded")
+
+	failed := fake.executed("status = 'failed'")
+	require.Len(t, failed, 1)
+	assert.Equal(t, []driver.Value{"Failed to generate data: rate limit exceeded", models.ErrorCodeGenerationFailed, int64(42)}, failed[0].args)
+}
+
+// TestGenerateDataset_RecordsFallbackModel tests that the stored model is
+// the one that produced the data
+func TestGenerateDataset_RecordsFallbackModel(t *testing.T) {
+	fake, db := newFakeDB(t)
+	openaiService := services.NewOpenAIService("", services.OpenAIOptions{DefaultModel: "gpt-4o-mini", Mock: &services.MockOptions{}})
+	h := &Handler{cfg: &config.Config{}, db: db, generator: openaiService}
+
+	_, err := h.generateDataset(context.Background(), 41, "users", 3, services.GenerateOptions{}, nil, false)
+	require.NoError(t, err)
+
+	h.generator = failingGenerator{errors.New("service unavailable")}
+	h.fallback = services.NewFakerService()
+	result, err := h.generateDataset(context.Background(), 42, "users", 3, services.GenerateOptions{}, nil, false)
+	require.NoError(t, err)
+	require.True(t, result.degraded)
+
+	completed := fake.executed("status = 'completed'")
+	require.Len(t, completed, 2)
+	assert.Contains(t, completed[0].query, "model = COALESCE(NULLIF($4, ''), model)")
+	assert.Equal(t, []driver.Value{"gpt-4o-mini", nil, int64(41)}, completed[0].args[3:], "The model that generated the data")
+	assert.Equal(t, []driver.Value{services.FallbackModelName, nil, int64(42)}, completed[1].args[3:], "Not the model that failed")
+}
+
+// TestGenerateDataset_AdjustedRowCount tests storing how many rows the
+// schema's constraints changed, and keeping edge cases off constrained fields
+func TestGenerateDataset_AdjustedRowCount(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := &Handler{cfg: &config.Config{}, db: db, generator: &fakeGenerator{}}
+
+	_, err := h.generateDataset(context.Background(), 41, "users", 3, services.GenerateOptions{}, nil, false)
+	require.NoError(t, err)
+
+	// fakeGenerator repeats "role" in every row; Unique renames two of them
+	opts := services.GenerateOptions{
+		Fields:    []string{"name", "role"},
+		Schema:    []models.SchemaField{{Name: "name", Type: models.SchemaTypeString}, {Name: "role", Type: models.SchemaTypeString, Unique: true}},
+		EdgeCases: map[string]models.EdgeCaseRule{"role": {Probability: 1, Kinds: []string{models.EdgeCaseEmpty}}},
+	}
+	h.generator = repeatingGenerator{field: "role", value: "admin"}
+	result, err := h.generateDataset(context.Background(), 42, "users", 3, opts, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "admin 2", result.data[1]["role"], "No edge case replaced the enforced value")
+
+	completed := fake.executed("status = 'completed'")
+	require.Len(t, completed, 2)
+	assert.Contains(t, completed[0].query, "adjusted_row_count = $5")
+	assert.Nil(t, completed[0].args[4], "No schema, nothing to adjust")
+	assert.Equal(t, int64(2), completed[1].args[4])
+}
+
+// repeatingGenerator is fakeGenerator with the same value of field in every row
+type repeatingGenerator struct {
+	field string
+	value string
+}
+
+func (r repeatingGenerator) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts services.GenerateOptions) ([]map[string]interface{}, []string, error) {
+	data, fields, err := (&fakeGenerator{}).GenerateMockData(ctx, scenario, rowCount, opts)
+	for _, row := range data {
+		row[r.field] = r.value
+	}
+	return data, fields, err
+}
+
+// TestGetGenerationRequest_ErrorMessage tests that the failure reason and code are returned
+func TestGetGenerationRequest_ErrorMessage(t *testing.T) {
+	fake, db := newFakeDB(t)
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		row := requestRow(7, "users", 10, models.StatusFailed, created)
+		row[12] = "Failed to generate data: generation timed out after 1m0s"
+		row[13] = models.ErrorCodeTimeout
+		return requestColumns, [][]driver.Value{row}, nil
+	}
+
+	app := fiber.New()
+	app.Get("/api/requests/:id", (&Handler{db: db}).GetGenerationRequest)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/requests/7", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var request models.GenerationRequest
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&request))
+	assert.Equal(t, models.StatusFailed, request.Status)
+	require.NotNil(t, request.ErrorMessage)
+	assert.Equal(t, "Failed to generate data: generation timed out after 1m0s", *request.ErrorMessage)
+	require.NotNil(t, request.ErrorCode)
+	assert.Equal(t, models.ErrorCodeTimeout, *request.ErrorCode)
+}
+
+// TestTruncateMessage tests capping stored failure reasons
+func TestTruncateMessage(t *testing.T) {
+	assert.Equal(t, "short", truncateMessage("short", 10))
+	assert.Equal(t, "abcdefghij", truncateMessage("abcdefghij", 10))
+	assert.Equal(t, "abcdefghi…", truncateMessage("abcdefghijk", 10))
+	assert.Equal(t, "ééé…", truncateMessage(strings.Repeat("é", 8), 4), "Limits count characters, not bytes")
+}
+
+// sleepyGenerator blocks until its context ends, like a hung model call
+type sleepyGenerator struct{}
+
+func (sleepyGenerator) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts services.GenerateOptions) ([]map[string]interface{}, []string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return nil, nil, errors.New("the test deadline was not applied")
+	}
+}
+
+// TestGenerateDataset_Timeout tests failing a generation whose model call hangs
+func TestGenerateDataset_Timeout(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := &Handler{cfg: &config.Config{OpenAITimeout: 20 * time.Millisecond}, db: db, generator: sleepyGenerator{}}
+
+	_, err := h.generateDataset(context.Background(), 42, "users", 3, services.GenerateOptions{}, nil, false)
+	assert.ErrorIs(t, err, models.ErrGenerationTimeout)
+	assert.EqualError(t, err, "Failed to generate data: generation timed out after 20ms")
+
+	failed := fake.executed("status = 'failed'")
+	require.Len(t, failed, 1)
+	assert.Equal(t, []driver.Value{"Failed to generate data: generation timed out after 20ms", models.ErrorCodeTimeout, int64(42)}, failed[0].args, "Timeouts have their own error code")
+	assert.Empty(t, fake.executed("INSERT INTO mock_datasets"))
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+InferFieldTypes handles POST /api/data/:id/infer-types
+
+Runs whole-column type inference once and stores the result on the dataset,
+so SQL exports use it instead of guessing from the first row every time.
+
+The optional body corrects individual guesses:
+
+	{"overrides": {"price": "NUMERIC(10,2)", "created": "DATE"}}
+*/
+func (h *Handler) InferFieldTypes(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	var req models.InferTypesRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	dataset, err := h.loadDataset(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	overrides, err := services.ValidateFieldTypes(req.Overrides, dataset.FieldNames)
+	if errors.Is(err, services.ErrInvalidExportOption) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid overrides",
+			Message: err.Error(),
+		})
+	}
+
+	fieldTypes := services.InferFieldTypes(dataset.Data, dataset.FieldNames)
+	for field, colType := range overrides {
+		fieldTypes[field] = colType
+	}
+
+	typesJSON, err := json.Marshal(fieldTypes)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Failed to serialize field types",
+			Message: err.Error(),
+		})
+	}
+
+	_, err = h.db.ExecContext(
+		c.UserContext(),
+		`UPDATE mock_datasets SET field_types = $1 WHERE id = $2`,
+		typesJSON,
+		dataset.ID,
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(models.FieldTypesResponse{
+		RequestID:  dataset.RequestID,
+		FieldTypes: fieldTypes,
+	})
+}
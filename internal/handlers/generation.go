@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/lib/pq"
+)
+
+// generationResult is what a successful generateDataset produced
+type generationResult struct {
+	data     []map[string]interface{}
+	degraded bool   // placeholder data from the fallback generator
+	cached   bool   // served from the generation cache
+	model    string // model that produced the data; empty when unknown
+
+	// adjusted is how many rows were changed to meet the schema's
+	// constraints; nil without a schema
+	adjusted *int
+}
+
+/*
+generateDataset runs generation for an existing request row and stores the
+result, keeping the request status in sync (processing → completed/failed).
+
+It is shared by the generate endpoint and the admin regenerate endpoint so
+both follow exactly the same lifecycle. When OpenAI fails and the fallback
+is enabled, the request still completes but is flagged as degraded. With
+useCache, an identical earlier generation may be reused (see generate).
+*/
+func (h *Handler) generateDataset(ctx context.Context, requestID int64, scenario string, rowCount int, opts services.GenerateOptions, encryptFields []string, useCache bool) (*generationResult, error) {
+	if len(encryptFields) > 0 && h.fpeService == nil {
+		return nil, h.fail(requestID, "Failed to generate data", models.ErrEncryptionNotConfigured)
+	}
+
+	// Update status to processing
+	_, err := h.db.Exec(
+		`UPDATE generation_requests SET status = 'processing' WHERE id = $1`,
+		requestID,
+	)
+	if err != nil {
+		log.Printf("Failed to update status: %v", err)
+	}
+
+	// A hung model call must not hold the worker until GENERATE_TIMEOUT
+	generateCtx, cancel := ctx, context.CancelFunc(func() {})
+	if h.cfg.OpenAITimeout > 0 {
+		generateCtx, cancel = context.WithTimeout(ctx, h.cfg.OpenAITimeout)
+	}
+	generated, fieldNames, err := h.generate(generateCtx, scenario, rowCount, opts, useCache)
+	timedOut := errors.Is(generateCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	cancel()
+	if err != nil {
+		if timedOut {
+			err = fmt.Errorf("%w after %s", models.ErrGenerationTimeout, h.cfg.OpenAITimeout)
+		}
+		log.Printf("OpenAI error: %v", err)
+		return nil, h.fail(requestID, "Failed to generate data", err)
+	}
+	data, degraded := generated.data, generated.degraded
+
+	// Strictly formatted fields are generated locally, the model is unreliable
+	// at them; a request seed makes them reproducible like the rest
+	if len(opts.Patterns) > 0 {
+		patternSeed := time.Now().UnixNano()
+		if opts.Seed != nil {
+			patternSeed = int64(*opts.Seed)
+		}
+		fieldNames, err = services.ApplyPatterns(data, fieldNames, opts.Patterns, rand.New(rand.NewSource(patternSeed)))
+		if err != nil {
+			return nil, h.fail(requestID, "Failed to generate data", err)
+		}
+	}
+
+	// Boundary values for QA go in last so nothing above treats them as
+	// model output; fields with schema constraints keep their enforced values
+	if len(opts.EdgeCases) > 0 {
+		rules := services.UnconstrainedEdgeCases(opts.EdgeCases, opts.Schema)
+		if skipped := len(opts.EdgeCases) - len(rules); skipped > 0 {
+			log.Printf("Skipped edge cases for %d fields with schema constraints", skipped)
+		}
+		injected := services.InjectEdgeCases(data, rules, rand.New(rand.NewSource(opts.EdgeCaseSeed)))
+		log.Printf("Injected %d edge case values (seed %d)", injected, opts.EdgeCaseSeed)
+	}
+
+	// Mask requested fields before anything is persisted
+	encryptedFields := intersectFields(encryptFields, fieldNames)
+	if len(encryptedFields) > 0 {
+		h.fpeService.EncryptRows(data, encryptedFields)
+	}
+
+	// Convert data to JSONB for PostgreSQL
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, h.fail(requestID, "Failed to serialize data", err)
+	}
+
+	// Every row starts out modified at creation time
+	rowTimesJSON, err := json.Marshal(uniformRowTimes(len(data), time.Now()))
+	if err != nil {
+		return nil, h.fail(requestID, "Failed to serialize data", err)
+	}
+
+	// Save generated data to database
+	_, err = h.db.ExecContext(
+		ctx,
+		`INSERT INTO mock_datasets (request_id, data, field_names, encrypted_fields, row_updated_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		requestID,
+		dataJSON,
+		pq.Array(fieldNames),
+		pq.Array(encryptedFields),
+		rowTimesJSON,
+	)
+	if err != nil {
+		log.Printf("Failed to save dataset: %v", err)
+		return nil, h.fail(requestID, "Failed to save dataset", err)
+	}
+
+	// Update request status to completed; the model recorded up front is
+	// replaced by the one that produced the data, e.g. after a fallback
+	_, err = h.db.Exec(
+		`UPDATE generation_requests SET status = 'completed', generated_at = $1, degraded = $2, actual_row_count = $3, model = COALESCE(NULLIF($4, ''), model), adjusted_row_count = $5 WHERE id = $6`,
+		time.Now(),
+		degraded,
+		len(data),
+		generated.model,
+		generated.adjusted,
+		requestID,
+	)
+	if err != nil {
+		log.Printf("Failed to update status: %v", err)
+	}
+
+	log.Printf("Generation request %d completed successfully", requestID)
+
+	return &generationResult{data: data, degraded: degraded, cached: generated.cached}, nil
+}
+
+/*
+generate produces the rows of a dataset: the generator's output, or the
+fallback's during outages, with the row count policy and diversity check
+applied to model output.
+
+With useCache and a cache configured, requests that differ only in
+post-processing (patterns, edge cases, encryption) share results: the
+cache is keyed by scenario, row count, model, temperature and seed, and requests
+with reference values, per-field prompts, translated field names or an
+explicit schema are never cached. Degraded results are not cached either.
+*/
+func (h *Handler) generate(ctx context.Context, scenario string, rowCount int, opts services.GenerateOptions, useCache bool) (*generationResult, []string, error) {
+	useCache = useCache && h.cache != nil && cacheable(opts)
+
+	var key string
+	if useCache {
+		key = services.GenerationCacheKey(scenario, rowCount, opts.Model, opts.Temperature, opts.Seed)
+		if data, fieldNames, ok := h.cache.Get(key); ok {
+			log.Printf("Serving %d cached rows for an identical request", len(data))
+			return &generationResult{data: data, cached: true, model: h.modelName(opts)}, fieldNames, nil
+		}
+	}
+
+	// Generate mock data, or use the fallback during outages
+	data, fieldNames, degraded, err := services.GenerateWithFallback(ctx, h.generator, h.fallback, scenario, rowCount, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Model output can miss the requested row count; the fallback cannot
+	if !degraded {
+		data = h.enforceRowCount(ctx, scenario, data, fieldNames, rowCount, opts)
+	}
+
+	// Repetitive columns are only worth another call for model output
+	if !degraded {
+		h.checkDiversity(ctx, scenario, data, fieldNames, opts)
+	}
+
+	// An explicit schema is a contract; data that breaks it is not stored
+	var adjusted *int
+	if len(opts.Schema) > 0 {
+		count := services.EnforceSchemaConstraints(data, opts.Schema)
+		if count > 0 {
+			log.Printf("Adjusted %d rows to the field constraints", count)
+		}
+		adjusted = &count
+		if err := services.CheckSchema(data, fieldNames, opts.Schema); err != nil {
+			return nil, nil, err
+		}
+		fieldNames = services.SchemaFieldNames(opts.Schema)
+	}
+
+	if useCache && !degraded {
+		h.cache.Put(key, data, fieldNames)
+	}
+
+	model := h.modelName(opts)
+	if degraded {
+		model = generatorModel(h.fallback, opts)
+	}
+
+	return &generationResult{data: data, degraded: degraded, model: model, adjusted: adjusted}, fieldNames, nil
+}
+
+// modelName returns the model a generation will use, as recorded with the
+// request; empty when the generator can't tell
+func (h *Handler) modelName(opts services.GenerateOptions) string {
+	return generatorModel(h.generator, opts)
+}
+
+// generatorModel returns the model generator uses for opts; empty when it
+// can't tell
+func generatorModel(generator services.Generator, opts services.GenerateOptions) string {
+	if namer, ok := generator.(services.ModelNamer); ok {
+		return namer.ModelName(opts)
+	}
+	return ""
+}
+
+// cacheable reports whether a generation depends only on the cache key
+func cacheable(opts services.GenerateOptions) bool {
+	return len(opts.ReferenceValues) == 0 && len(opts.FieldPrompts) == 0 && opts.FieldNameLanguage == "" && len(opts.Schema) == 0 && opts.Locale == ""
+}
+
+// checkDiversity logs low-diversity fields and, when enabled and the
+// generator supports it, regenerates them once; a failed regeneration is
+// only logged
+func (h *Handler) checkDiversity(ctx context.Context, scenario string, data []map[string]interface{}, fieldNames []string, opts services.GenerateOptions) {
+	low := services.LowDiversityFields(services.MeasureDiversity(data, fieldNames, h.cfg.DiversityThreshold))
+	if len(low) == 0 {
+		return
+	}
+
+	log.Printf("⚠️ Low diversity in fields: %s", strings.Join(low, ", "))
+
+	diversifier, ok := h.generator.(services.Diversifier)
+	if !h.cfg.DiversityRegenerate || !ok {
+		return
+	}
+	if err := diversifier.DiversifyFields(ctx, scenario, data, fieldNames, low, opts); err != nil {
+		log.Printf("Failed to regenerate low-diversity fields: %v", err)
+	}
+}
+
+/*
+enforceRowCount applies ROW_COUNT_POLICY when the model returned a different
+number of rows than requested: warn only logs, truncate drops extra rows and
+topup also generates the missing ones. Shortfalls that remain are logged.
+*/
+func (h *Handler) enforceRowCount(ctx context.Context, scenario string, data []map[string]interface{}, fieldNames []string, rowCount int, opts services.GenerateOptions) []map[string]interface{} {
+	if len(data) == rowCount {
+		return data
+	}
+
+	log.Printf("⚠️ Model returned %d rows, %d requested (policy %s)", len(data), rowCount, h.cfg.RowCountPolicy)
+
+	switch h.cfg.RowCountPolicy {
+	case config.RowCountTruncate:
+		data = services.TruncateRows(data, rowCount)
+	case config.RowCountTopUp:
+		data = services.TopUpRows(ctx, h.generator, scenario, data, fieldNames, rowCount, opts)
+	}
+
+	if len(data) < rowCount && h.cfg.RowCountPolicy != config.RowCountWarn {
+		log.Printf("⚠️ Storing %d of %d requested rows", len(data), rowCount)
+	}
+	return data
+}
+
+// createGenerationRequest inserts a new pending request and returns its id;
+// an empty locale is stored as NULL
+func (h *Handler) createGenerationRequest(scenario string, rowCount int, model, locale string, schema []models.SchemaField) (int64, error) {
+	var schemaJSON []byte
+	if len(schema) > 0 {
+		var err error
+		if schemaJSON, err = json.Marshal(schema); err != nil {
+			return 0, fmt.Errorf("failed to create generation request: %w", err)
+		}
+	}
+
+	var requestID int64
+	err := h.db.QueryRow(
+		`INSERT INTO generation_requests (scenario, scenario_hash, row_count, model, locale, schema_fields, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, 'pending')
+		 RETURNING id`,
+		scenario,
+		models.ScenarioHash(scenario),
+		rowCount,
+		model,
+		sql.NullString{String: locale, Valid: locale != ""},
+		schemaJSON,
+	).Scan(&requestID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create generation request: %w", err)
+	}
+	return requestID, nil
+}
+
+// decodeSchemaFields reads the stored schema_fields of a request; nil
+// without a schema
+func decodeSchemaFields(raw []byte) ([]models.SchemaField, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var schema []models.SchemaField
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("failed to decode stored fields: %w", err)
+	}
+	return schema, nil
+}
+
+// uniformRowTimes returns n copies of t, one per row
+func uniformRowTimes(n int, t time.Time) []time.Time {
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = t
+	}
+	return times
+}
+
+// maxErrorMessageLength caps the failure reason stored with a request, in characters
+const maxErrorMessageLength = 1000
+
+// fail marks a request failed with err, prefixed by the failed step's title
+func (h *Handler) fail(requestID int64, title string, err error) error {
+	err = fmt.Errorf("%s: %w", title, err)
+	h.markFailed(requestID, err)
+	return err
+}
+
+// markFailed sets a request's status to failed and stores the reason,
+// truncated to maxErrorMessageLength, and its error code
+func (h *Handler) markFailed(requestID int64, reason error) {
+	_, err := h.db.Exec(
+		`UPDATE generation_requests SET status = 'failed', error_message = $1, error_code = $2 WHERE id = $3`,
+		truncateMessage(reason.Error(), maxErrorMessageLength),
+		models.FailureCode(reason),
+		requestID,
+	)
+	if err != nil {
+		log.Printf("Failed to update status: %v", err)
+	}
+}
+
+// truncateMessage shortens s to at most limit characters, marking the cut with an ellipsis
+func truncateMessage(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
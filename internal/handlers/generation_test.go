@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGenerator returns numbered rows of the requested fields or schema,
+// or the id and name fields, and records every call
+type fakeGenerator struct {
+	calls []services.GenerateOptions
+}
+
+func (f *fakeGenerator) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts services.GenerateOptions) ([]map[string]interface{}, []string, error) {
+	f.calls = append(f.calls, opts)
+
+	fields := opts.Fields
+	if len(fields) == 0 && len(opts.Schema) > 0 {
+		fields = services.SchemaFieldNames(opts.Schema)
+	}
+	if len(fields) == 0 {
+		fields = []string{"id", "name"}
+	}
+
+	data := make([]map[string]interface{}, rowCount)
+	for i := range data {
+		data[i] = map[string]interface{}{}
+		for _, field := range fields {
+			data[i][field] = fmt.Sprintf("%s %d", field, opts.RowOffset+i+1)
+		}
+	}
+	return data, fields, nil
+}
+
+// diversifyingGenerator also implements services.Diversifier
+type diversifyingGenerator struct {
+	fakeGenerator
+	diversified []string
+}
+
+func (d *diversifyingGenerator) DiversifyFields(ctx context.Context, scenario string, data []map[string]interface{}, fieldNames, fields []string, opts services.GenerateOptions) error {
+	d.diversified = append(d.diversified, fields...)
+	return nil
+}
+
+// TestEnforceRowCount tests each row count policy against a fake generator
+func TestEnforceRowCount(t *testing.T) {
+	ctx := context.Background()
+
+	rows := func(n int) []map[string]interface{} {
+		data := make([]map[string]interface{}, n)
+		for i := range data {
+			data[i] = map[string]interface{}{"id": float64(i + 1), "name": fmt.Sprintf("user %d", i+1)}
+		}
+		return data
+	}
+	enforce := func(policy string, data []map[string]interface{}) []map[string]interface{} {
+		h := &Handler{cfg: &config.Config{RowCountPolicy: policy}, generator: &fakeGenerator{}}
+		return h.enforceRowCount(ctx, "users", data, []string{"id", "name"}, 10, services.GenerateOptions{})
+	}
+
+	assert.Len(t, enforce(config.RowCountWarn, rows(7)), 7, "warn keeps short data")
+	assert.Len(t, enforce(config.RowCountWarn, rows(12)), 12, "warn keeps extra rows")
+
+	assert.Len(t, enforce(config.RowCountTruncate, rows(7)), 7, "truncate cannot add rows")
+	assert.Len(t, enforce(config.RowCountTruncate, rows(12)), 10)
+
+	topped := enforce(config.RowCountTopUp, rows(7))
+	assert.Len(t, topped, 10)
+	assert.Equal(t, rows(7), topped[:7], "Existing rows are kept")
+	for _, row := range topped[7:] {
+		assert.Len(t, row, 2, "Top-up rows have exactly the existing fields")
+	}
+	assert.Equal(t, "id 8", topped[7]["id"], "Top-up rows continue after the existing ones")
+	assert.Len(t, enforce(config.RowCountTopUp, rows(12)), 10)
+}
+
+// TestCheckDiversity tests that only generators that support it regenerate fields
+func TestCheckDiversity(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{DiversityThreshold: 0.3, DiversityRegenerate: true}
+
+	data := make([]map[string]interface{}, 20)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": float64(i + 1), "note": "same text for every row"}
+	}
+
+	diversifying := &diversifyingGenerator{}
+	(&Handler{cfg: cfg, generator: diversifying}).checkDiversity(ctx, "notes", data, []string{"id", "note"}, services.GenerateOptions{})
+	assert.Equal(t, []string{"note"}, diversifying.diversified)
+
+	// A generator without DiversifyFields is left alone
+	(&Handler{cfg: cfg, generator: &fakeGenerator{}}).checkDiversity(ctx, "notes", data, []string{"id", "note"}, services.GenerateOptions{})
+
+	cfg.DiversityRegenerate = false
+	diversifying = &diversifyingGenerator{}
+	(&Handler{cfg: cfg, generator: diversifying}).checkDiversity(ctx, "notes", data, []string{"id", "note"}, services.GenerateOptions{})
+	assert.Empty(t, diversifying.diversified, "Regeneration is opt-in")
+}
+
+// TestGenerate_Cache tests that identical requests reuse the generator's output
+func TestGenerate_Cache(t *testing.T) {
+	ctx := context.Background()
+	generator := &fakeGenerator{}
+	h := &Handler{cfg: &config.Config{}, generator: generator, cache: services.NewGenerationCache(10, time.Minute)}
+
+	first, fields, err := h.generate(ctx, "users", 3, services.GenerateOptions{}, true)
+	require.NoError(t, err)
+	assert.False(t, first.cached)
+
+	second, cachedFields, err := h.generate(ctx, "users", 3, services.GenerateOptions{}, true)
+	require.NoError(t, err)
+	assert.True(t, second.cached)
+	assert.Equal(t, first.data, second.data)
+	assert.Equal(t, fields, cachedFields)
+	assert.Len(t, generator.calls, 1, "The second identical call does not reach the generator")
+
+	temperature := 0.2
+	_, _, err = h.generate(ctx, "users", 3, services.GenerateOptions{Temperature: &temperature}, true)
+	require.NoError(t, err)
+	_, _, err = h.generate(ctx, "users", 3, services.GenerateOptions{Model: "gpt-4o"}, true)
+	require.NoError(t, err)
+	assert.Len(t, generator.calls, 3, "Other models and temperatures are generated")
+
+	forced, _, err := h.generate(ctx, "users", 3, services.GenerateOptions{}, false)
+	require.NoError(t, err)
+	assert.False(t, forced.cached)
+	assert.Len(t, generator.calls, 4, "cache=false always generates")
+
+	references := services.GenerateOptions{ReferenceValues: map[string][]string{"name": {"Ann"}}}
+	h.generate(ctx, "users", 3, references, true)
+	h.generate(ctx, "users", 3, references, true)
+	assert.Len(t, generator.calls, 6, "Requests with reference values are not cached")
+}
+
+// TestGenerate_Schema tests rejecting output that breaks the requested schema
+func TestGenerate_Schema(t *testing.T) {
+	ctx := context.Background()
+	generator := &fakeGenerator{}
+	h := &Handler{cfg: &config.Config{}, generator: generator, cache: services.NewGenerationCache(10, time.Minute)}
+
+	// fakeGenerator writes string values for the listed fields
+	textual := services.GenerateOptions{Schema: []models.SchemaField{{Name: "name", Type: models.SchemaTypeString}, {Name: "id", Type: models.SchemaTypeString}}}
+	textual.Fields = services.SchemaFieldNames(textual.Schema)
+	result, fields, err := h.generate(ctx, "users", 3, textual, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "id"}, fields)
+	assert.Len(t, result.data, 3)
+
+	h.generate(ctx, "users", 3, textual, true)
+	assert.Len(t, generator.calls, 2, "Requests with a schema are not cached")
+
+	numbers := services.GenerateOptions{Schema: []models.SchemaField{{Name: "id", Type: models.SchemaTypeNumber}, {Name: "name", Type: models.SchemaTypeString}}}
+	_, _, err = h.generate(ctx, "users", 3, numbers, false)
+	assert.ErrorIs(t, err, services.ErrSchemaMismatch)
+	assert.ErrorContains(t, err, `field 'id' of row 1 is "id 1", want number`)
+}
+
+// TestNewGenerator tests selecting the generator with GENERATOR_PROVIDER
+func TestNewGenerator(t *testing.T) {
+	opts := services.OpenAIOptions{DefaultModel: "gpt-4o-mini", Mock: &services.MockOptions{}}
+	openaiService := services.NewOpenAIService("", opts)
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		generator services.Generator
+		model     string
+	}{
+		{"OpenAI", &config.Config{Generator: config.GeneratorOpenAI}, openaiService, "gpt-4o-mini"},
+		{"Template", &config.Config{Generator: config.GeneratorTemplate}, &services.TemplateService{}, services.TemplateModelName},
+		{"Anthropic", &config.Config{Generator: config.GeneratorAnthropic, AnthropicAPIKey: "key", AnthropicModel: "claude-3-5-haiku-latest"}, &services.AnthropicService{}, "claude-3-5-haiku-latest"},
+		{"Ollama", &config.Config{Generator: config.GeneratorOllama, OllamaHost: "http://localhost:11434", OllamaModel: "llama3.1"}, &services.OllamaService{}, "llama3.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(tt.cfg, openaiService, opts)
+			if tt.generator == openaiService {
+				assert.Same(t, openaiService, generator)
+			} else {
+				assert.IsType(t, tt.generator, generator)
+			}
+			assert.Equal(t, tt.model, generatorModel(generator, services.GenerateOptions{}))
+		})
+	}
+}
+
+// TestModelName tests the model recorded with a generation request
+func TestModelName(t *testing.T) {
+	openaiService := services.NewOpenAIService("", services.OpenAIOptions{DefaultModel: "gpt-4o-mini", Mock: &services.MockOptions{}})
+	h := &Handler{generator: openaiService}
+	assert.Equal(t, "gpt-4o-mini", h.modelName(services.GenerateOptions{}), "The configured default")
+	assert.Equal(t, "gpt-4", h.modelName(services.GenerateOptions{Model: "gpt-4"}), "The request's model")
+
+	h.generator = services.NewTemplateService()
+	assert.Equal(t, services.TemplateModelName, h.modelName(services.GenerateOptions{Model: "gpt-4"}))
+
+	h.generator = &fakeGenerator{}
+	assert.Empty(t, h.modelName(services.GenerateOptions{}), "Generators that can't tell record nothing")
+}
+
+// TestGenerateDataset_SeededPatterns tests that a request seed makes pattern
+// values reproducible
+func TestGenerateDataset_SeededPatterns(t *testing.T) {
+	_, db := newFakeDB(t)
+	h := &Handler{cfg: &config.Config{}, db: db, generator: &fakeGenerator{}}
+
+	generate := func(seed int) []map[string]interface{} {
+		opts := services.GenerateOptions{Seed: &seed, Patterns: map[string]string{"code": `[A-Z]{3}-\d{4}`}}
+		result, err := h.generateDataset(context.Background(), 1, "products", 5, opts, nil, false)
+		require.NoError(t, err)
+		return result.data
+	}
+
+	assert.Equal(t, generate(42), generate(42), "The same seed yields the same pattern values")
+	assert.NotEqual(t, generate(42), generate(43))
+}
@@ -1,31 +1,90 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
 	"github.com/kennyg37/wrapperX/backend/internal/database"
+	"github.com/kennyg37/wrapperX/backend/internal/middleware"
 	"github.com/kennyg37/wrapperX/backend/internal/models"
 	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/lib/pq"
 )
 
 type Handler struct {
+	cfg           *config.Config
 	db            *database.DB
-	openaiService *services.OpenAIService
+	generator     services.Generator
 	exportService *services.ExportService
+	fpeService    *services.FPEService // nil when FPE_KEY is not configured
+	policy        *models.ScenarioPolicy
+	fallback      services.Generator        // nil unless FALLBACK_ENABLED
+	cache         *services.GenerationCache // nil when GENERATION_CACHE_TTL is 0
+	queue         *generationQueue
+	jobs          *backgroundJobs // bulk work outside the queue
 }
 
-// NewHandler creates a new handler instance
-func NewHandler(db *database.DB, openaiService *services.OpenAIService, exportService *services.ExportService) *Handler {
-	return &Handler{
+// NewHandler creates a new handler instance and starts its generation
+// workers; call Shutdown to stop them
+func NewHandler(cfg *config.Config, db *database.DB, generator services.Generator, exportService *services.ExportService, fpeService *services.FPEService) *Handler {
+	h := &Handler{
+		cfg:           cfg,
 		db:            db,
-		openaiService: openaiService,
+		generator:     generator,
 		exportService: exportService,
+		fpeService:    fpeService,
+		policy:        models.NewScenarioPolicy(cfg.ScenarioAllow, cfg.ScenarioDeny),
+		fallback:      newFallback(cfg),
+		cache:         newGenerationCache(cfg),
 	}
+	h.queue = newGenerationQueue(cfg.GenerateWorkers, cfg.GenerateQueueSize, h.runGeneration)
+	h.jobs = newBackgroundJobs()
+	return h
+}
+
+// newGenerationCache returns the cache for identical generation requests, if enabled
+func newGenerationCache(cfg *config.Config) *services.GenerationCache {
+	if cfg.GenerationCacheTTL <= 0 || cfg.GenerationCacheSize < 1 {
+		return nil
+	}
+	return services.NewGenerationCache(cfg.GenerationCacheSize, cfg.GenerationCacheTTL)
+}
+
+// NewGenerator returns the data generator selected by GENERATOR_PROVIDER; the
+// Anthropic and Ollama services share the OpenAI pipeline options
+func NewGenerator(cfg *config.Config, openaiService *services.OpenAIService, opts services.OpenAIOptions) services.Generator {
+	switch cfg.Generator {
+	case config.GeneratorTemplate:
+		log.Println("Generating data from field-name templates, OpenAI is not called")
+		return services.NewTemplateService()
+	case config.GeneratorAnthropic:
+		log.Printf("Generating data with Anthropic (%s)", cfg.AnthropicModel)
+		opts.DefaultModel = cfg.AnthropicModel
+		return services.NewAnthropicService(cfg.AnthropicAPIKey, opts)
+	case config.GeneratorOllama:
+		log.Printf("Generating data with Ollama at %s (%s)", cfg.OllamaHost, cfg.OllamaModel)
+		opts.DefaultModel = cfg.OllamaModel
+		return services.NewOllamaService(cfg.OllamaHost, opts)
+	}
+	return openaiService
+}
+
+// newFallback returns the offline generator used during OpenAI outages, if enabled
+func newFallback(cfg *config.Config) services.Generator {
+	if !cfg.FallbackEnabled {
+		return nil
+	}
+	return services.NewFakerService()
 }
 
 
@@ -33,6 +92,7 @@ func (h *Handler) GenerateMockData(c *fiber.Ctx) error {
 	// Parse request body
 	var req models.GenerateRequest
 	if err := c.BodyParser(&req); err != nil {
+		h.recordValidationFailure("invalid_body", 0)
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Error:   "Invalid request body",
 			Message: err.Error(),
@@ -40,102 +100,135 @@ func (h *Handler) GenerateMockData(c *fiber.Ctx) error {
 	}
 
 	// Validate request
-	if err := req.Validate(); err != nil {
+	if err := req.ValidateWithLimits(models.Limits{MaxScenarioLength: h.cfg.MaxScenarioLength, MaxRowCount: h.cfg.MaxRowCount}); err != nil {
+		h.recordValidationFailure(models.ValidationRule(err), len(req.Scenario))
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Error:   "Validation failed",
 			Message: err.Error(),
 		})
 	}
 
-	log.Printf("New generation request: %s (%d rows)", req.Scenario, req.RowCount)
-
-	// Create generation request in database
-	var requestID int64
-	err := h.db.QueryRow(
-		`INSERT INTO generation_requests (scenario, row_count, status)
-		 VALUES ($1, $2, 'pending')
-		 RETURNING id`,
-		req.Scenario,
-		req.RowCount,
-	).Scan(&requestID)
+	// Models are checked here since the allowlist lives with the services
+	if req.Model != "" && !services.IsRequestModel(req.Model) {
+		h.recordValidationFailure(models.ValidationRule(models.ErrInvalidModel), len(req.Scenario))
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("%s: '%s'. Use: %s", models.ErrInvalidModel.Error(), req.Model, strings.Join(services.RequestModels(), ", ")),
+		})
+	}
 
-	if err != nil {
-		log.Printf("Database error: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error:   "Database error",
+	// Organizational policy on which topics may be generated
+	if err := h.policy.Check(req.Scenario); err != nil {
+		h.recordValidationFailure(models.ValidationRule(err), len(req.Scenario))
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error:   "Scenario not allowed",
 			Message: err.Error(),
 		})
 	}
 
-	// Update status to processing
-	_, err = h.db.Exec(
-		`UPDATE generation_requests SET status = 'processing' WHERE id = $1`,
-		requestID,
-	)
-	if err != nil {
-		log.Printf("Failed to update status: %v", err)
+	if len(req.EncryptFields) > 0 && h.fpeService == nil {
+		h.recordValidationFailure(models.ValidationRule(models.ErrEncryptionNotConfigured), len(req.Scenario))
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: models.ErrEncryptionNotConfigured.Error(),
+		})
 	}
 
-	// Generate mock data using OpenAI
-	data, fieldNames, err := h.openaiService.GenerateMockData(c.Context(), req.Scenario, req.RowCount)
-	if err != nil {
-		// Update status to failed
-		_, _ = h.db.Exec(
-			`UPDATE generation_requests SET status = 'failed' WHERE id = $1`,
-			requestID,
-		)
-
-		log.Printf("OpenAI error: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error:   "Failed to generate data",
-			Message: err.Error(),
+	if len(req.FieldPrompts) > 0 && !h.cfg.FieldPromptsEnabled {
+		h.recordValidationFailure(models.ValidationRule(models.ErrFieldPromptsDisabled), len(req.Scenario))
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: models.ErrFieldPromptsDisabled.Error(),
 		})
 	}
 
-	// Convert data to JSONB for PostgreSQL
-	dataJSON, err := json.Marshal(data)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error:   "Failed to serialize data",
+	opts := services.GenerateOptions{Model: req.Model, Temperature: req.Temperature, Seed: req.Seed, FieldNameLanguage: req.FieldNameLanguage, Locale: req.Locale, FieldPrompts: req.FieldPrompts, EdgeCases: req.EdgeCases, Patterns: req.Patterns, Schema: req.Fields}
+
+	// Edge cases without a seed of their own follow the request seed, or
+	// still get one, logged so the run can be repeated
+	opts.EdgeCaseSeed = time.Now().UnixNano()
+	if req.EdgeCaseSeed != nil {
+		opts.EdgeCaseSeed = *req.EdgeCaseSeed
+	} else if req.Seed != nil {
+		opts.EdgeCaseSeed = int64(*req.Seed)
+	}
+
+	if req.Reference != nil {
+		values, err := h.resolveReference(c.UserContext(), req.Reference)
+		if errors.Is(err, models.ErrInvalidReference) {
+			h.recordValidationFailure(models.ValidationRule(err), len(req.Scenario))
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid reference",
+				Message: err.Error(),
+			})
+		}
+		if errors.Is(err, models.ErrRequestNotFound) || errors.Is(err, models.ErrDatasetNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "Reference not found",
+				Message: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		opts.ReferenceValues = values
+	}
+
+	// Pick the actual row count when a range was requested; a seeded
+	// request always picks the same one
+	rowCountSeed := time.Now().UnixNano()
+	if req.Seed != nil {
+		rowCountSeed = int64(*req.Seed)
+	}
+	req.ResolveRowCount(rand.New(rand.NewSource(rowCountSeed)))
+
+	// Budget the follow-up calls once the actual row count is known
+	if err := services.CheckFieldPromptBudget(req.RowCount, req.FieldPrompts, h.cfg.FieldPromptTokenBudget); err != nil {
+		h.recordValidationFailure("field_prompt_budget", len(req.Scenario))
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
 			Message: err.Error(),
 		})
 	}
 
-	// Save generated data to database
-	_, err = h.db.Exec(
-		`INSERT INTO mock_datasets (request_id, data, field_names)
-		 VALUES ($1, $2, $3)`,
-		requestID,
-		dataJSON,
-		fieldNames,
-	)
+	log.Printf("New generation request: %s (%d rows)", h.redactor().Text(req.Scenario), req.RowCount)
 
+	// Create generation request in database
+	requestID, err := h.createGenerationRequest(req.Scenario, req.RowCount, h.modelName(opts), req.Locale, req.Fields)
 	if err != nil {
-		log.Printf("Failed to save dataset: %v", err)
+		log.Printf("Database error: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error:   "Failed to save dataset",
+			Error:   "Database error",
 			Message: err.Error(),
 		})
 	}
 
-	// Update request status to completed
-	_, err = h.db.Exec(
-		`UPDATE generation_requests SET status = 'completed', generated_at = $1 WHERE id = $2`,
-		time.Now(),
-		requestID,
-	)
-
+	// Generation runs in the background, clients poll the request for the outcome
+	err = h.queue.enqueue(generationJob{
+		requestID:     requestID,
+		scenario:      req.Scenario,
+		rowCount:      req.RowCount,
+		opts:          opts,
+		encryptFields: req.EncryptFields,
+		useCache:      req.Cache == nil || *req.Cache, // "cache": false forces a fresh generation
+	})
 	if err != nil {
-		log.Printf("Failed to update status: %v", err)
+		h.markFailed(requestID, err)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
+			Error:   "Generation unavailable",
+			Message: err.Error(),
+		})
 	}
 
-	log.Printf("Generation request %d completed successfully", requestID)
-
-	// Return response
-	return c.Status(fiber.StatusCreated).JSON(models.GenerateResponse{
+	return c.Status(fiber.StatusAccepted).JSON(models.GenerateResponse{
 		ID:        requestID,
-		Status:    "completed",
-		Message:   fmt.Sprintf("Successfully generated %d rows of mock data", req.RowCount),
+		Status:    models.StatusPending,
+		Message:   fmt.Sprintf("Poll /api/requests/%d for completion", requestID),
+		RowCount:  req.RowCount,
+		Locale:    req.Locale,
 		CreatedAt: time.Now(),
 	})
 }
@@ -144,10 +237,14 @@ func (h *Handler) GenerateMockData(c *fiber.Ctx) error {
 func (h *Handler) GetGenerationRequest(c *fiber.Ctx) error {
 	// Get ID from URL parameter
 	id := c.Params("id")
+	if _, err := parseRequestID(id); err != nil {
+		return invalidID(c, err)
+	}
 
 	var request models.GenerationRequest
-	err := h.db.QueryRow(
-		`SELECT id, scenario, row_count, status, generated_at, created_at, updated_at
+	err := h.db.QueryRowContext(
+		c.UserContext(),
+		`SELECT id, scenario, row_count, status, generated_at, created_at, updated_at, pinned, degraded, model, actual_row_count, adjusted_row_count, error_message, error_code, locale
 		 FROM generation_requests
 		 WHERE id = $1`,
 		id,
@@ -159,6 +256,14 @@ func (h *Handler) GetGenerationRequest(c *fiber.Ctx) error {
 		&request.GeneratedAt,
 		&request.CreatedAt,
 		&request.UpdatedAt,
+		&request.Pinned,
+		&request.Degraded,
+		&request.Model,
+		&request.ActualRowCount,
+		&request.AdjustedRowCount,
+		&request.ErrorMessage,
+		&request.ErrorCode,
+		&request.Locale,
 	)
 
 	if err == sql.ErrNoRows {
@@ -175,16 +280,37 @@ func (h *Handler) GetGenerationRequest(c *fiber.Ctx) error {
 		})
 	}
 
+	// Status polling must always hit the server while work is in progress
+	if request.Status == models.StatusPending || request.Status == models.StatusProcessing {
+		c.Set(fiber.HeaderCacheControl, cacheNoStore)
+	}
+
 	return c.JSON(request)
 }
 
 func (h *Handler) GetMockData(c *fiber.Ctx) error {
+	return h.sendDataset(c, 0)
+}
+
+/*
+sendDataset responds with the dataset of the :id request, only its first
+limit rows when limit is positive; row_count is always the full count.
+
+Repeated filter=field=value query parameters keep only the rows where each
+field has that value; row_count then counts the matching rows.
+*/
+func (h *Handler) sendDataset(c *fiber.Ctx, limit int) error {
 	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
 
 	// Get request details
 	var scenario string
 	var status string
-	err := h.db.QueryRow(
+	err = h.db.QueryRowContext(
+		c.UserContext(),
 		`SELECT scenario, status FROM generation_requests WHERE id = $1`,
 		requestID,
 	).Scan(&scenario, &status)
@@ -204,25 +330,14 @@ func (h *Handler) GetMockData(c *fiber.Ctx) error {
 	}
 
 	if status != "completed" {
+		c.Set(fiber.HeaderCacheControl, cacheControlFor(status))
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Error:   "Data not available",
 			Message: fmt.Sprintf("Request status is '%s', data is only available for completed requests", status),
 		})
 	}
 
-	// Get dataset
-	var dataJSON []byte
-	var fieldNames []string
-	var createdAt time.Time
-	var datasetID int64
-
-	err = h.db.QueryRow(
-		`SELECT id, data, field_names, created_at
-		 FROM mock_datasets
-		 WHERE request_id = $1`,
-		requestID,
-	).Scan(&datasetID, &dataJSON, &fieldNames, &createdAt)
-
+	dataset, err := h.loadDataset(c.UserContext(), id)
 	if err == sql.ErrNoRows {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Error:   "Dataset not found",
@@ -237,55 +352,92 @@ func (h *Handler) GetMockData(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse JSON data
-	var data []map[string]interface{}
-	if err := json.Unmarshal(dataJSON, &data); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error:   "Failed to parse data",
-			Message: err.Error(),
-		})
+	data := dataset.Data
+	if rawFilters := queryValues(c, "filter"); len(rawFilters) > 0 {
+		filters, err := services.ParseRowFilters(rawFilters, dataset.FieldNames)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid filter",
+				Message: err.Error(),
+			})
+		}
+		data = services.FilterRowsByValue(data, filters)
 	}
 
 	// Build response
 	response := models.DataResponse{
-		ID:         datasetID,
-		RequestID:  int64(mustAtoi(requestID)),
+		ID:         dataset.ID,
+		RequestID:  id,
 		Scenario:   scenario,
 		Data:       data,
-		FieldNames: fieldNames,
+		FieldNames: dataset.FieldNames,
+		FieldTypes: services.StoredValueTypes(dataset.Data, dataset.FieldNames, dataset.FieldTypes),
 		RowCount:   len(data),
-		CreatedAt:  createdAt,
+		CreatedAt:  dataset.CreatedAt,
+	}
+	if limit > 0 && len(response.Data) > limit {
+		response.Data = response.Data[:limit]
 	}
 
-	return c.JSON(response)
+	body, err := json.Marshal(response)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Failed to serialize data",
+			Message: err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return sendCacheable(c, body, cacheControlFor(models.StatusCompleted))
 }
 
 /*
 ExportMockData handles GET /api/data/:id/export?format=csv
 
 This endpoint exports the mock data in different formats.
-Supported formats: json, csv, markdown, sql
+Supported formats: see ExportService.GetAvailableFormats
 
 Query parameters:
 - format: export format (default: json)
-- table: table name for SQL export (default: mock_data)
+- table: table name for SQL, COPY and SQLite export (default: derived from the scenario, e.g.
+  hospital_patients, or mock_data when nothing usable is left)
+- decrypt: decrypt format-preserving encrypted fields (requires X-Admin-Key)
+- bool_format: boolean preset for csv/markdown (truefalse, TRUEFALSE, yesno, yn, 10)
+- true_label / false_label: custom boolean labels (override bool_format)
+- bom: prefix CSV with a UTF-8 byte order mark for Excel
+- name: interface name for TypeScript export (default: MockData)
+- upsert: emit INSERT ... ON CONFLICT DO UPDATE for SQL export
+- copy: emit a PostgreSQL COPY ... FROM stdin block instead of INSERTs
+- sql_mode: row (one INSERT per row, default) or batch (100 rows per INSERT)
+- dialect: SQL dialect, postgres (default) or mysql
+- conflict: conflict column for upserts (default: primary key, then id)
+- pk: primary key column for SQL export (default: detected id/uuid field)
+- rows: only these zero-based row indices, in the order given
+- since: only rows changed after this time (RFC3339 or YYYY-MM-DD)
+- null_as: null rendering: empty, null, or a custom token (default: per format)
+- strict_fields: rows with keys outside field_names: error (422) or include
+- include_metadata: add generation metadata (_meta in JSON, comments in CSV/SQL)
+- fields: only these columns, comma-separated, in the order given
 */
 func (h *Handler) ExportMockData(c *fiber.Ctx) error {
 	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
 	format := c.Query("format", "json") // Default to JSON
-	tableName := c.Query("table", "mock_data")
 
-	// Get dataset
-	var dataJSON []byte
-	var fieldNames []string
+	decrypt := c.QueryBool("decrypt")
 
-	err := h.db.QueryRow(
-		`SELECT data, field_names
-		 FROM mock_datasets
-		 WHERE request_id = $1`,
-		requestID,
-	).Scan(&dataJSON, &fieldNames)
+	// Decrypting masked fields is restricted to admin callers
+	if decrypt && !middleware.HasAdminKey(c, h.cfg.AdminAPIKey) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "A valid admin key is required to decrypt exports",
+		})
+	}
 
+	dataset, err := h.loadDataset(c.UserContext(), id)
 	if err == sql.ErrNoRows {
 		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 			Error:   "Dataset not found",
@@ -300,70 +452,264 @@ func (h *Handler) ExportMockData(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse JSON data
-	var data []map[string]interface{}
-	if err := json.Unmarshal(dataJSON, &data); err != nil {
+	data := dataset.Data
+	fieldNames := dataset.FieldNames
+
+	if decrypt && len(dataset.EncryptedFields) > 0 {
+		if h.fpeService == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Decryption unavailable",
+				Message: models.ErrEncryptionNotConfigured.Error(),
+			})
+		}
+		h.fpeService.DecryptRows(data, dataset.EncryptedFields)
+	}
+
+	rowTimes := dataset.RowUpdatedAt
+
+	// Row subset: only the selected rows, in the order given
+	if raw := c.Query("rows"); raw != "" {
+		indices, err := parseRowIndices(raw, len(data))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid rows",
+				Message: err.Error(),
+			})
+		}
+		data, rowTimes = services.SelectRows(data, rowTimes, dataset.CreatedAt, indices)
+	}
+
+	// Incremental export: only rows changed after the given time
+	if raw := c.Query("since"); raw != "" {
+		since, err := parseTimestamp(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid since",
+				Message: err.Error(),
+			})
+		}
+		data = services.FilterRowsSince(data, rowTimes, dataset.CreatedAt, since)
+	}
+
+	// Column subset: only the selected fields, in the order given
+	if raw := c.Query("fields"); raw != "" {
+		fieldNames, err = parseFieldSubset(raw, fieldNames)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid fields",
+				Message: err.Error(),
+			})
+		}
+		data = services.SelectFields(data, fieldNames)
+	}
+
+	opts, err := exportOptionsFromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: err.Error(),
+		})
+	}
+	opts.FieldTypes = dataset.FieldTypes
+	opts.ArchiveName = fmt.Sprintf("mockdata-%s", requestID)
+
+	if c.QueryBool("include_metadata") {
+		opts.Metadata, err = h.loadExportMetadata(c.UserContext(), dataset)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	tableName, err := h.exportTableName(c.UserContext(), c.Query("table"), format, dataset.RequestID)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
-			Error:   "Failed to parse data",
+			Error:   "Database error",
 			Message: err.Error(),
 		})
 	}
 
+	exporter := h.exportService.WithOptions(opts)
+
 	// Export data in requested format
-	var exportData []byte
-	var contentType string
-	var filename string
-
-	switch format {
-	case "json":
-		exportData, err = h.exportService.ToJSON(data, fieldNames)
-		contentType = "application/json"
-		filename = fmt.Sprintf("mockdata-%s.json", requestID)
-
-	case "csv":
-		exportData, err = h.exportService.ToCSV(data, fieldNames)
-		contentType = "text/csv"
-		filename = fmt.Sprintf("mockdata-%s.csv", requestID)
-
-	case "markdown", "md":
-		exportData, err = h.exportService.ToMarkdownTable(data, fieldNames)
-		contentType = "text/markdown"
-		filename = fmt.Sprintf("mockdata-%s.md", requestID)
-
-	case "sql":
-		exportData, err = h.exportService.ToSQL(data, fieldNames, tableName)
-		contentType = "application/sql"
-		filename = fmt.Sprintf("mockdata-%s.sql", requestID)
+	result, err := exporter.Export(format, data, fieldNames, tableName)
+	if err != nil {
+		return exportError(c, err)
+	}
 
-	default:
+	// Set headers for file download
+	c.Set("Content-Type", result.ContentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=mockdata-%s.%s", requestID, result.Extension))
+
+	// Datasets only exist for completed requests; decrypted output is never
+	// stored at all
+	cacheControl := cacheControlFor(models.StatusCompleted)
+	if decrypt {
+		cacheControl = cachePrivateNoStore
+	}
+
+	return sendCacheable(c, result.Data, cacheControl)
+}
+
+// formatList joins formats as "a, b, or c"
+func formatList(formats []string) string {
+	if len(formats) < 2 {
+		return strings.Join(formats, "")
+	}
+	return strings.Join(formats[:len(formats)-1], ", ") + ", or " + formats[len(formats)-1]
+}
+
+// exportError maps an export failure to its HTTP response
+func exportError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUnsupportedFormat):
 		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
 			Error:   "Invalid format",
-			Message: fmt.Sprintf("Format '%s' is not supported. Use: json, csv, markdown, or sql", format),
+			Message: fmt.Sprintf("%s. Use: %s", err.Error(), formatList(services.NewExportService().GetAvailableFormats())),
 		})
-	}
 
-	if err != nil {
+	case errors.Is(err, services.ErrFieldMismatch):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(models.ErrorResponse{
+			Error:   "Field mismatch",
+			Message: err.Error(),
+		})
+
+	case errors.Is(err, services.ErrIncompatibleSchemas):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(models.ErrorResponse{
+			Error:   "Incompatible datasets",
+			Message: err.Error(),
+		})
+
+	case errors.Is(err, services.ErrTemplateLimit):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(models.ErrorResponse{
+			Error:   "Template limit exceeded",
+			Message: err.Error(),
+		})
+
+	case errors.Is(err, services.ErrNoData):
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "No data",
+			Message: "No rows match the export filters",
+		})
+
+	case errors.Is(err, services.ErrInvalidExportOption):
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: err.Error(),
+		})
+
+	default:
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
 			Error:   "Export failed",
 			Message: err.Error(),
 		})
 	}
+}
 
-	// Set headers for file download
-	c.Set("Content-Type", contentType)
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 
-	return c.Send(exportData)
-}
+/*
+ListGenerationRequests handles GET /api/requests
 
+Requests are returned newest first. Two ways of paging are supported:
+- limit/offset: simple, but pages shift when new requests arrive
+- limit/after: pass the next_cursor of the previous page for stable paging
 
+Query parameters:
+- limit: page size (default 100, max 100)
+- offset: number of requests to skip (cannot be combined with after)
+- after: opaque cursor returned as next_cursor
+- status: only list requests with this status
+- search: only list requests whose scenario contains this text (any case)
+- sort: created_at (default), updated_at, row_count or status
+- order: asc or desc (default)
+
+Cursors only work in the default order; other orders page with offset.
+*/
 func (h *Handler) ListGenerationRequests(c *fiber.Ctx) error {
-	rows, err := h.db.Query(
-		`SELECT id, scenario, row_count, status, generated_at, created_at, updated_at
-		 FROM generation_requests
-		 ORDER BY created_at DESC
-		 LIMIT 100`,
-	)
+	limit := c.QueryInt("limit", defaultPageSize)
+	offset := c.QueryInt("offset", 0)
+	after := c.Query("after")
+	status := c.Query("status")
+	search := strings.TrimSpace(c.Query("search"))
+	sortBy := c.Query("sort", defaultRequestSort)
+	order := strings.ToLower(c.Query("order", defaultRequestOrder))
+
+	if limit < 1 || limit > maxPageSize || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid pagination",
+			Message: fmt.Sprintf("limit must be between 1 and %d and offset must not be negative", maxPageSize),
+		})
+	}
+
+	orderBy, err := requestOrderBy(sortBy, order)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid sort",
+			Message: err.Error(),
+		})
+	}
+	cursorOrder := sortBy == defaultRequestSort && order == defaultRequestOrder
+
+	if status != "" && !models.IsValidStatus(status) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("Unknown status '%s'", status),
+		})
+	}
+
+	query := `SELECT id, scenario, row_count, status, generated_at, created_at, updated_at, pinned, degraded, model, actual_row_count, adjusted_row_count, error_message, error_code, locale
+		 FROM generation_requests`
+	args := []interface{}{}
+	conditions := []string{}
+
+	if status != "" {
+		args = append(args, status)
+		conditions = append(conditions, fmt.Sprintf(`status = $%d`, len(args)))
+	}
+
+	if search != "" {
+		args = append(args, "%"+escapeLike(search)+"%")
+		conditions = append(conditions, fmt.Sprintf(`scenario ILIKE $%d`, len(args)))
+	}
+
+	if after != "" {
+		if !cursorOrder {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid pagination",
+				Message: "after only works with the default sort, use offset",
+			})
+		}
+
+		if offset > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid pagination",
+				Message: "after and offset cannot be combined",
+			})
+		}
+
+		cursor, err := decodeCursor(after)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid pagination",
+				Message: err.Error(),
+			})
+		}
+
+		// created_at is a TIMESTAMP; cast so the session time zone is not applied
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf(`(created_at, id) < ($%d::timestamp, $%d)`, len(args)-1, len(args)))
+	}
+
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	query += fmt.Sprintf(` ORDER BY %s LIMIT $%d OFFSET $%d`, orderBy, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := h.db.QueryContext(c.UserContext(), query, args...)
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
@@ -385,6 +731,14 @@ func (h *Handler) ListGenerationRequests(c *fiber.Ctx) error {
 			&req.GeneratedAt,
 			&req.CreatedAt,
 			&req.UpdatedAt,
+			&req.Pinned,
+			&req.Degraded,
+			&req.Model,
+			&req.ActualRowCount,
+			&req.AdjustedRowCount,
+			&req.ErrorMessage,
+			&req.ErrorCode,
+			&req.Locale,
 		)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
@@ -402,24 +756,270 @@ func (h *Handler) ListGenerationRequests(c *fiber.Ctx) error {
 		})
 	}
 
+	var next *string
+	if cursorOrder {
+		next = nextCursor(requests, limit)
+	}
+
 	return c.JSON(fiber.Map{
-		"requests": requests,
-		"count":    len(requests),
+		"requests":    requests,
+		"count":       len(requests),
+		"next_cursor": next,
 	})
 }
 
-// HealthCheck handles GET /api/health
-func (h *Handler) HealthCheck(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{
-		"status":  "ok",
-		"service": "mock-data-generator",
-		"time":    time.Now().Format(time.RFC3339),
-	})
+// likeEscaper escapes the LIKE wildcards, with PostgreSQL's default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match itself literally in a LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// exportOptionsFromQuery builds export options from the query string
+func exportOptionsFromQuery(c *fiber.Ctx) (services.ExportOptions, error) {
+	opts := services.DefaultExportOptions()
+
+	if preset := c.Query("bool_format"); preset != "" {
+		trueLabel, falseLabel, err := services.BoolPreset(preset)
+		if err != nil {
+			return opts, err
+		}
+		opts.TrueLabel, opts.FalseLabel = trueLabel, falseLabel
+	}
+
+	if label, ok := queryValue(c, "true_label"); ok {
+		opts.TrueLabel = label
+	}
+	if label, ok := queryValue(c, "false_label"); ok {
+		opts.FalseLabel = label
+	}
+
+	opts.Upsert = c.QueryBool("upsert")
+	opts.ConflictColumn = c.Query("conflict")
+	opts.PrimaryKey = c.Query("pk")
+	opts.Copy = c.QueryBool("copy")
+	opts.NullAs = c.Query("null_as")
+	opts.BOM = c.QueryBool("bom")
+	opts.TypeName = c.Query("name")
+
+	switch mode := c.Query("sql_mode"); mode {
+	case "", "row":
+		opts.SQLMode = services.SQLModeRow
+	case services.SQLModeBatch:
+		opts.SQLMode = mode
+	default:
+		return opts, fmt.Errorf("unknown sql_mode '%s' (use row or batch)", mode)
+	}
+
+	dialect, err := services.ParseSQLDialect(c.Query("dialect"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Dialect = dialect
+
+	switch mode := c.Query("strict_fields"); mode {
+	case services.StrictFieldsOff, services.StrictFieldsError, services.StrictFieldsInclude:
+		opts.StrictFields = mode
+	default:
+		return opts, fmt.Errorf("unknown strict_fields mode '%s' (use error or include)", mode)
+	}
+
+	return opts, nil
+}
+
+// parseTimestamp accepts RFC3339 timestamps or plain dates (UTC)
+func parseTimestamp(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not an RFC3339 timestamp or YYYY-MM-DD date", raw)
+}
+
+// queryValue returns a query parameter and whether it was present at all
+func queryValue(c *fiber.Ctx, key string) (string, bool) {
+	args := c.Context().QueryArgs()
+	if !args.Has(key) {
+		return "", false
+	}
+	return string(args.Peek(key)), true
+}
+
+// queryValues returns every value of a repeated query parameter
+func queryValues(c *fiber.Ctx, key string) []string {
+	var values []string
+	for _, value := range c.Context().QueryArgs().PeekMulti(key) {
+		values = append(values, string(value))
+	}
+	return values
+}
+
+// redactor returns the log redactor configured for this deployment
+func (h *Handler) redactor() services.LogRedactor {
+	return services.LogRedactor{Enabled: h.cfg.LogRedact}
+}
+
+// loadDataset fetches and decodes the dataset generated for a request.
+// Returns sql.ErrNoRows when the request has no dataset.
+func (h *Handler) loadDataset(ctx context.Context, requestID int64) (*models.MockDataset, error) {
+	var dataset models.MockDataset
+	var dataJSON, rowTimesJSON, fieldTypesJSON []byte
+
+	err := h.db.QueryRowContext(
+		ctx,
+		`SELECT id, request_id, data, field_names, encrypted_fields, row_updated_at, field_types, created_at
+		 FROM mock_datasets
+		 WHERE request_id = $1`,
+		requestID,
+	).Scan(
+		&dataset.ID,
+		&dataset.RequestID,
+		&dataJSON,
+		pq.Array(&dataset.FieldNames),
+		pq.Array(&dataset.EncryptedFields),
+		&rowTimesJSON,
+		&fieldTypesJSON,
+		&dataset.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := services.DecodeJSON(dataJSON, &dataset.Data, h.cfg.JSONUseNumber); err != nil {
+		return nil, fmt.Errorf("failed to parse data: %w", err)
+	}
+
+	if err := json.Unmarshal(rowTimesJSON, &dataset.RowUpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse row timestamps: %w", err)
+	}
+
+	if err := json.Unmarshal(fieldTypesJSON, &dataset.FieldTypes); err != nil {
+		return nil, fmt.Errorf("failed to parse field types: %w", err)
+	}
+
+	return &dataset, nil
 }
 
-// Helper function to convert string to int
-func mustAtoi(s string) int {
-	var result int
-	fmt.Sscanf(s, "%d", &result)
+// tableFormats are the export formats that name a database table
+var tableFormats = map[string]bool{"sql": true, "copy": true, "sqlite": true}
+
+// exportTableName returns the table name for an export: the table parameter
+// when given, otherwise (for table formats) a name derived from the request's scenario
+func (h *Handler) exportTableName(ctx context.Context, table, format string, requestID int64) (string, error) {
+	if table != "" {
+		return table, nil
+	}
+	if !tableFormats[format] {
+		return services.DefaultTableName, nil
+	}
+
+	var scenario string
+	err := h.db.QueryRowContext(ctx, `SELECT scenario FROM generation_requests WHERE id = $1`, requestID).Scan(&scenario)
+	if err != nil {
+		return "", fmt.Errorf("failed to load scenario: %w", err)
+	}
+
+	return services.TableNameFromScenario(scenario), nil
+}
+
+// loadExportMetadata describes how a dataset was generated for provenance
+func (h *Handler) loadExportMetadata(ctx context.Context, dataset *models.MockDataset) (*services.ExportMetadata, error) {
+	var meta services.ExportMetadata
+	var generatedAt sql.NullTime
+
+	err := h.db.QueryRowContext(
+		ctx,
+		`SELECT scenario, generated_at, model FROM generation_requests WHERE id = $1`,
+		dataset.RequestID,
+	).Scan(&meta.Scenario, &generatedAt, &meta.Model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load generation metadata: %w", err)
+	}
+
+	// Datasets are saved just before the request is completed
+	meta.GeneratedAt = dataset.CreatedAt
+	if generatedAt.Valid {
+		meta.GeneratedAt = generatedAt.Time
+	}
+
+	return &meta, nil
+}
+
+// resolveReference loads the values a new dataset should reuse from an
+// existing one. Errors wrapping ErrInvalidReference are client errors, and
+// ErrRequestNotFound or ErrDatasetNotFound mean there is nothing to reuse.
+func (h *Handler) resolveReference(ctx context.Context, ref *models.DatasetReference) (map[string][]string, error) {
+	dataset, err := h.loadDataset(ctx, ref.RequestID)
+	if err == sql.ErrNoRows {
+		var exists bool
+		err = h.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM generation_requests WHERE id = $1)`, ref.RequestID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: no generation request with ID %d", models.ErrRequestNotFound, ref.RequestID)
+		}
+		return nil, fmt.Errorf("%w: request %d has no generated data", models.ErrDatasetNotFound, ref.RequestID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if missing := missingFields(ref.Fields, dataset.FieldNames); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: fields %v not found in dataset %d", models.ErrInvalidReference, missing, ref.RequestID)
+	}
+
+	return services.ExtractReferenceValues(dataset.Data, ref.Fields, services.MaxReferenceValues), nil
+}
+
+// missingFields returns the requested fields that do not exist in fieldNames
+func missingFields(requested, fieldNames []string) []string {
+	known := make(map[string]bool, len(fieldNames))
+	for _, name := range fieldNames {
+		known[name] = true
+	}
+
+	missing := []string{}
+	for _, name := range requested {
+		if !known[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// intersectFields returns the requested fields that exist in fieldNames
+func intersectFields(requested, fieldNames []string) []string {
+	known := make(map[string]bool, len(fieldNames))
+	for _, name := range fieldNames {
+		known[name] = true
+	}
+
+	result := []string{}
+	for _, name := range requested {
+		if known[name] {
+			result = append(result, name)
+		}
+	}
 	return result
 }
+
+// parseRequestID parses an id path parameter; ids are positive integers
+func parseRequestID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid id '%s': must be a positive integer", s)
+	}
+	return id, nil
+}
+
+// invalidID responds 400 for an id path parameter parseRequestID rejected
+func invalidID(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		Error:   "Invalid id",
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRequestID tests parsing id path parameters
+func TestParseRequestID(t *testing.T) {
+	id, err := parseRequestID("42")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	for _, s := range []string{"abc", "-1", "0", "", "1.5", "12abc", " 7", "99999999999999999999"} {
+		_, err := parseRequestID(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// TestExportError_Formats tests listing every available format for an unsupported one
+func TestExportError_Formats(t *testing.T) {
+	assert.Equal(t, "csv", formatList([]string{"csv"}))
+	assert.Equal(t, "csv, json, or zip", formatList([]string{"csv", "json", "zip"}))
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return exportError(c, services.ErrUnsupportedFormat)
+	})
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), formatList(services.NewExportService().GetAvailableFormats()))
+}
+
+// TestInvalidRequestIDs tests rejecting malformed ids before querying
+func TestInvalidRequestIDs(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := &Handler{cfg: &config.Config{}, db: db}
+
+	app := fiber.New()
+	app.Get("/api/requests/:id", h.GetGenerationRequest)
+	app.Post("/api/requests/:id/pin", h.PinRequest)
+	app.Post("/api/requests/:id/unpin", h.UnpinRequest)
+	app.Post("/api/requests/:id/regenerate", h.RegenerateRequest)
+	app.Get("/api/data/:id", h.GetMockData)
+	app.Get("/api/data/:id/preview", h.PreviewMockData)
+	app.Get("/api/data/:id/export", h.ExportMockData)
+	app.Get("/api/data/:id/export/all", h.ExportAllFormats)
+	app.Post("/api/data/:id/export/template", h.ExportTemplate)
+	app.Get("/api/data/:id/quality", h.GetQuality)
+	app.Post("/api/data/:id/sql/validate", h.ValidateSQL)
+	app.Patch("/api/data/:id/coerce", h.CoerceFields)
+	app.Post("/api/data/:id/infer-types", h.InferFieldTypes)
+	app.Get("/api/jobs/:id", h.GetJob)
+	app.Get("/api/jobs/:id/result", h.GetJobResult)
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/api/requests/abc"},
+		{"GET", "/api/requests/-1"},
+		{"POST", "/api/requests/abc/pin"},
+		{"POST", "/api/requests/abc/unpin"},
+		{"POST", "/api/requests/abc/regenerate"},
+		{"GET", "/api/data/abc"},
+		{"GET", "/api/data/-3"},
+		{"GET", "/api/data/0"},
+		{"GET", "/api/data/abc/preview"},
+		{"GET", "/api/data/abc/export"},
+		{"GET", "/api/data/-1/export?format=csv"},
+		{"GET", "/api/data/abc/export/all"},
+		{"POST", "/api/data/abc/export/template"},
+		{"GET", "/api/data/abc/quality"},
+		{"POST", "/api/data/abc/sql/validate"},
+		{"PATCH", "/api/data/abc/coerce"},
+		{"POST", "/api/data/abc/infer-types"},
+		{"GET", "/api/jobs/abc"},
+		{"GET", "/api/jobs/abc/result"},
+	} {
+		resp, err := app.Test(httptest.NewRequest(route.method, route.path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, route.method+" "+route.path)
+	}
+
+	assert.Empty(t, fake.executed(""), "The database is never queried")
+}
+
+// TestGenerateMockData_MissingReference tests that references to missing
+// requests or datasets are 404s rather than validation failures
+func TestGenerateMockData_MissingReference(t *testing.T) {
+	h, fake := newQueueTestHandler(t, &fakeGenerator{})
+	t.Cleanup(func() { h.Shutdown(context.Background()) })
+
+	requestExists := false
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "FROM mock_datasets") {
+			return []string{"id"}, nil, nil
+		}
+		if strings.Contains(query, "SELECT EXISTS") {
+			return []string{"exists"}, [][]driver.Value{{requestExists}}, nil
+		}
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	}
+
+	app := fiber.New()
+	app.Post("/api/generate", h.GenerateMockData)
+
+	generate := func() (int, string) {
+		body := `{"scenario": "Orders of our customers", "row_count": 3, "reference": {"request_id": 5, "fields": ["name"]}}`
+		req := httptest.NewRequest("POST", "/api/generate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(raw)
+	}
+
+	status, body := generate()
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Contains(t, body, models.ErrRequestNotFound.Error())
+
+	requestExists = true
+	status, body = generate()
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Contains(t, body, models.ErrDatasetNotFound.Error())
+
+	assert.Empty(t, fake.executed("INSERT INTO validation_failures"), "Missing references are not validation failures")
+	assert.Empty(t, fake.executed("INSERT INTO generation_requests"))
+}
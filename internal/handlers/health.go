@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+)
+
+// healthPingTimeout bounds the database ping of the readiness check, so a
+// hung connection fails the check instead of the load balancer's probe
+const healthPingTimeout = 2 * time.Second
+
+// LivenessCheck handles GET /api/live: the process is up and serving, nothing
+// else is checked so a slow dependency never gets the instance restarted
+func (h *Handler) LivenessCheck(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status":  "ok",
+		"service": "mock-data-generator",
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+/*
+ReadinessCheck handles GET /api/ready and its alias GET /api/health
+
+Responds 503 with status "degraded" when the database does not answer a
+ping or the configured generator lacks its API key or host, so load
+balancers stop routing generate requests that could only fail.
+*/
+func (h *Handler) ReadinessCheck(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), healthPingTimeout)
+	defer cancel()
+
+	status, database, generator, code := "ok", "up", "configured", fiber.StatusOK
+	if err := h.db.PingContext(ctx); err != nil {
+		log.Printf("Readiness check database ping failed: %v", err)
+		status, database, code = "degraded", "down", fiber.StatusServiceUnavailable
+	}
+	if !generatorConfigured(h.cfg) {
+		status, generator, code = "degraded", "missing", fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"status":    status,
+		"database":  database,
+		"generator": generator,
+		"service":   "mock-data-generator",
+		"time":      time.Now().Format(time.RFC3339),
+	})
+}
+
+// generatorConfigured reports whether the configured generator has the key or
+// host it needs; the OpenAI generator needs no key in mock mode
+func generatorConfigured(cfg *config.Config) bool {
+	switch cfg.Generator {
+	case config.GeneratorTemplate:
+		return true
+	case config.GeneratorAnthropic:
+		return cfg.AnthropicAPIKey != ""
+	case config.GeneratorOllama:
+		return cfg.OllamaHost != ""
+	}
+	return cfg.OpenAIMode == config.OpenAIModeMock || cfg.OpenAIAPIKey != ""
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probe sends GET path to handler and returns the status code and JSON body
+func probe(t *testing.T, path string, handler fiber.Handler) (int, map[string]interface{}) {
+	app := fiber.New()
+	app.Get(path, handler)
+
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+// TestLivenessCheck tests that liveness ignores the database
+func TestLivenessCheck(t *testing.T) {
+	for name, closeDB := range map[string]bool{"Database up": false, "Database down": true} {
+		t.Run(name, func(t *testing.T) {
+			_, db := newFakeDB(t)
+			if closeDB {
+				require.NoError(t, db.Close())
+			}
+			h := &Handler{cfg: &config.Config{}, db: db}
+
+			code, body := probe(t, "/api/live", h.LivenessCheck)
+			assert.Equal(t, fiber.StatusOK, code)
+			assert.Equal(t, "ok", body["status"])
+		})
+	}
+}
+
+// TestReadinessCheck tests reporting database connectivity and generator setup
+func TestReadinessCheck(t *testing.T) {
+	configured := &config.Config{Generator: config.GeneratorOpenAI, OpenAIAPIKey: "sk-test"}
+
+	t.Run("Ready", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := &Handler{cfg: configured, db: db}
+
+		code, body := probe(t, "/api/ready", h.ReadinessCheck)
+		assert.Equal(t, fiber.StatusOK, code)
+		assert.Equal(t, "ok", body["status"])
+		assert.Equal(t, "up", body["database"])
+		assert.Equal(t, "configured", body["generator"])
+	})
+
+	t.Run("Database down", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		require.NoError(t, db.Close())
+		h := &Handler{cfg: configured, db: db}
+
+		code, body := probe(t, "/api/ready", h.ReadinessCheck)
+		assert.Equal(t, fiber.StatusServiceUnavailable, code)
+		assert.Equal(t, "degraded", body["status"])
+		assert.Equal(t, "down", body["database"])
+	})
+
+	t.Run("Generator missing", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := &Handler{cfg: &config.Config{Generator: config.GeneratorAnthropic}, db: db}
+
+		code, body := probe(t, "/api/ready", h.ReadinessCheck)
+		assert.Equal(t, fiber.StatusServiceUnavailable, code)
+		assert.Equal(t, "up", body["database"])
+		assert.Equal(t, "missing", body["generator"])
+	})
+}
+
+// TestGeneratorConfigured tests which generator settings count as ready
+func TestGeneratorConfigured(t *testing.T) {
+	assert.True(t, generatorConfigured(&config.Config{Generator: config.GeneratorTemplate}))
+	assert.True(t, generatorConfigured(&config.Config{Generator: config.GeneratorOpenAI, OpenAIMode: config.OpenAIModeMock}))
+	assert.True(t, generatorConfigured(&config.Config{Generator: config.GeneratorOllama, OllamaHost: "http://localhost:11434"}))
+	assert.False(t, generatorConfigured(&config.Config{Generator: config.GeneratorOpenAI, OpenAIMode: config.OpenAIModeLive}))
+	assert.False(t, generatorConfigured(&config.Config{Generator: config.GeneratorAnthropic}))
+}
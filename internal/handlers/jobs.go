@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetJob handles GET /api/jobs/:id
+// Clients poll it for the progress of background work.
+func (h *Handler) GetJob(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := parseRequestID(id); err != nil {
+		return invalidID(c, err)
+	}
+
+	var job models.Job
+	var finishedAt sql.NullTime
+	var hasResult bool
+
+	err := h.db.QueryRowContext(
+		c.UserContext(),
+		`SELECT id, kind, status, total, completed, failed, result_request_ids, error, created_at, updated_at, finished_at, result IS NOT NULL
+		 FROM jobs
+		 WHERE id = $1`,
+		id,
+	).Scan(
+		&job.ID,
+		&job.Kind,
+		&job.Status,
+		&job.Total,
+		&job.Completed,
+		&job.Failed,
+		pq.Array(&job.ResultRequestIDs),
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&finishedAt,
+		&hasResult,
+	)
+
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Job not found",
+			Message: fmt.Sprintf("No job found with ID %s", id),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if job.ResultRequestIDs == nil {
+		job.ResultRequestIDs = []int64{}
+	}
+	if hasResult {
+		job.ResultURL = fmt.Sprintf("/api/jobs/%d/result", job.ID)
+	}
+
+	return c.JSON(job)
+}
+
+// GetJobResult handles GET /api/jobs/:id/result
+// Downloads the file a job produced, such as a bundle archive; 409 while
+// the job is still running.
+func (h *Handler) GetJobResult(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := parseRequestID(id); err != nil {
+		return invalidID(c, err)
+	}
+
+	var status string
+	var result []byte
+
+	err := h.db.QueryRowContext(
+		c.UserContext(),
+		`SELECT status, result FROM jobs WHERE id = $1`,
+		id,
+	).Scan(&status, &result)
+
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Job not found",
+			Message: fmt.Sprintf("No job found with ID %s", id),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	if status == models.StatusPending || status == models.StatusProcessing {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Error:   "Job not finished",
+			Message: fmt.Sprintf("Job %s is %s", id, status),
+		})
+	}
+
+	if result == nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "No result",
+			Message: fmt.Sprintf("Job %s has no result to download", id),
+		})
+	}
+
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", "attachment; filename="+bundleFileName)
+
+	return c.Send(result)
+}
+
+// createJob inserts a job that is already running and returns its id
+func (h *Handler) createJob(ctx context.Context, kind string, total int) (int64, error) {
+	var jobID int64
+	err := h.db.QueryRowContext(
+		ctx,
+		`INSERT INTO jobs (kind, status, total)
+		 VALUES ($1, $2, $3)
+		 RETURNING id`,
+		kind,
+		models.StatusProcessing,
+		total,
+	).Scan(&jobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create job: %w", err)
+	}
+	return jobID, nil
+}
+
+// recordJobStep counts one finished step and remembers the request it created.
+// requestID is 0 when the step failed before creating a request.
+func (h *Handler) recordJobStep(jobID, requestID int64, succeeded bool) {
+	counter := "failed"
+	if succeeded {
+		counter = "completed"
+	}
+
+	_, err := h.db.Exec(
+		fmt.Sprintf(`UPDATE jobs
+		 SET %[1]s = %[1]s + 1,
+		     result_request_ids = CASE WHEN $2 > 0 THEN array_append(result_request_ids, $2::integer) ELSE result_request_ids END
+		 WHERE id = $1`, counter),
+		jobID,
+		requestID,
+	)
+	if err != nil {
+		log.Printf("Failed to record progress of job %d: %v", jobID, err)
+	}
+}
+
+// storeJobResult saves the file a job produced
+func (h *Handler) storeJobResult(jobID int64, result []byte) error {
+	_, err := h.db.Exec(`UPDATE jobs SET result = $1 WHERE id = $2`, result, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to store job result: %w", err)
+	}
+	return nil
+}
+
+// finishJob marks a job as done; a non-empty errMsg marks it failed
+func (h *Handler) finishJob(jobID int64, errMsg string) {
+	status := models.StatusCompleted
+	if errMsg != "" {
+		status = models.StatusFailed
+	}
+
+	_, err := h.db.Exec(
+		`UPDATE jobs SET status = $1, error = $2, finished_at = NOW() WHERE id = $3`,
+		status,
+		errMsg,
+		jobID,
+	)
+	if err != nil {
+		log.Printf("Failed to finish job %d: %v", jobID, err)
+	}
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listResponse is the body of GET /api/requests
+type listResponse struct {
+	Requests   []models.GenerationRequest `json:"requests"`
+	Count      int                        `json:"count"`
+	NextCursor *string                    `json:"next_cursor"`
+}
+
+// listFilter finds the status and search conditions of a list query
+var listFilter = regexp.MustCompile(`(status = |scenario ILIKE )\$(\d+)`)
+
+// newListTestApp serves GET /api/requests from stored, a fake table that
+// honours the status and search filters and sorting by row count
+func newListTestApp(t *testing.T, stored [][]driver.Value) (*fiber.App, *fakeDB) {
+	fake, db := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		rows := [][]driver.Value{}
+	row:
+		for _, row := range stored {
+			for _, match := range listFilter.FindAllStringSubmatch(query, -1) {
+				n, _ := strconv.Atoi(match[2])
+				arg := args[n-1].(string)
+				if match[1] == "status = " && row[3] != arg {
+					continue row
+				}
+				// Only plain "%term%" patterns are needed here
+				term := strings.Trim(arg, "%")
+				if match[1] == "scenario ILIKE " && !strings.Contains(strings.ToLower(row[1].(string)), strings.ToLower(term)) {
+					continue row
+				}
+			}
+			rows = append(rows, row)
+		}
+		if strings.Contains(query, "ORDER BY row_count ASC") {
+			sort.SliceStable(rows, func(i, j int) bool { return rows[i][2].(int64) < rows[j][2].(int64) })
+		}
+		return requestColumns, rows, nil
+	}
+
+	app := fiber.New()
+	app.Get("/api/requests", (&Handler{db: db}).ListGenerationRequests)
+	return app, fake
+}
+
+// list sends GET /api/requests?query and decodes a successful response
+func list(t *testing.T, app *fiber.App, query string) (int, listResponse) {
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/requests?"+query, nil))
+	require.NoError(t, err)
+
+	var body listResponse
+	if resp.StatusCode == fiber.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	}
+	return resp.StatusCode, body
+}
+
+// TestListGenerationRequests_Status tests filtering the request list by status
+func TestListGenerationRequests_Status(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	app, fake := newListTestApp(t, [][]driver.Value{
+		requestRow(3, "orders", 10, models.StatusFailed, created),
+		requestRow(2, "users", 10, models.StatusCompleted, created),
+		requestRow(1, "products", 10, models.StatusFailed, created),
+	})
+
+	status, body := list(t, app, "status=failed&limit=2")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, body.Requests, 2)
+	for _, request := range body.Requests {
+		assert.Equal(t, models.StatusFailed, request.Status)
+	}
+
+	queries := fake.executed("FROM generation_requests")
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0].query, "WHERE status = $1 ORDER BY")
+	assert.Equal(t, []driver.Value{"failed", int64(2), int64(0)}, queries[0].args)
+
+	// The cursor conditions follow the status placeholder
+	require.NotNil(t, body.NextCursor)
+	status, _ = list(t, app, "status=failed&limit=2&after="+*body.NextCursor)
+	require.Equal(t, fiber.StatusOK, status)
+	queries = fake.executed("FROM generation_requests")
+	assert.Contains(t, queries[1].query, "WHERE status = $1 AND (created_at, id) < ($2::timestamp, $3) ORDER BY")
+	assert.Equal(t, "failed", queries[1].args[0])
+
+	status, _ = list(t, app, "status=done")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Len(t, fake.executed("FROM generation_requests"), 2, "Invalid statuses never reach the database")
+}
+
+// TestListGenerationRequests_Columns tests that every selected column is
+// scanned into the listed requests
+func TestListGenerationRequests_Columns(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	row := requestRow(1, "users", 10, models.StatusCompleted, created)
+	row[7], row[8], row[10] = true, true, int64(9)
+	app, _ := newListTestApp(t, [][]driver.Value{row})
+
+	status, body := list(t, app, "")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, body.Requests, 1)
+
+	request := body.Requests[0]
+	assert.True(t, request.Pinned)
+	assert.True(t, request.Degraded)
+	assert.Equal(t, "gpt-3.5-turbo", request.Model)
+	require.NotNil(t, request.ActualRowCount)
+	assert.Equal(t, 9, *request.ActualRowCount)
+}
+
+// TestListGenerationRequests_Search tests searching the request list by scenario
+func TestListGenerationRequests_Search(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	app, fake := newListTestApp(t, [][]driver.Value{
+		requestRow(4, "Users of a bookshop", 10, models.StatusCompleted, created),
+		requestRow(3, "Orders placed by USERS", 10, models.StatusFailed, created),
+		requestRow(2, "Products and prices", 10, models.StatusCompleted, created),
+		requestRow(1, "Admin user accounts", 10, models.StatusCompleted, created),
+	})
+
+	status, body := list(t, app, "search=user")
+	require.Equal(t, fiber.StatusOK, status)
+	ids := []int64{}
+	for _, request := range body.Requests {
+		ids = append(ids, request.ID)
+	}
+	assert.Equal(t, []int64{4, 3, 1}, ids)
+
+	queries := fake.executed("FROM generation_requests")
+	assert.Contains(t, queries[0].query, "WHERE scenario ILIKE $1 ORDER BY")
+	assert.Equal(t, "%user%", queries[0].args[0])
+
+	status, body = list(t, app, "search=user&status=completed")
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Len(t, body.Requests, 2)
+	assert.Contains(t, fake.executed("FROM generation_requests")[1].query, "WHERE status = $1 AND scenario ILIKE $2 ORDER BY")
+
+	// Wildcards in the term match themselves
+	list(t, app, "search="+url.QueryEscape(`50%_off\`))
+	assert.Equal(t, `%50\%\_off\\%`, fake.executed("FROM generation_requests")[2].args[0])
+}
+
+// TestEscapeLike tests escaping LIKE wildcards
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "user", escapeLike("user"))
+	assert.Equal(t, `100\%`, escapeLike("100%"))
+	assert.Equal(t, `first\_name`, escapeLike("first_name"))
+	assert.Equal(t, `C:\\temp`, escapeLike(`C:\temp`))
+}
+
+// TestListGenerationRequests_Sort tests sorting the request list
+func TestListGenerationRequests_Sort(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	app, fake := newListTestApp(t, [][]driver.Value{
+		requestRow(3, "orders", 50, models.StatusCompleted, created),
+		requestRow(2, "users", 5, models.StatusCompleted, created),
+		requestRow(1, "products", 20, models.StatusCompleted, created),
+	})
+
+	status, body := list(t, app, "sort=row_count&order=asc&limit=3")
+	require.Equal(t, fiber.StatusOK, status)
+	counts := []int{}
+	for _, request := range body.Requests {
+		counts = append(counts, request.RowCount)
+	}
+	assert.Equal(t, []int{5, 20, 50}, counts)
+	assert.Nil(t, body.NextCursor, "Cursors only describe the default order")
+
+	queries := fake.executed("FROM generation_requests")
+	assert.Contains(t, queries[0].query, "ORDER BY row_count ASC, id ASC LIMIT $1")
+
+	list(t, app, "")
+	assert.Contains(t, fake.executed("FROM generation_requests")[1].query, "ORDER BY created_at DESC, id DESC", "The default order is unchanged")
+
+	for _, query := range []string{"sort=scenario", "sort=id%3BDROP%20TABLE%20generation_requests", "order=up", "sort=row_count&after=MTow"} {
+		status, _ := list(t, app, query)
+		assert.Equal(t, fiber.StatusBadRequest, status, query)
+	}
+	assert.Len(t, fake.executed("FROM generation_requests"), 2, "Rejected sorts never reach the database")
+}
+
+// TestRequestOrderBy tests building ORDER BY clauses from the allowlist
+func TestRequestOrderBy(t *testing.T) {
+	orderBy, err := requestOrderBy("updated_at", "asc")
+	require.NoError(t, err)
+	assert.Equal(t, "updated_at ASC, id ASC", orderBy)
+
+	orderBy, err = requestOrderBy("status", "desc")
+	require.NoError(t, err)
+	assert.Equal(t, "CASE status WHEN 'pending' THEN 0 WHEN 'processing' THEN 1 WHEN 'completed' THEN 2 ELSE 3 END DESC, id DESC", orderBy)
+
+	_, err = requestOrderBy("scenario", "asc")
+	assert.EqualError(t, err, "sort must be one of: created_at, row_count, status, updated_at")
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateMockData_Locale tests that a locale is validated, stored and
+// passed to the generator
+func TestGenerateMockData_Locale(t *testing.T) {
+	generator := &fakeGenerator{}
+	h, fake := newQueueTestHandler(t, generator)
+
+	app := fiber.New()
+	app.Post("/api/generate", h.GenerateMockData)
+
+	generate := func(locale string) (int, []byte) {
+		body := `{"scenario": "Customers of a bakery", "row_count": 3, "locale": "` + locale + `"}`
+		req := httptest.NewRequest("POST", "/api/generate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, raw
+	}
+
+	status, raw := generate("French")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Contains(t, string(raw), models.ErrInvalidLocale.Error())
+
+	status, raw = generate("fr-FR")
+	require.Equal(t, fiber.StatusAccepted, status)
+
+	var body models.GenerateResponse
+	require.NoError(t, json.Unmarshal(raw, &body))
+	assert.Equal(t, "fr-FR", body.Locale)
+
+	inserts := fake.executed("INSERT INTO generation_requests")
+	require.Len(t, inserts, 1)
+	assert.Equal(t, "fr-FR", inserts[0].args[4])
+
+	require.NoError(t, h.Shutdown(context.Background()))
+	require.Len(t, generator.calls, 1)
+	assert.Equal(t, "fr-FR", generator.calls[0].Locale)
+
+	_, err := h.createGenerationRequest("Customers of a bakery", 3, "", "", nil)
+	require.NoError(t, err)
+	inserts = fake.executed("INSERT INTO generation_requests")
+	assert.Nil(t, inserts[len(inserts)-1].args[4], "No locale is stored as NULL")
+}
+
+// TestGetGenerationRequest_Locale tests that the stored locale is returned
+func TestGetGenerationRequest_Locale(t *testing.T) {
+	fake, db := newFakeDB(t)
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		row := requestRow(7, "users", 10, models.StatusCompleted, created)
+		row[14] = "ja-JP"
+		return requestColumns, [][]driver.Value{row}, nil
+	}
+
+	app := fiber.New()
+	app.Get("/api/requests/:id", (&Handler{db: db}).GetGenerationRequest)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/requests/7", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var request models.GenerationRequest
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&request))
+	require.NotNil(t, request.Locale)
+	assert.Equal(t, "ja-JP", *request.Locale)
+}
+
+// TestGenerateMockData_EdgeCaseSeed tests that edge cases follow the request
+// seed unless they have their own
+func TestGenerateMockData_EdgeCaseSeed(t *testing.T) {
+	generate := func(body string) services.GenerateOptions {
+		generator := &fakeGenerator{}
+		h, _ := newQueueTestHandler(t, generator)
+
+		app := fiber.New()
+		app.Post("/api/generate", h.GenerateMockData)
+
+		req := httptest.NewRequest("POST", "/api/generate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+		require.NoError(t, h.Shutdown(context.Background()))
+		require.Len(t, generator.calls, 1)
+		return generator.calls[0]
+	}
+
+	edgeCases := `"edge_cases": {"name": {"probability": 0.5}}`
+	assert.Equal(t, int64(42), generate(`{"scenario": "Customers of a bakery", "row_count": 3, "seed": 42, `+edgeCases+`}`).EdgeCaseSeed)
+	assert.Equal(t, int64(7), generate(`{"scenario": "Customers of a bakery", "row_count": 3, "seed": 42, "edge_case_seed": 7, `+edgeCases+`}`).EdgeCaseSeed, "An explicit edge case seed wins")
+}
+
+// TestGenerateMockData_SeededRowCountRange tests that a seeded request
+// resolves its row count range the same way every time
+func TestGenerateMockData_SeededRowCountRange(t *testing.T) {
+	rowCount := func(body string) int {
+		h, _ := newQueueTestHandler(t, &fakeGenerator{})
+
+		app := fiber.New()
+		app.Post("/api/generate", h.GenerateMockData)
+
+		req := httptest.NewRequest("POST", "/api/generate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+		var response models.GenerateResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.NoError(t, h.Shutdown(context.Background()))
+		return response.RowCount
+	}
+
+	body := `{"scenario": "Customers of a bakery", "row_count_min": 1, "row_count_max": 1000, "seed": 42}`
+	first := rowCount(body)
+	assert.GreaterOrEqual(t, first, 1)
+	assert.LessOrEqual(t, first, 1000)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, first, rowCount(body), "The same seed picks the same row count")
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+ExportMerged handles GET /api/export/merged?ids=1,2,3&format=csv
+
+Stacks the rows of several datasets with the same fields into a single
+export file, in the order the ids were given. Datasets with different
+fields are rejected with 422 and a list of the differences.
+
+Query parameters:
+- ids: comma-separated request ids (required, max 50)
+- include_source: add a source_request_id column (default: false)
+- format, table and the value options of the single dataset export
+*/
+func (h *Handler) ExportMerged(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	tableName := c.Query("table", services.DefaultTableName)
+
+	ids, err := parseBundleIDs(c.Query("ids"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid ids",
+			Message: err.Error(),
+		})
+	}
+
+	opts, err := exportOptionsFromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: err.Error(),
+		})
+	}
+
+	sources := make([]services.MergeSource, 0, len(ids))
+	for _, id := range ids {
+		dataset, err := h.loadDataset(c.UserContext(), id)
+		if err == sql.ErrNoRows {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Error:   "Dataset not found",
+				Message: fmt.Sprintf("No dataset found for request ID %d", id),
+			})
+		}
+
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+
+		sources = append(sources, services.MergeSource{
+			RequestID:  id,
+			Data:       dataset.Data,
+			FieldNames: dataset.FieldNames,
+		})
+	}
+
+	data, fieldNames, err := services.MergeDatasets(sources, c.QueryBool("include_source"))
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	result, err := h.exportService.WithOptions(opts).Export(format, data, fieldNames, tableName)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	c.Set("Content-Type", result.ContentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=mockdata-merged.%s", result.Extension))
+
+	return c.Send(result.Data)
+}
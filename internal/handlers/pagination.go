@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// Page size bounds for list endpoints
+const (
+	defaultPageSize = 100
+	maxPageSize     = 100
+)
+
+// Default order of the request list, the only one cursors support
+const (
+	defaultRequestSort  = "created_at"
+	defaultRequestOrder = "desc"
+)
+
+// requestSorts maps the sort options of the request list to the SQL they
+// order by; only these ever reach the query
+var requestSorts = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"row_count":  "row_count",
+
+	// Lifecycle order rather than alphabetical
+	"status": fmt.Sprintf("CASE status WHEN '%s' THEN 0 WHEN '%s' THEN 1 WHEN '%s' THEN 2 ELSE 3 END",
+		models.StatusPending, models.StatusProcessing, models.StatusCompleted),
+}
+
+// requestOrderBy returns the ORDER BY clause for a sort option and order
+// (asc or desc), with ids breaking ties in the same direction
+func requestOrderBy(sortBy, order string) (string, error) {
+	expr, ok := requestSorts[sortBy]
+	if !ok {
+		names := make([]string, 0, len(requestSorts))
+		for name := range requestSorts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("sort must be one of: %s", strings.Join(names, ", "))
+	}
+
+	direction := strings.ToUpper(order)
+	if direction != "ASC" && direction != "DESC" {
+		return "", fmt.Errorf("order must be asc or desc")
+	}
+
+	return fmt.Sprintf(`%s %s, id %s`, expr, direction, direction), nil
+}
+
+/*
+pageCursor marks the last item of a page in (created_at DESC, id DESC)
+order. Unlike an offset it stays stable when new requests are created while
+a client is paging.
+
+It is sent to clients as an opaque base64 token.
+*/
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// encodeCursor returns the opaque token for a cursor
+func encodeCursor(cursor pageCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixNano(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a token produced by encodeCursor
+func decodeCursor(token string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	return pageCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// nextCursor returns the token for the page after requests, or nil when
+// the page was not full and there is nothing more to fetch
+func nextCursor(requests []models.GenerationRequest, limit int) *string {
+	if len(requests) == 0 || len(requests) < limit {
+		return nil
+	}
+
+	last := requests[len(requests)-1]
+	token := encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	return &token
+}
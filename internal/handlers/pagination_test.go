@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCursorRoundTrip tests encoding and decoding of page cursors
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := pageCursor{CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC), ID: 42}
+
+	decoded, err := decodeCursor(encodeCursor(cursor))
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+
+	for _, token := range []string{"", "not base64!", "MTIz", "YTpi"} {
+		_, err := decodeCursor(token)
+		assert.Error(t, err, token)
+	}
+}
+
+// TestNextCursor tests cursor continuation across pages
+func TestNextCursor(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	// Five requests, newest first, two sharing a timestamp
+	all := []models.GenerationRequest{
+		{ID: 5, CreatedAt: base.Add(3 * time.Minute)},
+		{ID: 4, CreatedAt: base.Add(2 * time.Minute)},
+		{ID: 3, CreatedAt: base.Add(1 * time.Minute)},
+		{ID: 2, CreatedAt: base.Add(1 * time.Minute)},
+		{ID: 1, CreatedAt: base},
+	}
+
+	// Mirrors WHERE (created_at, id) < (cursor) ORDER BY created_at DESC, id DESC LIMIT n
+	page := func(after *pageCursor, limit int) []models.GenerationRequest {
+		result := []models.GenerationRequest{}
+		for _, req := range all {
+			if after != nil && !(req.CreatedAt.Before(after.CreatedAt) || (req.CreatedAt.Equal(after.CreatedAt) && req.ID < after.ID)) {
+				continue
+			}
+			if len(result) < limit {
+				result = append(result, req)
+			}
+		}
+		return result
+	}
+
+	seen := []int64{}
+	var after *pageCursor
+	for i := 0; i < 10; i++ {
+		requests := page(after, 2)
+		for _, req := range requests {
+			seen = append(seen, req.ID)
+		}
+
+		token := nextCursor(requests, 2)
+		if token == nil {
+			break
+		}
+		cursor, err := decodeCursor(*token)
+		require.NoError(t, err)
+		after = &cursor
+	}
+
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, seen, "Every request appears exactly once")
+	assert.Nil(t, nextCursor(nil, 2), "Empty page has no next cursor")
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// PinRequest handles POST /api/requests/:id/pin
+// Pinned requests are skipped by retention cleanup and bulk deletes.
+func (h *Handler) PinRequest(c *fiber.Ctx) error {
+	return h.setPinned(c, true)
+}
+
+// UnpinRequest handles POST /api/requests/:id/unpin
+func (h *Handler) UnpinRequest(c *fiber.Ctx) error {
+	return h.setPinned(c, false)
+}
+
+func (h *Handler) setPinned(c *fiber.Ctx, pinned bool) error {
+	id := c.Params("id")
+	if _, err := parseRequestID(id); err != nil {
+		return invalidID(c, err)
+	}
+
+	var request models.GenerationRequest
+	err := h.db.QueryRowContext(
+		c.UserContext(),
+		`UPDATE generation_requests SET pinned = $1
+		 WHERE id = $2
+		 RETURNING id, scenario, row_count, status, generated_at, created_at, updated_at, pinned, degraded, model, actual_row_count, adjusted_row_count, error_message, error_code, locale`,
+		pinned,
+		id,
+	).Scan(
+		&request.ID,
+		&request.Scenario,
+		&request.RowCount,
+		&request.Status,
+		&request.GeneratedAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+		&request.Pinned,
+		&request.Degraded,
+		&request.Model,
+		&request.ActualRowCount,
+		&request.AdjustedRowCount,
+		&request.ErrorMessage,
+		&request.ErrorCode,
+		&request.Locale,
+	)
+
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Request not found",
+			Message: fmt.Sprintf("No generation request found with ID %s", id),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(request)
+}
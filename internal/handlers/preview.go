@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// Preview sizes for GET /api/data/:id/preview
+const (
+	defaultPreviewRows = 10
+	maxPreviewRows     = 50
+)
+
+/*
+PreviewMockData handles GET /api/data/:id/preview
+
+Returns the first rows of a dataset for a quick look, in the same shape as
+GET /api/data/:id (filters included). row_count counts every matching
+row, i.e. the whole dataset without filters, and field_types always
+describe the whole dataset.
+
+Query parameters:
+- rows: number of rows (default 10, larger values are capped at 50)
+*/
+func (h *Handler) PreviewMockData(c *fiber.Ctx) error {
+	rows := c.QueryInt("rows", defaultPreviewRows)
+	if rows < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("rows must be between 1 and %d", maxPreviewRows),
+		})
+	}
+
+	return h.sendDataset(c, min(rows, maxPreviewRows))
+}
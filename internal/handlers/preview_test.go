@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreviewMockData tests returning the first rows of a dataset
+func TestPreviewMockData(t *testing.T) {
+	data := make([]map[string]interface{}, 80)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": fmt.Sprint(i + 1), "city": "Kigali"}
+	}
+
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, data, []string{"id", "city"})
+
+	app := fiber.New()
+	app.Get("/api/data/:id/preview", (&Handler{cfg: &config.Config{}, db: db}).PreviewMockData)
+
+	preview := func(query string) (int, models.DataResponse) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/data/1/preview"+query, nil))
+		require.NoError(t, err)
+
+		var body models.DataResponse
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		}
+		return resp.StatusCode, body
+	}
+
+	status, body := preview("?rows=5")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Len(t, body.Data, 5)
+	assert.Equal(t, "1", body.Data[0]["id"])
+	assert.Equal(t, "5", body.Data[4]["id"])
+	assert.Equal(t, 80, body.RowCount, "row_count is the size of the whole dataset")
+
+	_, body = preview("?rows=5&filter=id=7")
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, 1, body.RowCount, "With a filter, row_count counts the matching rows")
+	assert.Equal(t, []string{"id", "city"}, body.FieldNames)
+	assert.Equal(t, int64(1), body.RequestID)
+
+	_, body = preview("")
+	assert.Len(t, body.Data, defaultPreviewRows)
+
+	_, body = preview("?rows=500")
+	assert.Len(t, body.Data, maxPreviewRows)
+
+	data[1]["city"] = "Musanze"
+	fake.serveDataset(t, data, []string{"id", "city"})
+	_, body = preview("?rows=5&filter=city=Kigali")
+	assert.Len(t, body.Data, 5)
+	assert.Equal(t, 79, body.RowCount)
+
+	status, _ = preview("?rows=0")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
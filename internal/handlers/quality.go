@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+GetQuality handles GET /api/data/:id/quality
+
+Reports per-field diversity metrics of a dataset: distinct values, the
+distinct-value ratio, whether the field looks categorical and whether it
+falls below DIVERSITY_THRESHOLD.
+*/
+func (h *Handler) GetQuality(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	dataset, err := h.loadDataset(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	diversity := services.MeasureDiversity(dataset.Data, dataset.FieldNames, h.cfg.DiversityThreshold)
+	low := services.LowDiversityFields(diversity)
+	if low == nil {
+		low = []string{}
+	}
+
+	return c.JSON(fiber.Map{
+		"request_id":          dataset.RequestID,
+		"row_count":           len(dataset.Data),
+		"diversity_threshold": h.cfg.DiversityThreshold,
+		"diversity":           diversity,
+		"low_diversity":       low,
+	})
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+var (
+	// errQueueFull rejects generate requests while every queue slot is taken
+	errQueueFull = errors.New("too many generations in progress, try again later")
+
+	// errQueueClosed rejects generate requests once shutdown has started
+	errQueueClosed = errors.New("the server is shutting down")
+)
+
+// generationJob is a stored generate request waiting for a worker
+type generationJob struct {
+	requestID     int64
+	scenario      string
+	rowCount      int
+	opts          services.GenerateOptions
+	encryptFields []string
+	useCache      bool
+}
+
+/*
+generationQueue runs generate requests in the background with a fixed
+number of workers, so POST /api/generate can answer before the model does.
+
+Jobs wait in a bounded buffer; enqueue fails instead of blocking when it is
+full. shutdown stops accepting jobs and waits for the queued and running
+ones to finish. When its context ends first, the workers' context is
+cancelled so the remaining jobs fail fast instead of staying pending.
+*/
+type generationQueue struct {
+	mu     sync.RWMutex
+	closed bool
+	jobs   chan generationJob
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// newGenerationQueue starts workers that pass each job to run
+func newGenerationQueue(workers, size int, run func(ctx context.Context, job generationJob)) *generationQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &generationQueue{jobs: make(chan generationJob, size), cancel: cancel}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for job := range q.jobs {
+				run(ctx, job)
+			}
+		}()
+	}
+
+	return q
+}
+
+// enqueue hands job to the workers without waiting for a free slot
+func (q *generationQueue) enqueue(job generationJob) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return errQueueClosed
+	}
+
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// shutdown stops accepting jobs and waits for the workers to drain the queue
+func (q *generationQueue) shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		log.Printf("⚠️ Shutdown deadline reached, cancelling %d queued generations", len(q.jobs))
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+/*
+backgroundJobs tracks jobs that run outside the generation queue, such as
+bulk regenerates and bundle exports, so shutdown waits for them as well.
+
+Like the queue, it refuses new jobs once shutdown has started and cancels
+the jobs' context when the shutdown deadline passes.
+*/
+type backgroundJobs struct {
+	mu     sync.Mutex
+	closed bool
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newBackgroundJobs() *backgroundJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backgroundJobs{ctx: ctx, cancel: cancel}
+}
+
+// start runs job in its own goroutine unless shutdown has started
+func (b *backgroundJobs) start(job func(ctx context.Context)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errQueueClosed
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		job(b.ctx)
+	}()
+	return nil
+}
+
+// shutdown stops accepting jobs and waits for the running ones to finish
+func (b *backgroundJobs) shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		log.Println("⚠️ Shutdown deadline reached, cancelling background jobs")
+		b.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// runGeneration processes one queued request, which records its own outcome
+func (h *Handler) runGeneration(ctx context.Context, job generationJob) {
+	// A panicking generation must not take the worker down with it
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Generation request %d panicked: %v", job.requestID, r)
+			h.markFailed(job.requestID, fmt.Errorf("internal error: %v", r))
+		}
+	}()
+
+	// Detached from the HTTP request, but still bounded per generation
+	if h.cfg.GenerateTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.GenerateTimeout)
+		defer cancel()
+	}
+
+	if _, err := h.generateDataset(ctx, job.requestID, job.scenario, job.rowCount, job.opts, job.encryptFields, job.useCache); err != nil {
+		log.Printf("Generation request %d failed: %v", job.requestID, err)
+	}
+}
+
+// Shutdown waits for queued generations and background jobs to finish; see
+// generationQueue.shutdown
+func (h *Handler) Shutdown(ctx context.Context) error {
+	err := h.queue.shutdown(ctx)
+	if jobsErr := h.jobs.shutdown(ctx); err == nil {
+		err = jobsErr
+	}
+	return err
+}
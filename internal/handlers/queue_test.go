@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingGenerator signals started and waits for release before generating
+type blockingGenerator struct {
+	fakeGenerator
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingGenerator) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts services.GenerateOptions) ([]map[string]interface{}, []string, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.fakeGenerator.GenerateMockData(ctx, scenario, rowCount, opts)
+}
+
+// newQueueTestHandler returns a handler with one generation worker whose
+// database hands out request id 1
+func newQueueTestHandler(t *testing.T, generator services.Generator) (*Handler, *fakeDB) {
+	fake, db := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	}
+
+	cfg := &config.Config{MaxScenarioLength: 2000, MaxRowCount: 1000, GenerateWorkers: 1, GenerateQueueSize: 1}
+	return NewHandler(cfg, db, generator, nil, nil), fake
+}
+
+// postGenerate sends a generate request for 3 users
+func postGenerate(t *testing.T, h *Handler) (*http.Response, models.GenerateResponse) {
+	app := fiber.New()
+	app.Post("/api/generate", h.GenerateMockData)
+
+	req := httptest.NewRequest("POST", "/api/generate", strings.NewReader(`{"scenario": "Users of a bookshop with names and emails", "row_count": 3}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var body models.GenerateResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, body
+}
+
+// statuses returns the request statuses written to fake, in order
+func statuses(fake *fakeDB) []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	var written []string
+	for _, statement := range fake.statements {
+		for _, status := range []string{models.StatusPending, models.StatusProcessing, models.StatusCompleted, models.StatusFailed} {
+			if strings.Contains(statement.query, "generation_requests") && strings.Contains(statement.query, "'"+status+"'") {
+				written = append(written, status)
+			}
+		}
+	}
+	return written
+}
+
+// TestGenerateMockData_Lifecycle tests the pending → processing → completed lifecycle
+func TestGenerateMockData_Lifecycle(t *testing.T) {
+	generator := &blockingGenerator{started: make(chan struct{}), release: make(chan struct{})}
+	h, fake := newQueueTestHandler(t, generator)
+
+	resp, body := postGenerate(t, h)
+	assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, int64(1), body.ID)
+	assert.Equal(t, models.StatusPending, body.Status)
+	assert.Equal(t, 3, body.RowCount)
+	assert.Equal(t, "Poll /api/requests/1 for completion", body.Message)
+
+	<-generator.started
+	assert.Equal(t, []string{models.StatusPending, models.StatusProcessing}, statuses(fake), "The response does not wait for the generator")
+
+	close(generator.release)
+	require.NoError(t, h.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{models.StatusPending, models.StatusProcessing, models.StatusCompleted}, statuses(fake))
+	assert.Len(t, fake.executed("INSERT INTO mock_datasets"), 1)
+	assert.Len(t, generator.calls, 1)
+}
+
+// TestGenerateMockData_ShuttingDown tests rejecting requests once shutdown started
+func TestGenerateMockData_ShuttingDown(t *testing.T) {
+	h, fake := newQueueTestHandler(t, &fakeGenerator{})
+	require.NoError(t, h.Shutdown(context.Background()))
+
+	resp, _ := postGenerate(t, h)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	failed := fake.executed("status = 'failed'")
+	require.Len(t, failed, 1, "The stored request does not stay pending")
+	assert.Equal(t, errQueueClosed.Error(), failed[0].args[0])
+}
+
+// TestGenerationQueue_Full tests that enqueue fails instead of blocking
+func TestGenerationQueue_Full(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	q := newGenerationQueue(1, 1, func(ctx context.Context, job generationJob) {
+		started <- struct{}{}
+		<-release
+	})
+
+	require.NoError(t, q.enqueue(generationJob{requestID: 1}))
+	<-started
+	require.NoError(t, q.enqueue(generationJob{requestID: 2}), "Waits in the buffer")
+	assert.ErrorIs(t, q.enqueue(generationJob{requestID: 3}), errQueueFull)
+
+	close(release)
+	go func() { <-started }()
+	require.NoError(t, q.shutdown(context.Background()))
+	assert.ErrorIs(t, q.enqueue(generationJob{requestID: 4}), errQueueClosed)
+}
+
+// TestGenerationQueue_ShutdownDeadline tests cancelling jobs that outlive the deadline
+func TestGenerationQueue_ShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	q := newGenerationQueue(1, 1, func(ctx context.Context, job generationJob) {
+		close(started)
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+	})
+
+	require.NoError(t, q.enqueue(generationJob{requestID: 1}))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.shutdown(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, <-cancelled, context.Canceled, "The running job sees the cancellation")
+}
+
+// TestBackgroundJobs_Shutdown tests waiting for, and then refusing, jobs
+// outside the queue
+func TestBackgroundJobs_Shutdown(t *testing.T) {
+	jobs := newBackgroundJobs()
+	release := make(chan struct{})
+	finished := false
+	require.NoError(t, jobs.start(func(ctx context.Context) {
+		<-release
+		finished = true
+	}))
+
+	go close(release)
+	require.NoError(t, jobs.shutdown(context.Background()))
+	assert.True(t, finished, "Shutdown waits for running jobs")
+	assert.ErrorIs(t, jobs.start(func(ctx context.Context) {}), errQueueClosed)
+
+	jobs = newBackgroundJobs()
+	cancelled := make(chan error, 1)
+	require.NoError(t, jobs.start(func(ctx context.Context) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, jobs.shutdown(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+}
+
+// TestRegenerateRequests_Shutdown tests that Shutdown drains bulk regenerate jobs
+func TestRegenerateRequests_Shutdown(t *testing.T) {
+	generator := &fakeGenerator{}
+	h, fake := newQueueTestHandler(t, generator)
+	h.cfg.RegenerateConcurrency = 1
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "FROM generation_requests r") {
+			return []string{"id", "scenario", "row_count", "locale", "encrypted_fields", "schema_fields"}, [][]driver.Value{
+				{int64(3), "users", int64(2), "", []byte("{}"), []byte(`[{"name":"email","type":"string"}]`)},
+				{int64(4), "orders", int64(2), "", []byte("{}"), nil},
+			}, nil
+		}
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	}
+
+	app := fiber.New()
+	app.Post("/api/admin/regenerate", h.RegenerateRequests)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/admin/regenerate?model=gpt-4o", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Len(t, generator.calls, 2, "Every request is regenerated before Shutdown returns")
+	assert.Equal(t, []models.SchemaField{{Name: "email", Type: models.SchemaTypeString}}, generator.calls[0].Schema, "The explicit fields are reused")
+
+	finished := fake.executed("UPDATE jobs SET status")
+	require.Len(t, finished, 1)
+	assert.Equal(t, []driver.Value{models.StatusCompleted, ""}, finished[0].args[:2])
+
+	resp, err = app.Test(httptest.NewRequest("POST", "/api/admin/regenerate?model=gpt-4o", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode, "No jobs start once shutdown has begun")
+}
+
+// TestRegenerateRequests_UnknownModel tests that unknown models are rejected before a job is created
+func TestRegenerateRequests_UnknownModel(t *testing.T) {
+	h, fake := newQueueTestHandler(t, &fakeGenerator{})
+
+	app := fiber.New()
+	app.Post("/api/admin/regenerate", h.RegenerateRequests)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/admin/regenerate?model=gpt-99", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var body models.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Contains(t, body.Message, "gpt-99")
+	assert.Empty(t, fake.executed("INSERT INTO jobs"), "No job is created")
+	assert.Empty(t, fake.executed("FROM generation_requests"), "No requests are looked up")
+}
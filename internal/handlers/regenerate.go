@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+	"github.com/lib/pq"
+)
+
+/*
+RegenerateRequest handles POST /api/requests/:id/regenerate
+
+Re-rolls a stored request: a new request with the same scenario, row count,
+model, locale, explicit fields and encrypted fields is created and generated in the background,
+bypassing the generation cache. The original is kept as it is.
+
+The optional body changes the row count:
+
+	{"row_count": 50}
+
+Responds 202 with the new request id, like POST /api/generate.
+*/
+func (h *Handler) RegenerateRequest(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := parseRequestID(id); err != nil {
+		return invalidID(c, err)
+	}
+
+	var req models.RegenerateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Invalid request body",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	var source regenerateSource
+	var model string
+	var schemaJSON []byte
+	err := h.db.QueryRowContext(
+		c.UserContext(),
+		`SELECT r.id, r.scenario, r.row_count, r.model, COALESCE(r.locale, ''), COALESCE(d.encrypted_fields, '{}'), r.schema_fields
+		 FROM generation_requests r
+		 LEFT JOIN mock_datasets d ON d.request_id = r.id
+		 WHERE r.id = $1`,
+		id,
+	).Scan(&source.id, &source.scenario, &source.rowCount, &model, &source.locale, pq.Array(&source.encryptedFields), &schemaJSON)
+	if err == nil {
+		source.schema, err = decodeSchemaFields(schemaJSON)
+	}
+
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Request not found",
+			Message: fmt.Sprintf("No generation request found with ID %s", id),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	if req.RowCount != nil {
+		if *req.RowCount < models.MinRowCount || *req.RowCount > h.cfg.MaxRowCount {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Error:   "Validation failed",
+				Message: fmt.Sprintf("%s: must be between %d and %d", models.ErrInvalidRowCount.Error(), models.MinRowCount, h.cfg.MaxRowCount),
+			})
+		}
+		source.rowCount = *req.RowCount
+	}
+
+	// The policy may have changed since the original was generated
+	if err := h.policy.Check(source.scenario); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Error:   "Scenario not allowed",
+			Message: err.Error(),
+		})
+	}
+
+	if len(source.encryptedFields) > 0 && h.fpeService == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: models.ErrEncryptionNotConfigured.Error(),
+		})
+	}
+
+	// Recorded models that requests can't choose (e.g. template) mean the server default
+	opts := services.GenerateOptions{Locale: source.locale, Schema: source.schema}
+	if services.IsRequestModel(model) {
+		opts.Model = model
+	}
+
+	requestID, err := h.createGenerationRequest(source.scenario, source.rowCount, h.modelName(opts), source.locale, source.schema)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	log.Printf("Regenerating request %d as request %d (%d rows)", source.id, requestID, source.rowCount)
+
+	err = h.queue.enqueue(generationJob{
+		requestID:     requestID,
+		scenario:      source.scenario,
+		rowCount:      source.rowCount,
+		opts:          opts,
+		encryptFields: source.encryptedFields,
+	})
+	if err != nil {
+		h.markFailed(requestID, err)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(models.ErrorResponse{
+			Error:   "Generation unavailable",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.GenerateResponse{
+		ID:        requestID,
+		Status:    models.StatusPending,
+		Message:   fmt.Sprintf("Poll /api/requests/%d for completion", requestID),
+		RowCount:  source.rowCount,
+		Locale:    source.locale,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegenerateRequest tests re-rolling a stored request as a new one
+func TestRegenerateRequest(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if strings.Contains(query, "INSERT INTO generation_requests") {
+			return []string{"id"}, [][]driver.Value{{int64(8)}}, nil
+		}
+		if args[0] != "7" {
+			return nil, nil, nil
+		}
+		return []string{"id", "scenario", "row_count", "model", "locale", "encrypted_fields", "schema_fields"},
+			[][]driver.Value{{int64(7), "Users of a bookshop", int64(25), "gpt-4o", "fr-FR", []byte("{}"), []byte(`[{"name":"email","type":"string","unique":true}]`)}}, nil
+	}
+
+	cfg := &config.Config{MaxRowCount: 1000, GenerateWorkers: 1, GenerateQueueSize: 10}
+	generator := &fakeGenerator{}
+	h := NewHandler(cfg, db, generator, nil, nil)
+
+	app := fiber.New()
+	app.Post("/api/requests/:id/regenerate", h.RegenerateRequest)
+
+	regenerate := func(id, body string) (int, []byte) {
+		req := httptest.NewRequest("POST", "/api/requests/"+id+"/regenerate", strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, raw
+	}
+
+	t.Run("Same scenario", func(t *testing.T) {
+		status, raw := regenerate("7", "")
+		require.Equal(t, fiber.StatusAccepted, status)
+
+		var body models.GenerateResponse
+		require.NoError(t, json.Unmarshal(raw, &body))
+		assert.Equal(t, int64(8), body.ID, "A new request is created")
+		assert.Equal(t, models.StatusPending, body.Status)
+		assert.Equal(t, 25, body.RowCount)
+		assert.Equal(t, "fr-FR", body.Locale)
+
+		inserts := fake.executed("INSERT INTO generation_requests")
+		require.Len(t, inserts, 1)
+		assert.Equal(t, "Users of a bookshop", inserts[0].args[0])
+		assert.Equal(t, int64(25), inserts[0].args[2])
+		assert.Equal(t, "fr-FR", inserts[0].args[4], "The locale is kept")
+		assert.JSONEq(t, `[{"name":"email","type":"string","unique":true}]`, string(inserts[0].args[5].([]byte)), "So are the fields")
+		assert.Empty(t, fake.executed("UPDATE generation_requests SET pinned"), "The original is left alone")
+	})
+
+	t.Run("Row count override", func(t *testing.T) {
+		status, raw := regenerate("7", `{"row_count": 50}`)
+		require.Equal(t, fiber.StatusAccepted, status)
+
+		var body models.GenerateResponse
+		require.NoError(t, json.Unmarshal(raw, &body))
+		assert.Equal(t, 50, body.RowCount)
+
+		inserts := fake.executed("INSERT INTO generation_requests")
+		assert.Equal(t, int64(50), inserts[len(inserts)-1].args[2])
+	})
+
+	t.Run("Invalid row count", func(t *testing.T) {
+		status, _ := regenerate("7", `{"row_count": 5000}`)
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("Missing original", func(t *testing.T) {
+		status, _ := regenerate("99", "")
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Len(t, fake.executed("status = 'completed'"), 2, "Both regenerations ran")
+	require.Len(t, generator.calls, 2)
+	assert.Equal(t, "gpt-4o", generator.calls[0].Model, "The original's model is reused")
+	assert.Equal(t, "fr-FR", generator.calls[0].Locale)
+	assert.Equal(t, []models.SchemaField{{Name: "email", Type: models.SchemaTypeString, Unique: true}}, generator.calls[0].Schema, "The explicit fields are reused")
+}
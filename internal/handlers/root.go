@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Service identity reported by the root endpoint and the Fiber app name
+const (
+	ServiceName = "Mock Data Generator API"
+	Version     = "1.0"
+)
+
+/*
+Root handles GET /
+
+A small unauthenticated landing document so developers hitting the bare
+host see what the API offers. Endpoints are read from the registered routes,
+so the list never drifts from the router. Only the environment name is
+reported from the configuration.
+*/
+func (h *Handler) Root(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"service":     ServiceName,
+		"version":     Version,
+		"environment": h.cfg.Environment,
+		"health":      "/api/health",
+		"endpoints":   listEndpoints(c.App().GetRoutes(true)),
+	})
+}
+
+// listEndpoints returns "METHOD /path" for every API route, sorted by path.
+// Implicit HEAD routes and the root itself are left out.
+func listEndpoints(routes []fiber.Route) []string {
+	seen := map[string]bool{}
+	endpoints := []string{}
+
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead || route.Path == "/" {
+			continue
+		}
+		endpoint := route.Method + " " + route.Path
+		if !seen[endpoint] {
+			seen[endpoint] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		pathI := endpoints[i][strings.IndexByte(endpoints[i], ' ')+1:]
+		pathJ := endpoints[j][strings.IndexByte(endpoints[j], ' ')+1:]
+		if pathI != pathJ {
+			return pathI < pathJ
+		}
+		return endpoints[i] < endpoints[j]
+	})
+
+	return endpoints
+}
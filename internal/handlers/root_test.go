@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoot tests the landing document built from the registered routes
+func TestRoot(t *testing.T) {
+	h := &Handler{cfg: &config.Config{Environment: "staging", OpenAIAPIKey: "secret"}}
+	noop := func(c *fiber.Ctx) error { return nil }
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error { return c.Next() })
+	app.Get("/", h.Root)
+	api := app.Group("/api")
+	api.Get("/health", noop)
+	api.Post("/generate", noop)
+	api.Get("/data/:id", noop)
+	api.Patch("/data/:id/coerce", noop)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "secret")
+
+	var info struct {
+		Service     string   `json:"service"`
+		Version     string   `json:"version"`
+		Environment string   `json:"environment"`
+		Endpoints   []string `json:"endpoints"`
+	}
+	require.NoError(t, json.Unmarshal(body, &info))
+
+	assert.Equal(t, ServiceName, info.Service)
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, "staging", info.Environment)
+	assert.Equal(t, []string{
+		"GET /api/data/:id",
+		"PATCH /api/data/:id/coerce",
+		"POST /api/generate",
+		"GET /api/health",
+	}, info.Endpoints)
+}
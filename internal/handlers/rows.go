@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseRowIndices parses a comma-separated list of zero-based row indices,
+// keeping their order and dropping duplicates; every index must be below rowCount
+func parseRowIndices(raw string, rowCount int) ([]int, error) {
+	indices := []int{}
+	seen := map[int]bool{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		index, err := strconv.Atoi(part)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("'%s' is not a valid row index", part)
+		}
+		if index >= rowCount {
+			return nil, fmt.Errorf("row index %d is out of range, the dataset has %d rows (0-%d)", index, rowCount, rowCount-1)
+		}
+
+		if !seen[index] {
+			seen[index] = true
+			indices = append(indices, index)
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("rows must list at least one row index")
+	}
+
+	return indices, nil
+}
+
+// parseFieldSubset parses the fields export parameter, a comma-separated
+// subset of fieldNames; order is kept and duplicates dropped
+func parseFieldSubset(raw string, fieldNames []string) ([]string, error) {
+	fields := []string{}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(raw, ",") {
+		field := strings.TrimSpace(part)
+		if field == "" {
+			continue
+		}
+
+		if !slices.Contains(fieldNames, field) {
+			return nil, fmt.Errorf("field '%s' is not in the dataset (fields: %s)", field, strings.Join(fieldNames, ", "))
+		}
+
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must list at least one field")
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRowIndices tests parsing of the rows export parameter
+func TestParseRowIndices(t *testing.T) {
+	indices, err := parseRowIndices("4, 0,2,,4", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 0, 2}, indices, "Order is kept and duplicates dropped")
+
+	for _, raw := range []string{" , ", "1,abc", "-1", "1.5"} {
+		_, err := parseRowIndices(raw, 5)
+		assert.Error(t, err, raw)
+	}
+
+	_, err = parseRowIndices("0,5", 5)
+	assert.ErrorContains(t, err, "out of range")
+
+	_, err = parseRowIndices("0", 0)
+	assert.ErrorContains(t, err, "out of range", "Empty datasets have no valid index")
+}
+
+// TestParseFieldSubset tests parsing of the fields export parameter
+func TestParseFieldSubset(t *testing.T) {
+	fieldNames := []string{"id", "name", "email"}
+
+	fields, err := parseFieldSubset("email, id,,email", fieldNames)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email", "id"}, fields, "Order is kept and duplicates dropped")
+
+	_, err = parseFieldSubset("id,phone", fieldNames)
+	assert.EqualError(t, err, "field 'phone' is not in the dataset (fields: id, name, email)")
+
+	_, err = parseFieldSubset(" , ", fieldNames)
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// Upper bound on how many scenarios the popular endpoint returns
+const maxPopularScenarios = 100
+
+/*
+PopularScenarios handles GET /api/scenarios/popular?limit=20
+
+Lists distinct scenarios, most requested first. Scenarios that only differ
+in case or whitespace ("Users", "users ") are counted together.
+*/
+func (h *Handler) PopularScenarios(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > maxPopularScenarios {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("limit must be between 1 and %d", maxPopularScenarios),
+		})
+	}
+
+	rows, err := h.db.QueryContext(
+		c.UserContext(),
+		`SELECT scenario_hash,
+		        (ARRAY_AGG(scenario ORDER BY created_at DESC))[1],
+		        COUNT(*),
+		        MAX(created_at)
+		 FROM generation_requests
+		 WHERE scenario_hash IS NOT NULL
+		 GROUP BY scenario_hash
+		 ORDER BY COUNT(*) DESC, MAX(created_at) DESC
+		 LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+	defer rows.Close()
+
+	scenarios := []models.PopularScenario{}
+	for rows.Next() {
+		var scenario models.PopularScenario
+		if err := rows.Scan(&scenario.ScenarioHash, &scenario.Scenario, &scenario.Requests, &scenario.LastUsed); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Failed to scan row",
+				Message: err.Error(),
+			})
+		}
+		scenarios = append(scenarios, scenario)
+	}
+
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"scenarios": scenarios,
+		"count":     len(scenarios),
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+ValidateSQL handles POST /api/data/:id/sql/validate
+
+Checks, without connecting anywhere, whether the dataset's SQL export fits
+an existing table:
+
+	{"columns": [
+	  {"name": "id", "type": "INTEGER"},
+	  {"name": "email", "type": "VARCHAR(255)", "nullable": true},
+	  {"name": "created_at", "type": "TIMESTAMP", "has_default": true}
+	]}
+
+The response lists every mismatch; an empty list means compatible.
+*/
+func (h *Handler) ValidateSQL(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	var req models.SQLValidateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+	}
+
+	if len(req.Columns) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid columns",
+			Message: "columns must list at least one target column",
+		})
+	}
+
+	dataset, err := h.loadDataset(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	mismatches, err := services.CheckSQLCompatibility(dataset.Data, dataset.FieldNames, req.Columns)
+	if errors.Is(err, services.ErrInvalidExportOption) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid columns",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(models.SQLValidateResponse{
+		RequestID:  dataset.RequestID,
+		Compatible: len(mismatches) == 0,
+		Mismatches: mismatches,
+	})
+}
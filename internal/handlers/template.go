@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+ExportTemplate handles POST /api/data/:id/export/template
+
+Renders the dataset with a Go text/template from the body:
+
+	{
+	  "template": "({{sql .Row.id}}, {{sql .Row.name}})",
+	  "header": "INSERT INTO people (id, name) VALUES\n",
+	  "footer": ";\n",
+	  "separator": ",\n",
+	  "extension": "sql"
+	}
+
+Value-formatting query parameters (bool_format, true_label, false_label,
+null_as, strict_fields) apply as for the regular export.
+*/
+func (h *Handler) ExportTemplate(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	var req models.TemplateExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid request body",
+			Message: err.Error(),
+		})
+	}
+
+	extension := req.Extension
+	if extension == "" {
+		extension = "txt"
+	}
+	if !isFileExtension(extension) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: "extension must be 1-10 letters or digits",
+		})
+	}
+
+	opts, err := exportOptionsFromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid export options",
+			Message: err.Error(),
+		})
+	}
+
+	dataset, err := h.loadDataset(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	content, err := h.exportService.WithOptions(opts).ToTemplate(dataset.Data, dataset.FieldNames, services.TemplateSpec{
+		Row:       req.Template,
+		Header:    req.Header,
+		Footer:    req.Footer,
+		Separator: req.Separator,
+	})
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	c.Set("Content-Type", "text/plain; charset=utf-8")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=mockdata-%s.%s", requestID, extension))
+	c.Set("Cache-Control", cacheNoStore)
+
+	return c.Send(content)
+}
+
+// isFileExtension accepts short alphanumeric extensions such as "sql" or "ts"
+func isFileExtension(value string) bool {
+	if len(value) > 10 {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
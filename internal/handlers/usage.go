@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+// Default reporting window for the usage endpoint
+const defaultUsageDays = 30
+
+const dateLayout = "2006-01-02"
+
+/*
+GetUsageSummary handles GET /api/admin/usage?from=2024-01-01&to=2024-01-31
+
+Aggregates OpenAI token usage by day and model with an estimated cost.
+
+Query parameters:
+- from: first day to include (default: 30 days ago)
+- to: last day to include (default: today)
+*/
+func (h *Handler) GetUsageSummary(c *fiber.Ctx) error {
+	from, to, err := usageRange(c.Query("from"), c.Query("to"), time.Now().UTC())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Error:   "Invalid date range",
+			Message: err.Error(),
+		})
+	}
+
+	rows, err := h.db.QueryContext(
+		c.UserContext(),
+		`SELECT TO_CHAR(created_at, 'YYYY-MM-DD') AS day, model, COUNT(*),
+		        SUM(prompt_tokens), SUM(completion_tokens), SUM(total_tokens)
+		 FROM openai_usage
+		 WHERE created_at >= $1 AND created_at < $2
+		 GROUP BY day, model
+		 ORDER BY day, model`,
+		from,
+		to.AddDate(0, 0, 1), // "to" is inclusive
+	)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+	defer rows.Close()
+
+	summary := models.UsageSummary{
+		From: from.Format(dateLayout),
+		To:   to.Format(dateLayout),
+		Days: []models.UsageDay{},
+	}
+	unpriced := map[string]bool{}
+
+	for rows.Next() {
+		var day models.UsageDay
+		if err := rows.Scan(&day.Date, &day.Model, &day.Requests, &day.PromptTokens, &day.CompletionTokens, &day.TotalTokens); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Error:   "Failed to scan row",
+				Message: err.Error(),
+			})
+		}
+
+		cost, priced := services.EstimateCost(day.Model, day.PromptTokens, day.CompletionTokens)
+		if !priced {
+			unpriced[day.Model] = true
+		}
+		day.EstimatedCostUSD = cost
+
+		summary.Totals.Requests += day.Requests
+		summary.Totals.PromptTokens += day.PromptTokens
+		summary.Totals.CompletionTokens += day.CompletionTokens
+		summary.Totals.TotalTokens += day.TotalTokens
+		summary.Totals.EstimatedCostUSD += cost
+		summary.Days = append(summary.Days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	for model := range unpriced {
+		summary.UnpricedModels = append(summary.UnpricedModels, model)
+	}
+	sort.Strings(summary.UnpricedModels)
+
+	return c.JSON(summary)
+}
+
+// usageRange parses the inclusive [from, to] day range, defaulting to the
+// last 30 days ending today
+func usageRange(rawFrom, rawTo string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if rawTo != "" {
+		parsed, err := time.Parse(dateLayout, rawTo)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be a YYYY-MM-DD date")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(defaultUsageDays - 1))
+	if rawFrom != "" {
+		parsed, err := time.Parse(dateLayout, rawFrom)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be a YYYY-MM-DD date")
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUsageRange tests date range parsing for the usage endpoint
+func TestUsageRange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+
+	from, to, err := usageRange("", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-15", from.Format(dateLayout), "Default covers the last 30 days")
+	assert.Equal(t, "2024-03-15", to.Format(dateLayout))
+
+	from, to, err = usageRange("2024-01-01", "2024-01-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01", from.Format(dateLayout))
+	assert.Equal(t, "2024-01-31", to.Format(dateLayout))
+
+	from, _, err = usageRange("", "2024-01-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02", from.Format(dateLayout), "Default window ends at to")
+
+	_, _, err = usageRange("2024-02-01", "2024-01-01", now)
+	assert.Error(t, err, "from after to")
+
+	_, _, err = usageRange("01/02/2024", "", now)
+	assert.Error(t, err, "Bad from format")
+
+	_, _, err = usageRange("", "yesterday", now)
+	assert.Error(t, err, "Bad to format")
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/kennyg37/wrapperX/backend/internal/services"
+)
+
+/*
+ExportVersions handles GET /api/data/:id/versions/export?format=csv
+
+Meant to export every version of a dataset at once, with a version column
+or one file per version. Datasets are stored once per request and have no
+version history yet (regenerating creates a new request), so an existing
+dataset is answered with 422 until versioning lands.
+
+Query parameters:
+- format: export format (default: json)
+*/
+func (h *Handler) ExportVersions(c *fiber.Ctx) error {
+	requestID := c.Params("id")
+	id, err := parseRequestID(requestID)
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	format := c.Query("format", "json")
+	if !slices.Contains(services.NewExportService().GetAvailableFormats(), format) {
+		return exportError(c, fmt.Errorf("%w: '%s'", services.ErrUnsupportedFormat, format))
+	}
+
+	_, err = h.loadDataset(c.UserContext(), id)
+	if err == sql.ErrNoRows {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Error:   "Dataset not found",
+			Message: fmt.Sprintf("No dataset found for request ID %s", requestID),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(models.ErrorResponse{
+		Error:   "No version history",
+		Message: models.ErrNoVersionHistory.Error(),
+	})
+}
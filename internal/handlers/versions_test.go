@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportVersions tests that datasets without version history are rejected explicitly
+func TestExportVersions(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.serveDataset(t, []map[string]interface{}{{"id": "1"}}, []string{"id"})
+
+	app := fiber.New()
+	app.Get("/api/data/:id/versions/export", (&Handler{cfg: &config.Config{}, db: db}).ExportVersions)
+
+	export := func(path string) (int, models.ErrorResponse) {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		var body models.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+
+	status, body := export("/api/data/1/versions/export?format=csv")
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Equal(t, models.ErrNoVersionHistory.Error(), body.Message)
+
+	status, _ = export("/api/data/1/versions/export?format=pdf")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = export("/api/data/abc/versions/export")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	fake.query = func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		return nil, nil, nil
+	}
+	status, _ = export("/api/data/2/versions/export?format=csv")
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
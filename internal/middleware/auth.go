@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// AdminKeyHeader is the header carrying the admin API key
+const AdminKeyHeader = "X-Admin-Key"
+
+// APIKeyHeader is the header carrying a client API key
+const APIKeyHeader = "X-API-Key"
+
+// probePaths stay open to load balancers, uptime checks and Kubernetes probes
+var probePaths = map[string]bool{"/api/health": true, "/api/live": true, "/api/ready": true}
+
+// HasAdminKey reports whether the request carries the configured admin key.
+// Always false when no admin key is configured.
+func HasAdminKey(c *fiber.Ctx, adminKey string) bool {
+	if adminKey == "" {
+		return false
+	}
+	provided := c.Get(AdminKeyHeader)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) == 1
+}
+
+// AdminAuth restricts routes to callers presenting the admin key.
+// When no admin key is configured the routes are disabled entirely.
+func AdminAuth(adminKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if adminKey == "" {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Admin endpoints are disabled (ADMIN_API_KEY is not set)",
+			})
+		}
+
+		if !HasAdminKey(c, adminKey) {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "A valid " + AdminKeyHeader + " header is required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+/*
+APIKeyAuth restricts routes to callers presenting one of validKeys, in the
+X-API-Key header or as an Authorization: Bearer token. The health probes and
+CORS preflight requests are always let through.
+
+With no keys configured it lets every request through (development mode).
+*/
+func APIKeyAuth(validKeys []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(validKeys) == 0 || c.Method() == fiber.MethodOptions || probePaths[c.Path()] {
+			return c.Next()
+		}
+
+		provided := apiKey(c)
+		if provided == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "An API key is required in the " + APIKeyHeader + " header or as a Bearer token",
+			})
+		}
+
+		// Every key is compared so the timing doesn't reveal which one matched
+		valid := 0
+		for _, key := range validKeys {
+			valid |= subtle.ConstantTimeCompare([]byte(provided), []byte(key))
+		}
+		if valid != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Invalid API key",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// apiKey returns the key from X-API-Key, or else the Authorization Bearer token
+func apiKey(c *fiber.Ctx) string {
+	if key := c.Get(APIKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAPIKeyApp(keys []string) *fiber.App {
+	app := fiber.New()
+	api := app.Group("/api", APIKeyAuth(keys))
+	api.Get("/health", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	api.Get("/ready", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	api.Get("/requests", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+// TestAPIKeyAuth tests API key checks on API routes
+func TestAPIKeyAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"Valid key", "/api/requests", "X-API-Key", "key-one", fiber.StatusOK},
+		{"Second key", "/api/requests", "X-API-Key", "key-two", fiber.StatusOK},
+		{"Bearer token", "/api/requests", "Authorization", "Bearer key-two", fiber.StatusOK},
+		{"Lowercase scheme", "/api/requests", "Authorization", "bearer key-one", fiber.StatusOK},
+		{"Invalid key", "/api/requests", "X-API-Key", "key-three", fiber.StatusUnauthorized},
+		{"Prefix of a key", "/api/requests", "X-API-Key", "key", fiber.StatusUnauthorized},
+		{"Invalid bearer token", "/api/requests", "Authorization", "Bearer nope", fiber.StatusUnauthorized},
+		{"Basic auth", "/api/requests", "Authorization", "Basic a2V5LW9uZQ==", fiber.StatusUnauthorized},
+		{"Missing key", "/api/requests", "", "", fiber.StatusUnauthorized},
+		{"Health check", "/api/health", "", "", fiber.StatusOK},
+		{"Readiness probe", "/api/ready", "", "", fiber.StatusOK},
+	}
+
+	app := newAPIKeyApp([]string{"key-one", "key-two"})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
+
+// TestAPIKeyAuth_NoKeys tests that the API stays open without configured keys
+func TestAPIKeyAuth_NoKeys(t *testing.T) {
+	resp, err := newAPIKeyApp(nil).Test(httptest.NewRequest("GET", "/api/requests", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
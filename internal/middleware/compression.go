@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// precompressedTypes are response media types whose body is already a
+// compressed archive; compressing them again only costs CPU
+var precompressedTypes = map[string]bool{
+	"application/zip":  true,
+	"application/gzip": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true,
+}
+
+/*
+Compression compresses response bodies with brotli, gzip or deflate,
+whichever the client's Accept-Encoding asks for first in that order.
+
+It uses the same fasthttp compressor as Fiber's compress middleware, but
+decides after the handler ran: compress.Config.Next only sees the request,
+and fasthttp treats every application/* type as compressible, so zip and
+xlsx exports would otherwise be compressed a second time. Responses that
+already carry a Content-Encoding, and bodies under 200 bytes, are left as is.
+*/
+func Compression() fiber.Handler {
+	compressor := fasthttp.CompressHandlerBrotliLevel(func(*fasthttp.RequestCtx) {},
+		fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressDefaultCompression)
+
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if isPrecompressed(string(c.Response().Header.ContentType())) {
+			return nil
+		}
+
+		compressor(c.Context())
+		return nil
+	}
+}
+
+// isPrecompressed reports whether contentType is one of precompressedTypes
+func isPrecompressed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return precompressedTypes[strings.ToLower(mediaType)]
+}
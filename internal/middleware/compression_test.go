@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressionApp() *fiber.App {
+	rows := make([]map[string]interface{}, 100)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i, "name": "Ada Lovelace", "email": "ada@example.com"}
+	}
+
+	app := fiber.New()
+	app.Use(Compression())
+	app.Get("/data", func(c *fiber.Ctx) error { return c.JSON(fiber.Map{"data": rows}) })
+	app.Get("/export", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/zip")
+		return c.SendString(strings.Repeat("PK", 500))
+	})
+
+	return app
+}
+
+// TestCompression tests compressing responses according to Accept-Encoding
+func TestCompression(t *testing.T) {
+	t.Run("Gzip", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/data", nil)
+		req.Header.Set(fiber.HeaderAcceptEncoding, "gzip")
+		resp, err := newCompressionApp().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+
+		reader, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		var body struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(reader).Decode(&body))
+		assert.Len(t, body.Data, 100)
+	})
+
+	t.Run("Not accepted", func(t *testing.T) {
+		resp, err := newCompressionApp().Test(httptest.NewRequest("GET", "/data", nil))
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get(fiber.HeaderContentEncoding))
+	})
+
+	t.Run("Zip export", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/export", nil)
+		req.Header.Set(fiber.HeaderAcceptEncoding, "gzip")
+		resp, err := newCompressionApp().Test(req)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get(fiber.HeaderContentEncoding), "Archives are not compressed twice")
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("PK", 500), string(body))
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+/*
+RequireContentType rejects POST/PUT/PATCH requests whose body is not one of
+the accepted media types with 415 Unsupported Media Type.
+
+Without this, BodyParser happily "parses" form-encoded or text bodies into an
+empty struct and the request fails later with a confusing validation error.
+Requests without a body (e.g. action endpoints) are let through.
+*/
+func RequireContentType(accepted []string) fiber.Handler {
+	allowed := make(map[string]bool, len(accepted))
+	for _, mediaType := range accepted {
+		allowed[strings.ToLower(strings.TrimSpace(mediaType))] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		if len(c.Body()) == 0 {
+			return c.Next()
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+		if err != nil || !allowed[strings.ToLower(mediaType)] {
+			return c.Status(fiber.StatusUnsupportedMediaType).JSON(models.ErrorResponse{
+				Error:   "Unsupported Media Type",
+				Message: fmt.Sprintf("Content-Type must be one of: %s", strings.Join(accepted, ", ")),
+			})
+		}
+
+		return c.Next()
+	}
+}
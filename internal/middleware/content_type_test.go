@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContentTypeApp() *fiber.App {
+	app := fiber.New()
+	app.Use(RequireContentType([]string{"application/json"}))
+	app.Post("/generate", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+	app.Get("/data", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+// TestRequireContentType tests Content-Type enforcement on request bodies
+func TestRequireContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"JSON body", "POST", "/generate", "application/json", `{"scenario":"users"}`, fiber.StatusCreated},
+		{"JSON with charset", "POST", "/generate", "application/json; charset=utf-8", `{}`, fiber.StatusCreated},
+		{"Case-insensitive", "POST", "/generate", "Application/JSON", `{}`, fiber.StatusCreated},
+		{"Form-encoded", "POST", "/generate", "application/x-www-form-urlencoded", "scenario=users&row_count=10", fiber.StatusUnsupportedMediaType},
+		{"Plain text", "POST", "/generate", "text/plain", "users", fiber.StatusUnsupportedMediaType},
+		{"Missing Content-Type", "POST", "/generate", "", `{}`, fiber.StatusUnsupportedMediaType},
+		{"Empty body", "POST", "/generate", "", "", fiber.StatusCreated},
+		{"GET is not checked", "GET", "/data", "text/plain", "", fiber.StatusOK},
+	}
+
+	app := newContentTypeApp()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.StatusCode)
+		})
+	}
+}
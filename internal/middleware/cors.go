@@ -1,30 +1,38 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
 )
 
-// CORS middleware configures Cross-Origin Resource Sharing
+/*
+CORS middleware configures Cross-Origin Resource Sharing
+
+allowedOrigins come from config.ParseCORSOrigins. Credentials are allowed
+only for an explicit origin list: browsers reject credentialed responses
+with a wildcard origin, and allowing any site to send credentialed requests
+would defeat CORS.
+*/
 func CORS(allowedOrigins []string) fiber.Handler {
 	return cors.New(cors.Config{
-		AllowOrigins: getAllowOriginsString(allowedOrigins),
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
-		AllowCredentials: true,
-		ExposeHeaders: "Content-Length,Content-Type",
+		AllowOrigins: strings.Join(allowedOrigins, ","),
+		AllowMethods: "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization,X-Request-ID," + APIKeyHeader + "," + AdminKeyHeader,
+		AllowCredentials: !allowsAnyOrigin(allowedOrigins),
+		ExposeHeaders: "Content-Length,Content-Type,X-Request-ID",
 		MaxAge: 86400, // 24 hours
 	})
 }
 
-// getAllowOriginsString converts a slice of origins to a comma-separated string
-func getAllowOriginsString(origins []string) string {
-	result := ""
-	for i, origin := range origins {
-		result += origin
-		if i < len(origins)-1 {
-			result += ","
+// allowsAnyOrigin reports whether origins contains the wildcard origin
+func allowsAnyOrigin(origins []string) bool {
+	for _, origin := range origins {
+		if origin == config.AnyOrigin {
+			return true
 		}
 	}
-	return result
+	return false
 }
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// preflight sends a CORS preflight from origin and returns the response headers
+func preflight(t *testing.T, origins []string, origin string) (string, string) {
+	app := fiber.New()
+	app.Use(CORS(origins))
+	app.Get("/api/requests", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest("OPTIONS", "/api/requests", nil)
+	req.Header.Set(fiber.HeaderOrigin, origin)
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, "GET")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	return resp.Header.Get(fiber.HeaderAccessControlAllowOrigin), resp.Header.Get(fiber.HeaderAccessControlAllowCredentials)
+}
+
+// TestCORS tests the credentials setting for explicit and wildcard origins
+func TestCORS(t *testing.T) {
+	t.Run("Explicit origins", func(t *testing.T) {
+		origin, credentials := preflight(t, []string{"http://a.com", "http://b.com"}, "http://b.com")
+		assert.Equal(t, "http://b.com", origin)
+		assert.Equal(t, "true", credentials)
+
+		origin, _ = preflight(t, []string{"http://a.com"}, "http://evil.com")
+		assert.Empty(t, origin)
+	})
+
+	t.Run("Wildcard", func(t *testing.T) {
+		origin, credentials := preflight(t, []string{config.AnyOrigin}, "http://any.com")
+		assert.Equal(t, "*", origin)
+		assert.Empty(t, credentials, "Credentials are never allowed for any origin")
+	})
+}
+
+// TestCORS_KeyHeaders tests that browsers may send the API and admin keys
+func TestCORS_KeyHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS([]string{"http://a.com"}))
+	app.Post("/api/generate", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	req := httptest.NewRequest("OPTIONS", "/api/generate", nil)
+	req.Header.Set(fiber.HeaderOrigin, "http://a.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, "POST")
+	req.Header.Set(fiber.HeaderAccessControlRequestHeaders, "content-type,x-api-key,x-admin-key")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	allowed := resp.Header.Get(fiber.HeaderAccessControlAllowHeaders)
+	assert.Contains(t, allowed, APIKeyHeader)
+	assert.Contains(t, allowed, AdminKeyHeader)
+}
@@ -2,14 +2,26 @@ package middleware
 
 import (
 	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
 )
 
 
-// Logger middleware logs each HTTP request
-func Logger() fiber.Handler {
+/*
+Logger middleware logs each HTTP request
+
+format is config.LogFormatText for a readable line per request, or
+config.LogFormatJSON for a JSON object with method, path, ip, status,
+duration_ms and request_id, written to the standard logger's output.
+*/
+func Logger(format string) fiber.Handler {
+	if format == config.LogFormatJSON {
+		return jsonLogger(slog.New(slog.NewJSONHandler(log.Writer(), nil)))
+	}
+
 	return func(c *fiber.Ctx) error {
 		// Record start time
 		start := time.Now()
@@ -22,14 +34,34 @@ func Logger() fiber.Handler {
 
 		// Log request details
 		log.Printf(
-			"[%s] %s %s - %d - %v",
+			"[%s] %s %s - %d - %v - %s",
 			c.Method(),           // HTTP method (GET, POST, etc.)
 			c.Path(),             // Request path
 			c.IP(),               // Client IP address
 			c.Response().StatusCode(), // Response status code
 			duration,             // Request duration
+			GetRequestID(c),      // Request id, see RequestID
+		)
+
+		return err
+	}
+}
+
+// jsonLogger logs each request as structured attributes through logger
+func jsonLogger(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		logger.Info("request",
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("ip", c.IP()),
+			slog.Int("status", c.Response().StatusCode()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("request_id", GetRequestID(c)),
 		)
 
 		return err
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLog sends GET /missing through Logger(format) and returns what it logged
+func captureLog(t *testing.T, format string) []byte {
+	var output bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&output)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	app := fiber.New()
+	app.Use(RequestID(), Logger(format))
+	app.Get("/missing", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNotFound) })
+
+	req := httptest.NewRequest("GET", "/missing", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "trace-1")
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	return output.Bytes()
+}
+
+// TestLogger_JSON tests that the JSON format logs one object per request
+func TestLogger_JSON(t *testing.T) {
+	output := captureLog(t, config.LogFormatJSON)
+	require.Equal(t, 1, bytes.Count(output, []byte("\n")), "One line per request")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(output, &entry), string(output))
+
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/missing", entry["path"])
+	assert.Equal(t, "0.0.0.0", entry["ip"])
+	assert.Equal(t, float64(fiber.StatusNotFound), entry["status"])
+	assert.Equal(t, "trace-1", entry["request_id"])
+	assert.Contains(t, entry, "duration_ms")
+}
+
+// TestLogger_Text tests that text stays the free-form line
+func TestLogger_Text(t *testing.T) {
+	output := captureLog(t, config.LogFormatText)
+	assert.False(t, json.Valid(output))
+	assert.Contains(t, string(output), "[GET] /missing")
+	assert.Contains(t, string(output), "trace-1")
+}
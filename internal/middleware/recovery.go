@@ -15,7 +15,7 @@ func Recovery() fiber.Handler {
 
 		// StackTraceHandler: custom function to handle stack traces
 		StackTraceHandler: func(c *fiber.Ctx, e interface{}) {
-			log.Printf("PANIC RECOVERED: %v", e)
+			log.Printf("PANIC RECOVERED [%s]: %v", GetRequestID(c), e)
 		},
 	})
 }
\ No newline at end of file
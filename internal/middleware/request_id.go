@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// requestIDKey is the c.Locals key holding the request id
+const requestIDKey = "requestID"
+
+// maxRequestIDLength caps incoming ids so clients cannot flood the logs
+const maxRequestIDLength = 128
+
+/*
+RequestID tags each request with an id for correlating logs and errors.
+
+An incoming X-Request-ID (e.g. from a proxy or the client) is reused so the
+id follows the request across services; a missing, overlong or non-printable
+one is replaced with a random UUID. The id is echoed in the X-Request-ID
+response header and available to later handlers via GetRequestID.
+*/
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(id) {
+			id = utils.UUIDv4()
+		}
+
+		c.Locals(requestIDKey, id)
+		c.Set(fiber.HeaderXRequestID, id)
+
+		return c.Next()
+	}
+}
+
+// GetRequestID returns the id set by RequestID, or "" when it did not run
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDKey).(string)
+	return id
+}
+
+// validRequestID accepts non-empty ids of printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uuidPattern matches a random (version 4) UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func newRequestIDApp() *fiber.App {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(GetRequestID(c)) })
+	return app
+}
+
+// requestID sends a request with the given X-Request-ID and returns the
+// response header and the id the handler saw
+func requestID(t *testing.T, incoming string) (string, string) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if incoming != "" {
+		req.Header.Set(fiber.HeaderXRequestID, incoming)
+	}
+	resp, err := newRequestIDApp().Test(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.Header.Get(fiber.HeaderXRequestID), string(body)
+}
+
+// TestRequestID tests reusing or generating the request id
+func TestRequestID(t *testing.T) {
+	t.Run("Echoed", func(t *testing.T) {
+		header, seen := requestID(t, "trace-abc-123")
+		assert.Equal(t, "trace-abc-123", header)
+		assert.Equal(t, "trace-abc-123", seen)
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		header, seen := requestID(t, "")
+		assert.Regexp(t, uuidPattern, header)
+		assert.Equal(t, header, seen)
+
+		other, _ := requestID(t, "")
+		assert.NotEqual(t, header, other)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, incoming := range []string{"has space", strings.Repeat("a", maxRequestIDLength+1)} {
+			header, _ := requestID(t, incoming)
+			assert.Regexp(t, uuidPattern, header, incoming)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+/*
+Timeout bounds how long a route may run with a server-side deadline.
+
+The deadline is attached to c.UserContext(), so handlers must pass that
+context to downstream calls (OpenAI, database) for them to be cancelled.
+When the deadline is exceeded the response is replaced with 504 Gateway
+Timeout, whatever the handler returned. A zero duration disables the limit.
+*/
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if d <= 0 {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return c.Status(fiber.StatusGatewayTimeout).JSON(models.ErrorResponse{
+				Error:   "Request timed out",
+				Message: fmt.Sprintf("The request did not complete within %s", d),
+			})
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTimeoutApp(d time.Duration) *fiber.App {
+	app := fiber.New()
+
+	// Simulates a downstream call that honours cancellation
+	app.Get("/slow", Timeout(d), func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			return c.Status(fiber.StatusInternalServerError).SendString(c.UserContext().Err().Error())
+		case <-time.After(200 * time.Millisecond):
+			return c.SendStatus(fiber.StatusOK)
+		}
+	})
+	app.Get("/fast", Timeout(d), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	return app
+}
+
+// TestTimeout tests the per-route deadline middleware
+func TestTimeout(t *testing.T) {
+	t.Run("Exceeded", func(t *testing.T) {
+		app := newTimeoutApp(20 * time.Millisecond)
+		resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusGatewayTimeout, resp.StatusCode)
+	})
+
+	t.Run("Within deadline", func(t *testing.T) {
+		app := newTimeoutApp(20 * time.Millisecond)
+		resp, err := app.Test(httptest.NewRequest("GET", "/fast", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		app := newTimeoutApp(0)
+		resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
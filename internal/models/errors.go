@@ -1,13 +1,78 @@
 package models
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
-	ErrInvalidScenario    = errors.New("scenario description is required")
-	ErrInvalidRowCount    = errors.New("row count must be between 1 and 1000")
-	ErrRequestNotFound    = errors.New("generation request not found")
-	ErrDatasetNotFound    = errors.New("dataset not found")
-	ErrOpenAIFailure      = errors.New("failed to generate data with OpenAI")
-	ErrDatabaseConnection = errors.New("database connection failed")
-	ErrInvalidFormat      = errors.New("invalid export format")
+	ErrInvalidScenario         = errors.New("scenario description is required")
+	ErrScenarioTooLong         = errors.New("scenario description is too long")
+	ErrInvalidRowCount         = errors.New("row count is out of range")
+	ErrInvalidRowCountRange    = errors.New("row_count_min must not be greater than row_count_max")
+	ErrRequestNotFound         = errors.New("generation request not found")
+	ErrDatasetNotFound         = errors.New("dataset not found")
+	ErrNoVersionHistory        = errors.New("dataset has no version history; regenerating a request creates a new request instead of a new version")
+	ErrOpenAIFailure           = errors.New("failed to generate data with OpenAI")
+	ErrGenerationTimeout       = errors.New("generation timed out")
+	ErrDatabaseConnection      = errors.New("database connection failed")
+	ErrInvalidFormat           = errors.New("invalid export format")
+	ErrInvalidReference        = errors.New("reference requires a request_id and at least one field")
+	ErrEncryptionNotConfigured = errors.New("field encryption is not configured (set FPE_KEY)")
+	ErrInvalidFieldLanguage    = errors.New("field_name_language must be a language name or code of at most 32 letters")
+	ErrInvalidLocale           = errors.New("locale must be a BCP 47 tag of at most 35 characters, e.g. fr-FR or ja-JP")
+	ErrScenarioNotAllowed      = errors.New("scenario is not allowed by the generation policy")
+	ErrInvalidFieldPrompts     = errors.New("field_prompts allows at most 10 fields, each with a non-empty prompt of at most 500 characters")
+	ErrFieldPromptsDisabled    = errors.New("field_prompts are disabled on this server (set FIELD_PROMPTS_ENABLED)")
+	ErrInvalidEdgeCases        = errors.New("edge_cases allows at most 50 fields, each with a probability between 0 and 1 and kinds from empty, null, long_string, zero, negative")
+	ErrInvalidPatterns         = errors.New("patterns allows at most 20 fields, each with a valid regular expression of at most 200 characters")
+	ErrInvalidSchema           = errors.New("fields allows at most 50 entries, each with a unique non-empty name and a type of string, number, boolean or date; min and max (min <= max) are only for numbers, unique not for booleans")
+	ErrInvalidModel            = errors.New("model is not available for generation requests")
+	ErrInvalidTemperature      = errors.New("temperature must be between 0 and 2")
+)
+
+// Error codes of failed requests, so clients can tell failures apart
+// without parsing error_message
+const (
+	ErrorCodeTimeout          = "timeout"           // the model call or the whole generation ran out of time
+	ErrorCodeGenerationFailed = "generation_failed" // any other failure
 )
+
+// FailureCode returns the error code of a failed generation
+func FailureCode(err error) string {
+	if errors.Is(err, ErrGenerationTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCodeTimeout
+	}
+	return ErrorCodeGenerationFailed
+}
+
+// validationRules names each validation error for analytics
+var validationRules = map[error]string{
+	ErrInvalidScenario:         "scenario_required",
+	ErrScenarioTooLong:         "scenario_too_long",
+	ErrInvalidRowCount:         "row_count_out_of_range",
+	ErrInvalidRowCountRange:    "row_count_range_inverted",
+	ErrInvalidReference:        "invalid_reference",
+	ErrEncryptionNotConfigured: "encryption_not_configured",
+	ErrInvalidFieldLanguage:    "invalid_field_name_language",
+	ErrInvalidLocale:           "invalid_locale",
+	ErrScenarioNotAllowed:      "scenario_not_allowed",
+	ErrInvalidFieldPrompts:     "invalid_field_prompts",
+	ErrFieldPromptsDisabled:    "field_prompts_disabled",
+	ErrInvalidEdgeCases:        "invalid_edge_cases",
+	ErrInvalidPatterns:         "invalid_patterns",
+	ErrInvalidSchema:           "invalid_fields",
+	ErrInvalidModel:            "invalid_model",
+	ErrInvalidTemperature:      "temperature_out_of_range",
+}
+
+// ValidationRule returns a stable rule name for a validation error,
+// or "other" when the error is not a known validation failure
+func ValidationRule(err error) string {
+	for target, rule := range validationRules {
+		if errors.Is(err, target) {
+			return rule
+		}
+	}
+	return "other"
+}
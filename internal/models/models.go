@@ -1,6 +1,14 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 
 type GenerationRequest struct {
@@ -8,40 +16,449 @@ type GenerationRequest struct {
 	Scenario    string    `json:"scenario" db:"scenario"`
 	RowCount    int       `json:"row_count" db:"row_count"`
 	Status      string    `json:"status" db:"status"` // pending, processing, completed, failed
-	GeneratedAt time.Time `json:"generated_at" db:"generated_at"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	Pinned      bool      `json:"pinned" db:"pinned"`     // pinned requests are never purged
+	Degraded    bool      `json:"degraded" db:"degraded"` // data came from the offline fallback, not the model
+	Model       string    `json:"model" db:"model"`       // model asked to generate the data
+
+	// GeneratedAt is when the data was stored; nil until the request completes
+	GeneratedAt *time.Time `json:"generated_at,omitempty" db:"generated_at"`
+
+	// ActualRowCount is the number of rows stored, which can differ from
+	// RowCount; nil until the request completes
+	ActualRowCount *int `json:"actual_row_count,omitempty" db:"actual_row_count"`
+
+	// AdjustedRowCount is how many rows were changed to meet the schema's
+	// constraints; nil for requests without a schema
+	AdjustedRowCount *int `json:"adjusted_row_count,omitempty" db:"adjusted_row_count"`
+
+	// ErrorMessage says why a failed request failed; nil otherwise
+	ErrorMessage *string `json:"error_message,omitempty" db:"error_message"`
+
+	// ErrorCode is the kind of failure (ErrorCodeTimeout, ...); nil otherwise
+	ErrorCode *string `json:"error_code,omitempty" db:"error_code"`
+
+	// Locale the data was generated for, e.g. "fr-FR"; nil for the default
+	Locale *string `json:"locale,omitempty" db:"locale"`
+}
+
+// Request statuses, in lifecycle order
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+// IsValidStatus reports whether s is a known request status
+func IsValidStatus(s string) bool {
+	switch s {
+	case StatusPending, StatusProcessing, StatusCompleted, StatusFailed:
+		return true
+	}
+	return false
 }
 
 type MockDataset struct {
-	ID          int64                    `json:"id" db:"id"`
-	RequestID   int64                    `json:"request_id" db:"request_id"`
-	Data        []map[string]interface{} `json:"data"` 
-	FieldNames  []string                 `json:"field_names" db:"field_names"`
-	CreatedAt   time.Time                `json:"created_at" db:"created_at"`
+	ID              int64                    `json:"id" db:"id"`
+	RequestID       int64                    `json:"request_id" db:"request_id"`
+	Data            []map[string]interface{} `json:"data"` 
+	FieldNames      []string                 `json:"field_names" db:"field_names"`
+	EncryptedFields []string                 `json:"encrypted_fields,omitempty" db:"encrypted_fields"`
+	RowUpdatedAt    []time.Time              `json:"-" db:"row_updated_at"`
+	FieldTypes      map[string]string        `json:"field_types,omitempty" db:"field_types"`
+	CreatedAt       time.Time                `json:"created_at" db:"created_at"`
+}
+
+
+// MaxFieldLanguageLength bounds the field_name_language option
+const MaxFieldLanguageLength = 32
+
+// MaxLocaleLength bounds the locale option, the longest tag the
+// generation_requests.locale column stores
+const MaxLocaleLength = 35
+
+// localePattern matches BCP 47 style tags: a 2-3 letter language followed
+// by script, region or variant subtags, e.g. "fr", "fr-FR" or "zh-Hant-TW"
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// Limits for the per-field prompts of the advanced generation mode
+const (
+	MaxFieldPrompts      = 10
+	MaxFieldPromptLength = 500
+)
+
+// MaxEdgeCaseFields bounds the number of fields with edge case rules
+const MaxEdgeCaseFields = 50
+
+// Limits for the regular expressions of generated pattern fields
+const (
+	MaxPatternFields = 20
+	MaxPatternLength = 200
+)
+
+// MaxSchemaFields bounds the fields of an explicit schema
+const MaxSchemaFields = 50
+
+// Value types of explicit schema fields
+const (
+	SchemaTypeString  = "string"
+	SchemaTypeNumber  = "number"
+	SchemaTypeBoolean = "boolean"
+	SchemaTypeDate    = "date" // a "YYYY-MM-DD" string
+)
+
+// SchemaTypes lists every schema field type
+var SchemaTypes = []string{SchemaTypeString, SchemaTypeNumber, SchemaTypeBoolean, SchemaTypeDate}
+
+// SchemaField is one column of an explicit schema in a generate request
+type SchemaField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Optional constraints, enforced after generation: Unique values in
+	// every row (not for booleans), and for numbers an inclusive Min and Max
+	Unique bool     `json:"unique,omitempty"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+}
+
+// Edge case kinds that can be injected into generated values
+const (
+	EdgeCaseEmpty      = "empty"       // "" for strings
+	EdgeCaseNull       = "null"        // any value
+	EdgeCaseLongString = "long_string" // a very long string, for strings
+	EdgeCaseZero       = "zero"        // 0, for numbers
+	EdgeCaseNegative   = "negative"    // a negative number, for numbers
+)
+
+// EdgeCaseKinds lists every edge case kind
+var EdgeCaseKinds = []string{EdgeCaseEmpty, EdgeCaseNull, EdgeCaseLongString, EdgeCaseZero, EdgeCaseNegative}
+
+// IsValidEdgeCaseKind reports whether kind is a known edge case kind
+func IsValidEdgeCaseKind(kind string) bool {
+	for _, known := range EdgeCaseKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EdgeCaseRule injects boundary values into one field after generation
+type EdgeCaseRule struct {
+	// Probability of replacing each value, from 0 to 1
+	Probability float64 `json:"probability"`
+
+	// Kinds limits the injected values; all kinds that fit the value's type
+	// are used when empty
+	Kinds []string `json:"kinds,omitempty"`
+}
+
+// DefaultMaxScenarioLength is the scenario limit used by Validate
+const DefaultMaxScenarioLength = 2000
+
+// Limits holds the deployment-configurable request bounds
+type Limits struct {
+	// MaxScenarioLength caps the scenario in characters; zero disables the check
+	MaxScenarioLength int
+
+	// MaxRowCount caps the rows of a single request; zero means DefaultMaxRowCount
+	MaxRowCount int
+}
+
+// DefaultLimits returns the limits used when no configuration is given
+func DefaultLimits() Limits {
+	return Limits{MaxScenarioLength: DefaultMaxScenarioLength, MaxRowCount: DefaultMaxRowCount}
 }
 
+// Bounds for the number of rows a single request may generate; the
+// maximum is configurable through Limits
+const (
+	MinRowCount        = 1
+	DefaultMaxRowCount = 1000
+)
+
+// Bounds for the sampling temperature accepted by the OpenAI API
+const (
+	MinTemperature = 0.0
+	MaxTemperature = 2.0
+)
 
 type GenerateRequest struct {
 	Scenario string `json:"scenario"` 
 	RowCount int    `json:"row_count"` 
+
+	// Optional range; when set, the row count is picked randomly within it
+	RowCountMin int `json:"row_count_min,omitempty"`
+	RowCountMax int `json:"row_count_max,omitempty"`
+
+	// Fields to mask with reversible format-preserving encryption
+	EncryptFields []string `json:"encrypt_fields,omitempty"`
+
+	// Optional existing dataset whose values the new data should reuse
+	Reference *DatasetReference `json:"reference,omitempty"`
+
+	// Optional language for field names, e.g. "German" or "ja"
+	FieldNameLanguage string `json:"field_name_language,omitempty"`
+
+	// Optional locale for the values, e.g. "fr-FR" or "ja-JP": names,
+	// addresses and text are generated as they appear there
+	Locale string `json:"locale,omitempty"`
+
+	// Advanced mode: field name -> focused prompt used to fill that field
+	// after the skeleton rows are generated (more tokens, better values)
+	FieldPrompts map[string]string `json:"field_prompts,omitempty"`
+
+	// Field name -> rule for sprinkling edge case values into the output;
+	// EdgeCaseSeed makes the injection reproducible
+	EdgeCases    map[string]EdgeCaseRule `json:"edge_cases,omitempty"`
+	EdgeCaseSeed *int64                  `json:"edge_case_seed,omitempty"`
+
+	// Field name -> regular expression; values of these fields are
+	// generated to match it, replacing whatever the model produced
+	Patterns map[string]string `json:"patterns,omitempty"`
+
+	// Optional explicit schema: the data gets exactly these columns, in
+	// this order, with values of these types
+	Fields []SchemaField `json:"fields,omitempty"`
+
+	// Optional chat model, one of the allowed request models; the server
+	// default (OPENAI_MODEL) is used when empty
+	Model string `json:"model,omitempty"`
+
+	// Optional sampling temperature between MinTemperature and
+	// MaxTemperature; the server default (OPENAI_TEMPERATURE) when unset
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// Optional seed for reproducible output: passed to models that support
+	// it and seeding the template generator
+	Seed *int `json:"seed,omitempty"`
+
+	// Optional; false skips the generation cache and always generates
+	// fresh data
+	Cache *bool `json:"cache,omitempty"`
 }
 
-// Validate checks if the request is valid
+// DatasetReference points to columns of an existing dataset
+type DatasetReference struct {
+	RequestID int64    `json:"request_id"`
+	Fields    []string `json:"fields"`
+}
+
+// Validate checks if the request is valid using the default limits
 func (r *GenerateRequest) Validate() error {
+	return r.ValidateWithLimits(DefaultLimits())
+}
+
+// ValidateWithLimits checks if the request is valid within the given limits
+func (r *GenerateRequest) ValidateWithLimits(limits Limits) error {
 	if r.Scenario == "" {
 		return ErrInvalidScenario
 	}
-	if r.RowCount < 1 || r.RowCount > 1000 {
-		return ErrInvalidRowCount
+
+	if limits.MaxScenarioLength > 0 && utf8.RuneCountInString(r.Scenario) > limits.MaxScenarioLength {
+		return fmt.Errorf("%w: %d characters, the limit is %d", ErrScenarioTooLong, utf8.RuneCountInString(r.Scenario), limits.MaxScenarioLength)
+	}
+
+	maxRowCount := limits.MaxRowCount
+	if maxRowCount == 0 {
+		maxRowCount = DefaultMaxRowCount
+	}
+
+	if r.HasRowCountRange() {
+		if r.RowCountMin < MinRowCount || r.RowCountMax > maxRowCount {
+			return fmt.Errorf("%w: must be between %d and %d", ErrInvalidRowCount, MinRowCount, maxRowCount)
+		}
+		if r.RowCountMin > r.RowCountMax {
+			return ErrInvalidRowCountRange
+		}
+	} else if r.RowCount < MinRowCount || r.RowCount > maxRowCount {
+		return fmt.Errorf("%w: must be between %d and %d", ErrInvalidRowCount, MinRowCount, maxRowCount)
+	}
+
+	if r.Temperature != nil && (*r.Temperature < MinTemperature || *r.Temperature > MaxTemperature) {
+		return fmt.Errorf("%w, got %g", ErrInvalidTemperature, *r.Temperature)
+	}
+
+	if r.Reference != nil && (r.Reference.RequestID < 1 || len(r.Reference.Fields) == 0) {
+		return ErrInvalidReference
+	}
+
+	if r.FieldNameLanguage != "" && !isLanguageName(r.FieldNameLanguage) {
+		return ErrInvalidFieldLanguage
+	}
+
+	if r.Locale != "" && !IsValidLocale(r.Locale) {
+		return ErrInvalidLocale
+	}
+
+	if !validFieldPrompts(r.FieldPrompts) {
+		return ErrInvalidFieldPrompts
+	}
+
+	if !validEdgeCases(r.EdgeCases) {
+		return ErrInvalidEdgeCases
+	}
+
+	if !validPatterns(r.Patterns) {
+		return ErrInvalidPatterns
+	}
+
+	if !validSchema(r.Fields) {
+		return ErrInvalidSchema
+	}
+
+	// The schema fixes the field names, they cannot be translated as well
+	if len(r.Fields) > 0 && r.FieldNameLanguage != "" {
+		return fmt.Errorf("%w, and cannot be combined with field_name_language", ErrInvalidSchema)
+	}
+
+	// Reference values only reach the fields the schema generates
+	if len(r.Fields) > 0 && r.Reference != nil {
+		for _, name := range r.Reference.Fields {
+			if !schemaHasField(r.Fields, name) {
+				return fmt.Errorf("%w; field '%s' is not one of the requested fields", ErrInvalidReference, name)
+			}
+		}
 	}
+
 	return nil
 }
 
+// schemaHasField reports whether the schema has a field called name
+func schemaHasField(fields []SchemaField, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validSchema checks the size, names and types of an explicit schema
+func validSchema(fields []SchemaField) bool {
+	if len(fields) > MaxSchemaFields {
+		return false
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if strings.TrimSpace(field.Name) == "" || seen[field.Name] || !IsValidSchemaType(field.Type) {
+			return false
+		}
+		if field.Unique && field.Type == SchemaTypeBoolean {
+			return false
+		}
+		if (field.Min != nil || field.Max != nil) && field.Type != SchemaTypeNumber {
+			return false
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return false
+		}
+		seen[field.Name] = true
+	}
+	return true
+}
+
+// IsValidSchemaType reports whether fieldType is a known schema field type
+func IsValidSchemaType(fieldType string) bool {
+	for _, known := range SchemaTypes {
+		if fieldType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validPatterns bounds the number and length of patterns and checks that they compile
+func validPatterns(patterns map[string]string) bool {
+	if len(patterns) > MaxPatternFields {
+		return false
+	}
+	for field, pattern := range patterns {
+		if strings.TrimSpace(field) == "" || pattern == "" || utf8.RuneCountInString(pattern) > MaxPatternLength {
+			return false
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// validEdgeCases checks field names, probabilities and kinds of edge case rules
+func validEdgeCases(rules map[string]EdgeCaseRule) bool {
+	if len(rules) > MaxEdgeCaseFields {
+		return false
+	}
+	for field, rule := range rules {
+		if strings.TrimSpace(field) == "" || rule.Probability < 0 || rule.Probability > 1 {
+			return false
+		}
+		for _, kind := range rule.Kinds {
+			if !IsValidEdgeCaseKind(kind) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validFieldPrompts bounds the number of prompts and the length of each one
+func validFieldPrompts(prompts map[string]string) bool {
+	if len(prompts) > MaxFieldPrompts {
+		return false
+	}
+	for field, prompt := range prompts {
+		if strings.TrimSpace(field) == "" || strings.TrimSpace(prompt) == "" {
+			return false
+		}
+		if utf8.RuneCountInString(prompt) > MaxFieldPromptLength {
+			return false
+		}
+	}
+	return true
+}
+
+// isLanguageName accepts short names like "German", "pt-BR" or "Simplified Chinese"
+func isLanguageName(value string) bool {
+	if utf8.RuneCountInString(value) > MaxFieldLanguageLength {
+		return false
+	}
+	for _, r := range value {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidLocale reports whether value looks like a BCP 47 locale tag
+func IsValidLocale(value string) bool {
+	return len(value) <= MaxLocaleLength && localePattern.MatchString(value)
+}
+
+// HasRowCountRange reports whether the request asks for a random row count
+func (r *GenerateRequest) HasRowCountRange() bool {
+	return r.RowCountMin != 0 || r.RowCountMax != 0
+}
+
+// ResolveRowCount picks the row count to generate and stores it in RowCount.
+// Requests without a range keep their explicit row count.
+func (r *GenerateRequest) ResolveRowCount(rng *rand.Rand) int {
+	if r.HasRowCountRange() {
+		r.RowCount = r.RowCountMin + rng.Intn(r.RowCountMax-r.RowCountMin+1)
+	}
+	return r.RowCount
+}
+
 type GenerateResponse struct {
 	ID        int64     `json:"id"`
 	Status    string    `json:"status"`
 	Message   string    `json:"message"`
+	RowCount  int       `json:"row_count"`
+	Locale    string    `json:"locale,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -51,11 +468,160 @@ type DataResponse struct {
 	Scenario   string                   `json:"scenario"`
 	Data       []map[string]interface{} `json:"data"`
 	FieldNames []string                 `json:"field_names"`
+	FieldTypes map[string]string        `json:"field_types"` // number, boolean, date, datetime or string
 	RowCount   int                      `json:"row_count"`
 	CreatedAt  time.Time                `json:"created_at"`
 }
 
+// RegenerateRequest is the optional body of the request regenerate endpoint
+type RegenerateRequest struct {
+	// RowCount replaces the original request's row count
+	RowCount *int `json:"row_count,omitempty"`
+}
+
+// InferTypesRequest is the optional body of the infer-types endpoint
+type InferTypesRequest struct {
+	// Overrides replace inferred types for individual fields
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// TemplateExportRequest is the body of the template export endpoint
+type TemplateExportRequest struct {
+	Template  string  `json:"template"`            // executed once per row
+	Header    string  `json:"header,omitempty"`    // executed once before the rows
+	Footer    string  `json:"footer,omitempty"`    // executed once after the rows
+	Separator *string `json:"separator,omitempty"` // between rows, default newline
+	Extension string  `json:"extension,omitempty"` // download file extension, default txt
+}
+
+// CoerceRequest is the body of the column coercion endpoint
+type CoerceRequest struct {
+	// Fields maps field names to string, number or boolean
+	Fields map[string]string `json:"fields"`
+}
+
+// FieldTypesResponse reports the stored column types of a dataset
+type FieldTypesResponse struct {
+	RequestID  int64             `json:"request_id"`
+	FieldTypes map[string]string `json:"field_types"`
+}
+
+// PopularScenario groups requests whose scenarios normalize to the same text
+type PopularScenario struct {
+	ScenarioHash string    `json:"scenario_hash"`
+	Scenario     string    `json:"scenario"` // most recent spelling
+	Requests     int       `json:"requests"`
+	LastUsed     time.Time `json:"last_used"`
+}
+
+/*
+Job tracks multi-step background work such as a bulk regenerate.
+
+Progress is Completed+Failed out of Total; ResultRequestIDs lists the
+generation requests the job created (or, for a bundle export, the ones it
+packed), in the order they finished. Jobs that produce a file link it as
+ResultURL once they complete. Status uses the same values as generation
+requests.
+*/
+type Job struct {
+	ID               int64      `json:"id"`
+	Kind             string     `json:"kind"`
+	Status           string     `json:"status"`
+	Total            int        `json:"total"`
+	Completed        int        `json:"completed"`
+	Failed           int        `json:"failed"`
+	ResultRequestIDs []int64    `json:"result_request_ids"`
+	Error            string     `json:"error,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	ResultURL        string     `json:"result_url,omitempty"`
+}
+
+// Job kinds
+const (
+	JobKindRegenerate = "regenerate" // bulk regenerate endpoint
+	JobKindBundle     = "bundle"     // background bundle export
+)
+
+// ValidationStat summarizes validation failures for one rule
+type ValidationStat struct {
+	Rule              string    `json:"rule"`
+	Count             int       `json:"count"`
+	AvgScenarioLength float64   `json:"avg_scenario_length"`
+	LastSeen          time.Time `json:"last_seen"`
+}
+
+// UsageDay is the token usage of one model on one day
+type UsageDay struct {
+	Date             string  `json:"date"`
+	Model            string  `json:"model"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// UsageTotals sums usage over a reporting period
+type UsageTotals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// UsageSummary is returned by the usage endpoint
+type UsageSummary struct {
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Days   []UsageDay  `json:"days"`
+	Totals UsageTotals `json:"totals"`
+
+	// Models without a known price contribute tokens but no cost
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
 }
+
+// TargetColumn describes one column of an existing table for SQL validation
+type TargetColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`                  // e.g. INTEGER, VARCHAR(255), TIMESTAMP
+	Nullable   bool   `json:"nullable"`              // whether NULL is accepted
+	HasDefault bool   `json:"has_default,omitempty"` // columns with defaults may be omitted
+}
+
+// SQLValidateRequest is the body of the SQL compatibility check
+type SQLValidateRequest struct {
+	Columns []TargetColumn `json:"columns"`
+}
+
+// SQLMismatch is one incompatibility between a dataset and a target table
+type SQLMismatch struct {
+	Field   string `json:"field"`
+	Issue   string `json:"issue"`
+	Detail  string `json:"detail"`
+	Rows    int    `json:"rows,omitempty"`    // rows with an offending value
+	Example string `json:"example,omitempty"` // first offending value
+}
+
+// SQLValidateResponse reports whether a dataset's SQL export fits a table
+type SQLValidateResponse struct {
+	RequestID  int64         `json:"request_id"`
+	Compatible bool          `json:"compatible"`
+	Mismatches []SQLMismatch `json:"mismatches"`
+}
+
+// Issues reported by the SQL compatibility check
+const (
+	SQLIssueUnknownColumn   = "unknown_column"   // dataset field has no target column
+	SQLIssueMissingRequired = "missing_required" // NOT NULL column without default not in the dataset
+	SQLIssueNotNull         = "not_null"         // null values for a NOT NULL column
+	SQLIssueType            = "type_mismatch"    // values not assignable to the column type
+	SQLIssueTooLong         = "too_long"         // strings longer than VARCHAR(n)/CHAR(n)
+)
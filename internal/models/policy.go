@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+ScenarioPolicy restricts which topics may be generated.
+
+Patterns are matched case-insensitively against whole words of the scenario.
+A pattern may be a phrase ("credit card") and may use simple wildcards:
+'*' matches any run of letters or digits and '?' exactly one, so "financ*"
+matches "finance" and "financial".
+
+Deny patterns always win. When allow patterns are configured, the scenario
+must match at least one of them.
+*/
+type ScenarioPolicy struct {
+	allow []policyPattern
+	deny  []policyPattern
+}
+
+type policyPattern struct {
+	text string
+	re   *regexp.Regexp
+}
+
+// NewScenarioPolicy compiles allow and deny patterns; empty lists disable the check
+func NewScenarioPolicy(allow, deny []string) *ScenarioPolicy {
+	return &ScenarioPolicy{
+		allow: compilePolicyPatterns(allow),
+		deny:  compilePolicyPatterns(deny),
+	}
+}
+
+// Check returns an error wrapping ErrScenarioNotAllowed when the scenario
+// violates the policy. A nil policy allows everything.
+func (p *ScenarioPolicy) Check(scenario string) error {
+	if p == nil {
+		return nil
+	}
+
+	for _, pattern := range p.deny {
+		if pattern.re.MatchString(scenario) {
+			return fmt.Errorf("%w: scenarios about '%s' are not permitted", ErrScenarioNotAllowed, pattern.text)
+		}
+	}
+
+	if len(p.allow) == 0 {
+		return nil
+	}
+
+	for _, pattern := range p.allow {
+		if pattern.re.MatchString(scenario) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: scenario must be about one of the permitted topics", ErrScenarioNotAllowed)
+}
+
+func compilePolicyPatterns(patterns []string) []policyPattern {
+	compiled := make([]policyPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		compiled = append(compiled, policyPattern{text: pattern, re: policyRegexp(pattern)})
+	}
+	return compiled
+}
+
+// policyRegexp turns a wildcard pattern into a case-insensitive whole-word regexp
+func policyRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString(`(?i)(^|[^\pL\pN])`)
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(`[\pL\pN]*`)
+		case '?':
+			b.WriteString(`[\pL\pN]`)
+		case ' ':
+			b.WriteString(`\s+`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString(`($|[^\pL\pN])`)
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScenarioPolicy_Deny tests rejecting denied topics
+func TestScenarioPolicy_Deny(t *testing.T) {
+	policy := NewScenarioPolicy(nil, []string{"financ*", "medical", "credit card", "ssn?"})
+
+	allowed := []string{
+		"10 e-commerce products with prices",
+		"Users with contact info",
+		"Biomedical research labs", // whole words only
+	}
+	for _, scenario := range allowed {
+		assert.NoError(t, policy.Check(scenario), scenario)
+	}
+
+	denied := []string{
+		"Financial transactions for a bank",
+		"Patient MEDICAL records",
+		"Customers with Credit  Card numbers",
+		"Employees with ssns",
+		"finance",
+	}
+	for _, scenario := range denied {
+		assert.ErrorIs(t, policy.Check(scenario), ErrScenarioNotAllowed, scenario)
+	}
+}
+
+// TestScenarioPolicy_Allow tests restricting generation to allowed topics
+func TestScenarioPolicy_Allow(t *testing.T) {
+	policy := NewScenarioPolicy([]string{"product*", "user*"}, []string{"medical"})
+
+	assert.NoError(t, policy.Check("Products with names and prices"))
+	assert.NoError(t, policy.Check("A user directory"))
+	assert.ErrorIs(t, policy.Check("Weather observations"), ErrScenarioNotAllowed, "Not on the allowlist")
+	assert.ErrorIs(t, policy.Check("Medical products"), ErrScenarioNotAllowed, "Deny wins over allow")
+}
+
+// TestScenarioPolicy_Unrestricted tests that empty and nil policies allow everything
+func TestScenarioPolicy_Unrestricted(t *testing.T) {
+	assert.NoError(t, NewScenarioPolicy(nil, []string{" ", ""}).Check("anything"))
+
+	var policy *ScenarioPolicy
+	assert.NoError(t, policy.Check("anything"))
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+/*
+NormalizeScenario returns the canonical form of a scenario used to group
+similar requests: lowercased, trimmed, with runs of whitespace (including
+tabs, newlines and non-breaking spaces) collapsed to a single space.
+
+Punctuation is kept, so "users" and "users!" stay distinct.
+*/
+func NormalizeScenario(scenario string) string {
+	return strings.Join(strings.Fields(strings.ToLower(scenario)), " ")
+}
+
+// ScenarioHash returns the hex SHA-256 of the normalized scenario
+func ScenarioHash(scenario string) string {
+	sum := sha256.Sum256([]byte(NormalizeScenario(scenario)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeScenario tests canonicalization of scenario text
+func TestNormalizeScenario(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Lowercase", "USERS", "users"},
+		{"Trim", "  users \n", "users"},
+		{"Collapse whitespace", "users  with\t\tcontact\ninfo", "users with contact info"},
+		{"Non-breaking space", "users with emails", "users with emails"},
+		{"Punctuation kept", "Users, with e-mails!", "users, with e-mails!"},
+		{"Unicode lowercase", "ÉTUDIANTS À PARIS", "étudiants à paris"},
+		{"Non-Latin script", "  Пользователи  ", "пользователи"},
+		{"Empty", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeScenario(tt.input))
+		})
+	}
+}
+
+// TestScenarioHash tests that similar scenarios share a hash
+func TestScenarioHash(t *testing.T) {
+	hash := ScenarioHash("Users")
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, ScenarioHash("users "))
+	assert.Equal(t, hash, ScenarioHash("  USERS"))
+	assert.NotEqual(t, hash, ScenarioHash("users."))
+	assert.NotEqual(t, hash, ScenarioHash("user"))
+}
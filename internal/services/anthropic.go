@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultAnthropicModel is used when no Anthropic model is configured
+const DefaultAnthropicModel = "claude-3-5-haiku-latest"
+
+const (
+	anthropicMessagesURL = "https://api.anthropic.com/v1/messages"
+	anthropicVersion     = "2023-06-01"
+)
+
+/*
+AnthropicService generates data with Anthropic's Messages API
+(GENERATOR_PROVIDER=anthropic).
+
+It runs the same pipeline as the OpenAI service, with the same prompts,
+chunking, per-field prompts, retries and parsing, over a client that
+translates each chat completion into a Messages call. Request models name
+OpenAI models, so they are ignored and the configured model is always used.
+*/
+type AnthropicService struct {
+	*OpenAIService
+}
+
+// NewAnthropicService creates a new Anthropic service; opts.DefaultModel
+// is the Anthropic model (DefaultAnthropicModel when empty), and Mock and
+// LogPromptCache do not apply
+func NewAnthropicService(apiKey string, opts OpenAIOptions) *AnthropicService {
+	if opts.DefaultModel == "" {
+		opts.DefaultModel = DefaultAnthropicModel
+	}
+
+	opts.Mock = nil
+	opts.LogPromptCache = false
+	service := NewOpenAIService("", opts)
+	service.client = &anthropicClient{apiKey: apiKey, url: anthropicMessagesURL, http: http.DefaultClient}
+	service.fixedModel = true
+
+	return &AnthropicService{OpenAIService: service}
+}
+
+// anthropicClient implements chatClient on top of the Messages API
+type anthropicClient struct {
+	apiKey string
+	url    string
+	http   *http.Client
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+}
+
+type anthropicResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type anthropicError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+/*
+CreateChatCompletion sends a chat completion as a Messages call.
+
+System messages become the system prompt. Anthropic accepts temperatures up
+to 1 only, so higher ones are capped, and it has no JSON mode or seed; the
+prompts already ask for JSON only. Error responses are returned as
+*openai.APIError with the HTTP status, so rate limits (429) and overloads
+(529) are retried like OpenAI's.
+*/
+func (c *anthropicClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	body := anthropicRequest{
+		Model:       request.Model,
+		MaxTokens:   request.MaxTokens,
+		Temperature: min(request.Temperature, 1),
+	}
+
+	var system []string
+	for _, message := range request.Messages {
+		if message.Role == openai.ChatMessageRoleSystem {
+			system = append(system, message.Content)
+			continue
+		}
+		body.Messages = append(body.Messages, anthropicMessage{Role: message.Role, Content: message.Content})
+	}
+	body.System = strings.Join(system, "\n\n")
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("x-api-key", c.apiKey)
+	httpRequest.Header.Set("anthropic-version", anthropicVersion)
+
+	httpResponse, err := c.http.Do(httpRequest)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	defer httpResponse.Body.Close()
+
+	raw, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	if httpResponse.StatusCode != http.StatusOK {
+		apiErr := &openai.APIError{HTTPStatusCode: httpResponse.StatusCode, Message: strings.TrimSpace(string(raw))}
+		var errBody anthropicError
+		if json.Unmarshal(raw, &errBody) == nil && errBody.Error.Message != "" {
+			apiErr.Type = errBody.Error.Type
+			apiErr.Message = errBody.Error.Message
+		}
+		return openai.ChatCompletionResponse{}, apiErr
+	}
+
+	var resp anthropicResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("failed to decode Anthropic response: %w", err)
+	}
+
+	return resp.chatCompletion(), nil
+}
+
+// chatCompletion converts a Messages response into a single-choice chat completion
+func (r anthropicResponse) chatCompletion() openai.ChatCompletionResponse {
+	var text strings.Builder
+	for _, block := range r.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	finish := openai.FinishReasonStop
+	switch r.StopReason {
+	case "max_tokens":
+		finish = openai.FinishReasonLength
+	case "refusal":
+		finish = openai.FinishReasonContentFilter
+	}
+
+	return openai.ChatCompletionResponse{
+		ID:    r.ID,
+		Model: r.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: stripCodeFence(text.String())},
+			FinishReason: finish,
+		}},
+		Usage: openai.Usage{
+			PromptTokens:     r.Usage.InputTokens,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      r.Usage.InputTokens + r.Usage.OutputTokens,
+		},
+	}
+}
+
+// stripCodeFence removes a markdown code fence around a whole response
+// ("```json ... ```"), which models without a JSON mode like to add
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return content
+	}
+
+	trimmed = strings.TrimSuffix(trimmed, "```")
+	if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+		return strings.TrimSpace(trimmed[newline+1:])
+	}
+	return content
+}
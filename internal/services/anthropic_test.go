@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anthropicMessageResponse is a Messages API response as recorded from the API
+const anthropicMessageResponse = `{
+  "id": "msg_01XFDUDYJgAACzvnptvVoYEL",
+  "type": "message",
+  "role": "assistant",
+  "model": "claude-3-5-haiku-20241022",
+  "content": [
+    {
+      "type": "text",
+      "text": "{\"fields\": [\"id\", \"name\", \"email\"], \"data\": [{\"id\": 1, \"name\": \"Ada Lovelace\", \"email\": \"ada@example.com\"}, {\"id\": 2, \"name\": \"Alan Turing\", \"email\": \"alan@example.com\"}]}"
+    }
+  ],
+  "stop_reason": "end_turn",
+  "stop_sequence": null,
+  "usage": {"input_tokens": 412, "output_tokens": 68}
+}`
+
+// newAnthropicTestService returns an Anthropic service talking to handler
+func newAnthropicTestService(t *testing.T, handler http.HandlerFunc) *AnthropicService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	svc := NewAnthropicService("test-key", OpenAIOptions{})
+	svc.client = &anthropicClient{apiKey: "test-key", url: server.URL, http: server.Client()}
+	svc.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return svc
+}
+
+// TestAnthropicService_GenerateMockData tests the Messages API request and response parsing
+func TestAnthropicService_GenerateMockData(t *testing.T) {
+	ctx := context.Background()
+
+	var request anthropicRequest
+	var header http.Header
+	svc := newAnthropicTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(anthropicMessageResponse))
+	})
+
+	temperature := 1.5
+	data, fields, err := svc.GenerateMockData(ctx, "users", 2, GenerateOptions{Model: "gpt-4o", Temperature: &temperature})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"id", "name", "email"}, fields)
+	require.Len(t, data, 2)
+	assert.Equal(t, "Ada Lovelace", data[0]["name"])
+	assert.Equal(t, "alan@example.com", data[1]["email"])
+
+	assert.Equal(t, "test-key", header.Get("x-api-key"))
+	assert.Equal(t, anthropicVersion, header.Get("anthropic-version"))
+
+	assert.Equal(t, DefaultAnthropicModel, request.Model, "Request models name OpenAI models and are ignored")
+	assert.Contains(t, request.System, systemPrompt)
+	assert.Contains(t, request.System, generationInstructions)
+	require.Len(t, request.Messages, 1, "System messages are not sent as messages")
+	assert.Equal(t, "user", request.Messages[0].Role)
+	assert.Contains(t, request.Messages[0].Content, `Generate 2 rows`)
+	assert.Equal(t, 4000, request.MaxTokens)
+	assert.Equal(t, float32(1), request.Temperature, "Temperatures are capped at Anthropic's maximum")
+}
+
+// TestAnthropicClient_Responses tests mapping stop reasons, errors and code fences
+func TestAnthropicClient_Responses(t *testing.T) {
+	ctx := context.Background()
+
+	respond := func(status int, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}
+	}
+	message := func(text, stopReason string) string {
+		content, _ := json.Marshal(text)
+		return `{"id": "msg_1", "type": "message", "role": "assistant", "content": [{"type": "text", "text": ` + string(content) + `}], "stop_reason": "` + stopReason + `", "usage": {"input_tokens": 10, "output_tokens": 5}}`
+	}
+
+	t.Run("Fenced JSON", func(t *testing.T) {
+		svc := newAnthropicTestService(t, respond(http.StatusOK, message("```json\n{\"fields\": [\"id\"], \"data\": [{\"id\": 1}]}\n```", "end_turn")))
+		data, fields, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id"}, fields)
+		assert.Len(t, data, 1)
+	})
+
+	t.Run("Max tokens", func(t *testing.T) {
+		svc := newAnthropicTestService(t, respond(http.StatusOK, message(`{"fields": ["id"], "data": [{"id"`, "max_tokens")))
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrResponseTruncated)
+	})
+
+	t.Run("Refusal", func(t *testing.T) {
+		svc := newAnthropicTestService(t, respond(http.StatusOK, message("", "refusal")))
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrContentFiltered)
+	})
+
+	t.Run("Overloaded is retried", func(t *testing.T) {
+		calls := 0
+		svc := newAnthropicTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				respond(529, `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`)(w, r)
+				return
+			}
+			respond(http.StatusOK, message(`{"fields": ["id"], "data": [{"id": 1}]}`, "end_turn"))(w, r)
+		})
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Errors keep the API message", func(t *testing.T) {
+		svc := newAnthropicTestService(t, respond(http.StatusUnauthorized, `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`))
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+
+		var apiErr *openai.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.HTTPStatusCode)
+		assert.Equal(t, "authentication_error", apiErr.Type)
+		assert.Equal(t, "invalid x-api-key", apiErr.Message)
+	})
+}
+
+// TestStripCodeFence tests removing markdown fences around responses
+func TestStripCodeFence(t *testing.T) {
+	assert.Equal(t, `{"a": 1}`, stripCodeFence("```json\n{\"a\": 1}\n```"))
+	assert.Equal(t, `{"a": 1}`, stripCodeFence("  ```\n{\"a\": 1}```  "))
+	assert.Equal(t, "{\"a\": \"```\"}", stripCodeFence("{\"a\": \"```\"}"))
+	assert.Equal(t, "```", stripCodeFence("```"))
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientConfig_Azure tests building the client for an Azure deployment
+func TestClientConfig_Azure(t *testing.T) {
+	config := clientConfig("key", OpenAIOptions{})
+	assert.Equal(t, openai.APITypeOpenAI, config.APIType)
+
+	azure := &AzureOptions{Endpoint: "https://my-resource.openai.azure.com/", Deployment: "mockdata-gpt4o"}
+	config = clientConfig("key", OpenAIOptions{Azure: azure})
+	assert.Equal(t, openai.APITypeAzure, config.APIType)
+	assert.Equal(t, "https://my-resource.openai.azure.com", config.BaseURL)
+	assert.Equal(t, DefaultAzureAPIVersion, config.APIVersion)
+	assert.Equal(t, "mockdata-gpt4o", config.GetAzureDeploymentByModel("gpt-4o"), "Every model maps to the deployment")
+	assert.Equal(t, "mockdata-gpt4o", config.GetAzureDeploymentByModel("gpt-3.5-turbo"))
+
+	azure.APIVersion = "2024-06-01"
+	assert.Equal(t, "2024-06-01", clientConfig("key", OpenAIOptions{Azure: azure}).APIVersion)
+}
+
+// TestNewOpenAIService_Azure tests that calls reach the deployment URL
+func TestNewOpenAIService_Azure(t *testing.T) {
+	var path, version, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, version, key = r.URL.Path, r.URL.Query().Get("api-version"), r.Header.Get("api-key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"fields\": [\"id\"], \"data\": [{\"id\": 1}]}"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	svc := NewOpenAIService("azure-key", OpenAIOptions{
+		DefaultModel: "gpt-4o",
+		Azure:        &AzureOptions{Endpoint: server.URL, Deployment: "mockdata-gpt4o", APIVersion: "2024-06-01"},
+	})
+	_, _, err := svc.GenerateMockData(context.Background(), "ids", 1, GenerateOptions{Model: "gpt-4"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/openai/deployments/mockdata-gpt4o/chat/completions", path)
+	assert.Equal(t, "2024-06-01", version)
+	assert.Equal(t, "azure-key", key)
+	assert.Equal(t, "gpt-4o", svc.modelFor(GenerateOptions{Model: "gpt-4"}), "Request models are ignored for a fixed deployment")
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// BundleFile is one file of a zip bundle, rendered on demand
+type BundleFile struct {
+	Name   string
+	Render func() ([]byte, error)
+}
+
+/*
+ExportFormats exports one dataset in each of the given formats as a zip
+archive, with a file per format named <baseName>.<extension> in the order
+given. Formats are rendered in parallel like bundles.
+*/
+func (s *ExportService) ExportFormats(formats []string, data []map[string]interface{}, fieldNames []string, tableName, baseName string, concurrency int) ([]byte, error) {
+	files := make([]BundleFile, len(formats))
+
+	for i, format := range formats {
+		format := format
+		extension, err := FormatExtension(format)
+		if err != nil {
+			return nil, err
+		}
+
+		files[i] = BundleFile{
+			Name: fmt.Sprintf("%s.%s", baseName, extension),
+			Render: func() ([]byte, error) {
+				result, err := s.Export(format, data, fieldNames, tableName)
+				if err != nil {
+					return nil, err
+				}
+				return result.Data, nil
+			},
+		}
+	}
+
+	return BuildZip(files, concurrency)
+}
+
+// ZipFormats are the formats included in zip exports, in archive order
+var ZipFormats = []string{"json", "csv", "markdown", "sql"}
+
+// DefaultArchiveName is the file base name inside zip exports when none is set
+const DefaultArchiveName = "mockdata"
+
+/*
+ToZip exports data in each of ZipFormats as one zip archive, with files
+named <baseName>.<extension> (DefaultArchiveName when empty).
+
+A format that fails is left out and its error listed in an errors.txt entry,
+so one broken format does not cost the others. Only when every format fails
+is the first error returned.
+*/
+func (s *ExportService) ToZip(data []map[string]interface{}, fieldNames []string, tableName, baseName string) ([]byte, error) {
+	if baseName == "" {
+		baseName = DefaultArchiveName
+	}
+
+	files := []BundleFile{}
+	var failures []string
+	var firstErr error
+
+	for _, format := range ZipFormats {
+		result, err := s.Export(format, data, fieldNames, tableName)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", format, err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		content := result.Data
+		files = append(files, BundleFile{
+			Name:   fmt.Sprintf("%s.%s", baseName, result.Extension),
+			Render: func() ([]byte, error) { return content, nil },
+		})
+	}
+
+	if len(files) == 0 {
+		return nil, firstErr
+	}
+
+	if len(failures) > 0 {
+		report := []byte(strings.Join(failures, "\n") + "\n")
+		files = append(files, BundleFile{
+			Name:   "errors.txt",
+			Render: func() ([]byte, error) { return report, nil },
+		})
+	}
+
+	return BuildZip(files, 1)
+}
+
+/*
+BuildZip renders the files with at most concurrency workers and packs them
+into a zip archive.
+
+Rendering (the expensive part: loading and serializing a dataset) runs in
+parallel, but entries are written to the archive one at a time in the order
+given, so the result is byte-for-byte identical for any concurrency.
+The first render error (in file order) aborts the bundle.
+*/
+func BuildZip(files []BundleFile, concurrency int) ([]byte, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	contents := make([][]byte, len(files))
+	errs := make([]error, len(files))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, file BundleFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			contents[i], errs[i] = file.Render()
+		}(i, file)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files[i].Name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for i, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to zip: %w", file.Name, err)
+		}
+		if _, err := writer.Write(contents[i]); err != nil {
+			return nil, fmt.Errorf("failed to write %s to zip: %w", file.Name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish zip: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
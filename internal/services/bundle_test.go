@@ -0,0 +1,186 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bundleFiles(n int) []BundleFile {
+	service := NewExportService()
+	files := make([]BundleFile, n)
+
+	for i := range files {
+		i := i
+		files[i] = BundleFile{
+			Name: fmt.Sprintf("mockdata-%d.csv", i+1),
+			Render: func() ([]byte, error) {
+				// Finish out of order to exercise the ordering guarantee
+				time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+				data := []map[string]interface{}{{"id": float64(i + 1), "name": fmt.Sprintf("user %d", i+1)}}
+				return service.ToCSV(data, []string{"id", "name"})
+			},
+		}
+	}
+	return files
+}
+
+// TestBuildZip tests that concurrent bundles match the sequential one
+func TestBuildZip(t *testing.T) {
+	files := bundleFiles(12)
+
+	sequential, err := BuildZip(files, 1)
+	require.NoError(t, err)
+
+	for _, concurrency := range []int{2, 4, 16} {
+		concurrent, err := BuildZip(files, concurrency)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(sequential, concurrent), "concurrency %d should produce identical archives", concurrency)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(sequential), int64(len(sequential)))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 12)
+
+	for i, file := range reader.File {
+		assert.Equal(t, fmt.Sprintf("mockdata-%d.csv", i+1), file.Name, "Entries keep the requested order")
+	}
+
+	rc, err := reader.File[2].Open()
+	require.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n3,user 3\n", string(content))
+}
+
+// TestBuildZip_Error tests that a failing file aborts the bundle
+func TestBuildZip_Error(t *testing.T) {
+	files := bundleFiles(3)
+	files[1].Render = func() ([]byte, error) { return nil, ErrNoData }
+
+	_, err := BuildZip(files, 2)
+	assert.ErrorIs(t, err, ErrNoData)
+	assert.Contains(t, err.Error(), "mockdata-2.csv")
+
+	_, err = BuildZip([]BundleFile{{Name: "x", Render: func() ([]byte, error) { return nil, errors.New("boom") }}}, 0)
+	assert.Error(t, err, "Zero concurrency falls back to sequential")
+}
+
+// TestParseFormatList tests splitting format lists into supported and unsupported names
+func TestParseFormatList(t *testing.T) {
+	supported, unsupported := ParseFormatList("csv, json,pdf,,md,markdown,csv,pdf,docx,zip")
+	assert.Equal(t, []string{"csv", "json", "md"}, supported)
+	assert.Equal(t, []string{"pdf", "docx", "zip"}, unsupported)
+
+	supported, unsupported = ParseFormatList("")
+	assert.Empty(t, supported)
+	assert.Empty(t, unsupported)
+}
+
+// TestExportService_ExportFormats tests the per-format archive of one dataset
+func TestExportService_ExportFormats(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "Alice"},
+		{"id": float64(2), "name": "Bob"},
+	}
+	fieldNames := []string{"id", "name"}
+	service := NewExportService()
+
+	archive, err := service.ExportFormats([]string{"csv", "json", "sql"}, data, fieldNames, "people", "mockdata-7", 2)
+	require.NoError(t, err)
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 3)
+
+	for i, format := range []string{"csv", "json", "sql"} {
+		file := reader.File[i]
+		assert.Equal(t, "mockdata-7."+format, file.Name)
+
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+
+		// Every entry matches the single-format export
+		expected, err := service.Export(format, data, fieldNames, "people")
+		require.NoError(t, err)
+		assert.Equal(t, string(expected.Data), string(content), format)
+	}
+
+	_, err = service.ExportFormats([]string{"pdf"}, data, fieldNames, "people", "mockdata-7", 1)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+// readZip returns the entries of a zip archive by name
+func readZip(t *testing.T, archive []byte) (map[string]string, []string) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	names := []string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+
+		contents[file.Name] = string(content)
+		names = append(names, file.Name)
+	}
+	return contents, names
+}
+
+// TestExportService_ToZip tests the all-formats zip export
+func TestExportService_ToZip(t *testing.T) {
+	data := []map[string]interface{}{{"id": float64(1), "name": "Alice"}}
+	fieldNames := []string{"id", "name"}
+	service := NewExportService()
+
+	archive, err := service.ToZip(data, fieldNames, "people", "mockdata-7")
+	require.NoError(t, err)
+
+	contents, names := readZip(t, archive)
+	assert.Equal(t, []string{"mockdata-7.json", "mockdata-7.csv", "mockdata-7.md", "mockdata-7.sql"}, names)
+	assert.Equal(t, "id,name\n1,Alice\n", contents["mockdata-7.csv"])
+	assert.Contains(t, contents["mockdata-7.sql"], "INSERT INTO people")
+
+	// Through Export, the base name comes from the options
+	result, err := service.Export("zip", data, fieldNames, "people")
+	require.NoError(t, err)
+	assert.Equal(t, "application/zip", result.ContentType)
+	_, names = readZip(t, result.Data)
+	assert.Contains(t, names, "mockdata.json")
+}
+
+// TestExportService_ToZip_PartialFailure tests that failing formats are reported, not fatal
+func TestExportService_ToZip_PartialFailure(t *testing.T) {
+	data := []map[string]interface{}{{"id": float64(1)}, {"id": float64(1)}}
+
+	// A duplicate primary key only breaks the SQL export
+	opts := DefaultExportOptions()
+	opts.PrimaryKey = "id"
+	archive, err := NewExportService().WithOptions(opts).ToZip(data, []string{"id"}, "items", "")
+	require.NoError(t, err)
+
+	contents, names := readZip(t, archive)
+	assert.Equal(t, []string{"mockdata.json", "mockdata.csv", "mockdata.md", "errors.txt"}, names)
+	assert.Contains(t, contents["errors.txt"], "sql: ")
+
+	// Every format rejects rows with unlisted keys in strict mode
+	opts = DefaultExportOptions()
+	opts.StrictFields = StrictFieldsError
+	_, err = NewExportService().WithOptions(opts).ToZip([]map[string]interface{}{{"id": float64(1), "extra": "x"}}, []string{"id"}, "items", "")
+	assert.ErrorIs(t, err, ErrFieldMismatch)
+}
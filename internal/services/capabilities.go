@@ -0,0 +1,73 @@
+package services
+
+import "strings"
+
+// requestModels are the models a generation request may choose; others are
+// only used through OPENAI_MODEL or the admin regenerate endpoint
+var requestModels = []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o", "gpt-4o-mini"}
+
+// Capabilities lists optional API features a chat model accepts
+type Capabilities struct {
+	JSONMode bool `json:"json_mode"` // response_format {"type": "json_object"}
+	Tools    bool `json:"tools"`     // tool / function calling
+	Seed     bool `json:"seed"`      // reproducible sampling via seed
+}
+
+/*
+modelCapabilities maps model names and name prefixes to their features.
+
+Dated snapshots are listed explicitly where they differ from their family,
+e.g. gpt-3.5-turbo-0613 predates JSON mode and seed.
+*/
+var modelCapabilities = map[string]Capabilities{
+	"gpt-4o":                 {JSONMode: true, Tools: true, Seed: true},
+	"gpt-4-turbo":            {JSONMode: true, Tools: true, Seed: true},
+	"gpt-4-1106-preview":     {JSONMode: true, Tools: true, Seed: true},
+	"gpt-4-0125-preview":     {JSONMode: true, Tools: true, Seed: true},
+	"gpt-4-vision":           {},
+	"gpt-4":                  {Tools: true},
+	"gpt-3.5-turbo":          {JSONMode: true, Tools: true, Seed: true},
+	"gpt-3.5-turbo-0301":     {},
+	"gpt-3.5-turbo-0613":     {Tools: true},
+	"gpt-3.5-turbo-16k":      {Tools: true},
+	"gpt-3.5-turbo-instruct": {},
+}
+
+/*
+ModelCapabilities returns the features of a model: an exact entry wins,
+otherwise the longest matching prefix ("gpt-4o-mini" uses "gpt-4o").
+Unknown models get no optional features, so requests stay valid.
+*/
+func ModelCapabilities(model string) Capabilities {
+	if caps, ok := modelCapabilities[model]; ok {
+		return caps
+	}
+
+	best := ""
+	for name := range modelCapabilities {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	return modelCapabilities[best]
+}
+
+// KnownModels returns a copy of the model capability table; other models
+// resolve through ModelCapabilities
+func KnownModels() map[string]Capabilities {
+	models := make(map[string]Capabilities, len(modelCapabilities))
+	for name, caps := range modelCapabilities {
+		models[name] = caps
+	}
+	return models
+}
+
+// RequestModels returns the models a generation request may choose
+func RequestModels() []string {
+	return append([]string(nil), requestModels...)
+}
+
+// IsRequestModel reports whether a generation request may use a model
+func IsRequestModel(model string) bool {
+	return containsString(requestModels, model)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestModelCapabilities tests exact and prefix lookups in the capability table
+func TestModelCapabilities(t *testing.T) {
+	all := Capabilities{JSONMode: true, Tools: true, Seed: true}
+
+	tests := map[string]Capabilities{
+		"gpt-3.5-turbo":          all,
+		"gpt-3.5-turbo-0125":     all,
+		"gpt-3.5-turbo-0613":     {Tools: true},
+		"gpt-3.5-turbo-16k-0613": {Tools: true},
+		"gpt-4":                  {Tools: true},
+		"gpt-4-0613":             {Tools: true},
+		"gpt-4-turbo-2024-04-09": all,
+		"gpt-4-vision-preview":   {},
+		"gpt-4o":                 all,
+		"gpt-4o-mini":            all,
+		"gpt-4oops":              {}, // prefixes only match whole name segments
+		"my-finetune":            {},
+	}
+
+	for model, expected := range tests {
+		assert.Equal(t, expected, ModelCapabilities(model), model)
+	}
+}
+
+// TestChatRequest tests that optional parameters follow the model capabilities
+func TestChatRequest(t *testing.T) {
+	seed := 42
+
+	t.Run("Supported model", func(t *testing.T) {
+		request := chatRequest("gpt-4o", "", "prompt", GenerateOptions{Seed: &seed})
+		if assert.NotNil(t, request.ResponseFormat) {
+			assert.Equal(t, openai.ChatCompletionResponseFormatTypeJSONObject, request.ResponseFormat.Type)
+		}
+		assert.Equal(t, &seed, request.Seed)
+	})
+
+	t.Run("Unsupported model", func(t *testing.T) {
+		request := chatRequest("gpt-4-0613", "", "prompt", GenerateOptions{Seed: &seed})
+		assert.Nil(t, request.ResponseFormat)
+		assert.Nil(t, request.Seed)
+	})
+
+	t.Run("No seed requested", func(t *testing.T) {
+		request := chatRequest("gpt-4o", "", "prompt", GenerateOptions{})
+		assert.Nil(t, request.Seed)
+	})
+}
+
+// TestChatRequest_Temperature tests the default, custom and zero temperatures
+func TestChatRequest_Temperature(t *testing.T) {
+	request := chatRequest("gpt-4o", "", "prompt", GenerateOptions{})
+	assert.Equal(t, float32(DefaultTemperature), request.Temperature)
+
+	custom := 1.3
+	request = chatRequest("gpt-4o", "", "prompt", GenerateOptions{Temperature: &custom})
+	assert.Equal(t, float32(1.3), request.Temperature)
+
+	zero := 0.0
+	request = chatRequest("gpt-4o", "", "prompt", GenerateOptions{Temperature: &zero})
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"temperature":1e-45`, "Zero is still sent, not dropped as empty")
+}
+
+// TestIsRequestModel tests the allowlist of models a request may choose
+func TestIsRequestModel(t *testing.T) {
+	for _, model := range []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o", "gpt-4o-mini"} {
+		assert.True(t, IsRequestModel(model), model)
+	}
+	for _, model := range []string{"", "gpt-5", "gpt-4o-2024-05-13", "gpt-3.5-turbo-instruct", "GPT-4O", "my-finetune"} {
+		assert.False(t, IsRequestModel(model), model)
+	}
+
+	models := RequestModels()
+	models[0] = "changed"
+	assert.True(t, IsRequestModel("gpt-3.5-turbo"), "RequestModels returns a copy")
+}
+
+// TestModelFor tests that the request model wins over the configured default
+func TestModelFor(t *testing.T) {
+	rows := idRows(1, 1)
+
+	tests := []struct {
+		name         string
+		defaultModel string
+		model        string
+		expected     string
+	}{
+		{"Built-in default", "", "", openai.GPT3Dot5Turbo},
+		{"Configured default", "gpt-4o-mini", "", "gpt-4o-mini"},
+		{"Request model", "gpt-4o-mini", "gpt-4o", "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &sequenceChatClient{contents: []string{rows}}
+			svc := &OpenAIService{client: client, defaultModel: tt.defaultModel}
+
+			_, _, err := svc.GenerateMockData(context.Background(), "users", 1, GenerateOptions{Model: tt.model})
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.expected}, client.models)
+		})
+	}
+}
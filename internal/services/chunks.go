@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// ChunkSize is the most rows requested in one generation call; larger
+// requests would run into the completion token limit
+const ChunkSize = 50
+
+// DefaultChunkConcurrency is how many chunks are generated in parallel
+// when the service is not configured otherwise
+const DefaultChunkConcurrency = 4
+
+/*
+generateChunks generates the rows after first, which the first chunk
+produced, in chunks of ChunkSize with a bounded number of parallel calls.
+
+Every chunk is asked for exactly the first chunk's fields and continues its
+row numbering, and its rows are conformed to those fields, so the schema
+stays consistent. Chunks keep their order whatever the concurrency. A failed
+chunk cancels the ones not started yet and fails the whole request with the
+errors of all failed chunks.
+*/
+func (s *OpenAIService) generateChunks(ctx context.Context, model, scenario string, first []map[string]interface{}, fieldNames []string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, error) {
+	concurrency := s.chunkConcurrency
+	if concurrency < 1 {
+		concurrency = DefaultChunkConcurrency
+	}
+
+	// The first chunk's row count, not its length, fixes the numbering
+	offset := min(rowCount, ChunkSize)
+	starts := []int{}
+	for start := offset; start < rowCount; start += ChunkSize {
+		starts = append(starts, start)
+	}
+
+	log.Printf("🧱 Generating %d more chunks of up to %d rows (%d in parallel)", len(starts), ChunkSize, concurrency)
+
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks := make([][]map[string]interface{}, len(starts))
+	errs := make([]error, len(starts))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, start := range starts {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i, start int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Chunks after a failure are skipped
+			if chunkCtx.Err() != nil {
+				return
+			}
+
+			chunkOpts := opts
+			chunkOpts.Fields = fieldNames
+			chunkOpts.RowOffset = opts.RowOffset + start
+
+			size := min(ChunkSize, rowCount-start)
+			data, _, err := s.generateChunk(chunkCtx, model, scenario, size, chunkOpts)
+			if err != nil {
+				// Calls cut short by another chunk's failure are not errors of their own
+				if chunkCtx.Err() != nil && ctx.Err() == nil {
+					return
+				}
+				errs[i] = fmt.Errorf("rows %d-%d: %w", start+1, start+size, err)
+				cancel()
+				return
+			}
+
+			for j, row := range data {
+				data[j] = conformRow(row, fieldNames)
+			}
+			chunks[i] = data
+		}(i, start)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	data := first
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	chunkRowCount = regexp.MustCompile(`^Generate (\d+) rows`)
+	chunkOffset   = regexp.MustCompile(`existing dataset of (\d+) rows`)
+)
+
+// chunkChatClient answers generation prompts with the requested number of
+// id rows, numbered after the prompt's offset; safe for parallel calls
+type chunkChatClient struct {
+	failAt int // offset whose chunk fails, 0 for none
+
+	mu      sync.Mutex
+	prompts []string
+	active  int
+	peak    int
+}
+
+func (f *chunkChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	prompt := request.Messages[len(request.Messages)-1].Content
+
+	f.mu.Lock()
+	f.prompts = append(f.prompts, prompt)
+	f.active++
+	f.peak = max(f.peak, f.active)
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.active--
+		f.mu.Unlock()
+	}()
+
+	count, _ := strconv.Atoi(chunkRowCount.FindStringSubmatch(prompt)[1])
+	offset := 0
+	if match := chunkOffset.FindStringSubmatch(prompt); match != nil {
+		offset, _ = strconv.Atoi(match[1])
+	}
+
+	if f.failAt > 0 && offset == f.failAt {
+		return openai.ChatCompletionResponse{}, errors.New("chunk failed")
+	}
+
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: idRows(offset+1, offset+count)},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}, nil
+}
+
+// TestGenerateMockData_Chunks tests splitting large row counts into chunk calls
+func TestGenerateMockData_Chunks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("One call per chunk", func(t *testing.T) {
+		client := &chunkChatClient{}
+		svc := &OpenAIService{client: client, chunkConcurrency: 3}
+
+		data, fields, err := svc.GenerateMockData(ctx, "users", 420, GenerateOptions{})
+		require.NoError(t, err)
+
+		assert.Len(t, client.prompts, 9, "420 rows are 8 full chunks and one of 20")
+		assert.LessOrEqual(t, client.peak, 3, "At most chunkConcurrency calls run at once")
+
+		assert.Equal(t, []string{"id", "name", "extra"}, fields, "The first chunk defines the fields")
+		require.Len(t, data, 420)
+		for i, row := range data {
+			assert.Equal(t, float64(i+1), row["id"], "Chunks are merged in order")
+		}
+		assert.Equal(t, true, data[0]["extra"])
+		assert.Equal(t, map[string]interface{}{"id": float64(420), "name": "user 420", "extra": true}, data[419])
+
+		later := 0
+		for _, prompt := range client.prompts {
+			if strings.Contains(prompt, "existing dataset") {
+				later++
+				assert.Contains(t, prompt, `Use exactly these fields, in this order: ["id","name","extra"].`)
+			}
+		}
+		assert.Equal(t, 8, later, "Every chunk after the first continues the dataset")
+	})
+
+	t.Run("Small requests use a single call", func(t *testing.T) {
+		client := &chunkChatClient{}
+		data, _, err := (&OpenAIService{client: client}).GenerateMockData(ctx, "users", ChunkSize, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Len(t, data, ChunkSize)
+		assert.Len(t, client.prompts, 1)
+	})
+
+	t.Run("Continues an existing offset", func(t *testing.T) {
+		client := &chunkChatClient{}
+		data, _, err := (&OpenAIService{client: client}).GenerateMockData(ctx, "users", 60, GenerateOptions{RowOffset: 100})
+		require.NoError(t, err)
+		require.Len(t, data, 60)
+		assert.Equal(t, float64(101), data[0]["id"])
+		assert.Equal(t, float64(160), data[59]["id"])
+	})
+
+	t.Run("A failed chunk fails the request", func(t *testing.T) {
+		client := &chunkChatClient{failAt: 100}
+		svc := &OpenAIService{client: client, chunkConcurrency: 1}
+
+		_, _, err := svc.GenerateMockData(ctx, "users", 200, GenerateOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rows 101-150")
+		assert.Len(t, client.prompts, 3, "Chunks after the failure are not requested")
+	})
+
+	t.Run("Respects cancellation", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, _, err := (&OpenAIService{client: &chunkChatClient{}}).GenerateMockData(cancelled, "users", 200, GenerateOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Target types accepted by CoerceColumns
+const (
+	CoerceString  = "string"
+	CoerceNumber  = "number"
+	CoerceBoolean = "boolean"
+)
+
+// coerceSQLTypes maps coercion targets to the stored column type
+var coerceSQLTypes = map[string]string{
+	CoerceString:  "TEXT",
+	CoerceNumber:  "NUMERIC",
+	CoerceBoolean: "BOOLEAN",
+}
+
+// CoerceTargets returns the supported coercion target types
+func CoerceTargets() []string {
+	targets := make([]string, 0, len(coerceSQLTypes))
+	for target := range coerceSQLTypes {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// ErrUncoercible is returned when some values cannot be converted
+var ErrUncoercible = errors.New("some values cannot be coerced")
+
+// jsonNumberPattern matches numbers as written in JSON (no hex, Inf or NaN)
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?([eE][+-]?\d+)?$`)
+
+// CoercionFailure is a value that could not be converted
+type CoercionFailure struct {
+	Field string      `json:"field"`
+	Row   int         `json:"row"` // zero-based row index
+	Value interface{} `json:"value"`
+}
+
+// CoercionResult reports what CoerceColumns changed
+type CoercionResult struct {
+	ChangedRows []int             // indexes of rows with at least one changed value
+	FieldTypes  map[string]string // stored column type per coerced field
+	Failures    []CoercionFailure
+}
+
+/*
+CoerceColumns converts whole columns to the given types (field → string,
+number or boolean).
+
+Numbers become strings without trailing zeros and strings become numbers
+when they are JSON numbers; booleans become "true"/"false". Strings
+become booleans from true/false, yes/no, y/n, t/f and 1/0 (any case).
+Nulls stay null, values already of the target type are kept, and nested
+values or other combinations are failures.
+
+Conversion is all or nothing: when any value fails, data is left untouched
+and the result lists every failure with ErrUncoercible.
+*/
+func CoerceColumns(data []map[string]interface{}, fieldNames []string, targets map[string]string) (*CoercionResult, error) {
+	fields := make([]string, 0, len(targets))
+	for field, target := range targets {
+		if !containsString(fieldNames, field) {
+			return nil, fmt.Errorf("%w: '%s' is not a field of this dataset", ErrInvalidExportOption, field)
+		}
+		if _, ok := coerceSQLTypes[target]; !ok {
+			return nil, fmt.Errorf("%w: unknown type '%s' for field '%s' (use string, number or boolean)", ErrInvalidExportOption, target, field)
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	result := &CoercionResult{FieldTypes: make(map[string]string, len(fields))}
+	converted := make([]map[string]interface{}, len(data))
+
+	for i, row := range data {
+		for _, field := range fields {
+			value, present := row[field]
+			if !present || value == nil {
+				continue
+			}
+
+			coerced, ok := coerceValue(value, targets[field])
+			if !ok {
+				result.Failures = append(result.Failures, CoercionFailure{Field: field, Row: i, Value: value})
+				continue
+			}
+			if coerced != value {
+				if converted[i] == nil {
+					converted[i] = map[string]interface{}{}
+				}
+				converted[i][field] = coerced
+			}
+		}
+	}
+
+	if len(result.Failures) > 0 {
+		return result, fmt.Errorf("%w: %d values failed", ErrUncoercible, len(result.Failures))
+	}
+
+	for i, values := range converted {
+		if values == nil {
+			continue
+		}
+		for field, value := range values {
+			data[i][field] = value
+		}
+		result.ChangedRows = append(result.ChangedRows, i)
+	}
+	for _, field := range fields {
+		result.FieldTypes[field] = coerceSQLTypes[targets[field]]
+	}
+
+	return result, nil
+}
+
+// coerceValue converts one non-null value to target
+func coerceValue(value interface{}, target string) (interface{}, bool) {
+	switch target {
+	case CoerceString:
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case float64, json.Number:
+			return formatValue(v), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+
+	case CoerceNumber:
+		switch v := value.(type) {
+		case float64, json.Number:
+			return v, true
+		case string:
+			trimmed := strings.TrimSpace(v)
+			if jsonNumberPattern.MatchString(trimmed) {
+				return json.Number(trimmed), true
+			}
+		}
+
+	case CoerceBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, true
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "y", "t", "1":
+				return true, true
+			case "false", "no", "n", "f", "0":
+				return false, true
+			}
+		}
+	}
+
+	return nil, false
+}
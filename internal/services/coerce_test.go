@@ -0,0 +1,74 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCoerceColumns tests every coercion direction
+func TestCoerceColumns(t *testing.T) {
+	fields := []string{"zip", "price", "active", "note"}
+
+	t.Run("Number to string", func(t *testing.T) {
+		data := []map[string]interface{}{{"zip": float64(12345)}, {"zip": json.Number("02134")}, {"zip": true}, {"zip": nil}}
+		result, err := CoerceColumns(data, fields, map[string]string{"zip": CoerceString})
+		require.NoError(t, err)
+		assert.Equal(t, "12345", data[0]["zip"])
+		assert.Equal(t, "02134", data[1]["zip"])
+		assert.Equal(t, "true", data[2]["zip"])
+		assert.Nil(t, data[3]["zip"])
+		assert.Equal(t, []int{0, 1, 2}, result.ChangedRows)
+		assert.Equal(t, map[string]string{"zip": "TEXT"}, result.FieldTypes)
+	})
+
+	t.Run("String to number", func(t *testing.T) {
+		data := []map[string]interface{}{{"price": " 9.99 "}, {"price": "-1e3"}, {"price": float64(5)}}
+		result, err := CoerceColumns(data, fields, map[string]string{"price": CoerceNumber})
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("9.99"), data[0]["price"])
+		assert.Equal(t, json.Number("-1e3"), data[1]["price"])
+		assert.Equal(t, float64(5), data[2]["price"])
+		assert.Equal(t, []int{0, 1}, result.ChangedRows, "Unchanged rows are not reported")
+	})
+
+	t.Run("String to boolean", func(t *testing.T) {
+		data := []map[string]interface{}{{"active": "Yes"}, {"active": "0"}, {"active": "F"}, {"active": true}}
+		_, err := CoerceColumns(data, fields, map[string]string{"active": CoerceBoolean})
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{true, false, false, true}, []interface{}{data[0]["active"], data[1]["active"], data[2]["active"], data[3]["active"]})
+	})
+
+	t.Run("Uncoercible values leave data untouched", func(t *testing.T) {
+		data := []map[string]interface{}{
+			{"price": "12", "active": "maybe"},
+			{"price": "Inf", "active": "yes"},
+			{"price": "0x10", "active": float64(1)},
+		}
+		result, err := CoerceColumns(data, fields, map[string]string{"price": CoerceNumber, "active": CoerceBoolean})
+		assert.ErrorIs(t, err, ErrUncoercible)
+		assert.Equal(t, []CoercionFailure{
+			{Field: "active", Row: 0, Value: "maybe"},
+			{Field: "price", Row: 1, Value: "Inf"},
+			{Field: "active", Row: 2, Value: float64(1)},
+			{Field: "price", Row: 2, Value: "0x10"},
+		}, result.Failures)
+		assert.Equal(t, "12", data[0]["price"])
+	})
+
+	t.Run("Nested values fail", func(t *testing.T) {
+		data := []map[string]interface{}{{"note": map[string]interface{}{"a": 1}}}
+		_, err := CoerceColumns(data, fields, map[string]string{"note": CoerceString})
+		assert.ErrorIs(t, err, ErrUncoercible)
+	})
+
+	t.Run("Invalid targets", func(t *testing.T) {
+		_, err := CoerceColumns(nil, fields, map[string]string{"missing": CoerceString})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+
+		_, err = CoerceColumns(nil, fields, map[string]string{"zip": "date"})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+	})
+}
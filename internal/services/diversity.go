@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// MinDiversityRows is the smallest dataset whose diversity is judged;
+// ratios over a handful of rows say little
+const MinDiversityRows = 5
+
+// categoricalHints are name tokens of fields whose values are meant to repeat
+var categoricalHints = []string{"status", "type", "category", "kind", "gender", "country", "state", "level", "role", "priority", "tier", "plan", "currency", "department"}
+
+// flagPrefixes are leading name tokens of boolean-like fields (is_active, hasPaid)
+var flagPrefixes = []string{"is", "has"}
+
+// FieldDiversity reports how varied the values of one field are
+type FieldDiversity struct {
+	Field       string  `json:"field"`
+	Distinct    int     `json:"distinct"`
+	NonNull     int     `json:"non_null"`
+	Ratio       float64 `json:"ratio"`       // distinct / non-null values
+	Categorical bool    `json:"categorical"` // repetition is expected
+	Low         bool    `json:"low"`         // below the threshold and not categorical
+}
+
+/*
+MeasureDiversity computes the distinct-value ratio of every field.
+
+Fields are categorical when all values are booleans or the name suggests a
+category (status, type, country, is_...); their repeats are intentional and
+never flagged. Other fields are flagged Low when their ratio is below
+threshold, provided the dataset has at least MinDiversityRows rows.
+*/
+func MeasureDiversity(data []map[string]interface{}, fieldNames []string, threshold float64) []FieldDiversity {
+	metrics := make([]FieldDiversity, 0, len(fieldNames))
+
+	for _, field := range fieldNames {
+		seen := map[string]bool{}
+		nonNull := 0
+		allBool := true
+
+		for _, row := range data {
+			value := row[field]
+			if value == nil {
+				continue
+			}
+			nonNull++
+			if _, ok := value.(bool); !ok {
+				allBool = false
+			}
+			// %T keeps 1 and "1" apart
+			seen[fmt.Sprintf("%T:%s", value, formatValue(value))] = true
+		}
+
+		metric := FieldDiversity{
+			Field:       field,
+			Distinct:    len(seen),
+			NonNull:     nonNull,
+			Categorical: (nonNull > 0 && allBool) || hasCategoricalName(field),
+		}
+		if nonNull > 0 {
+			metric.Ratio = float64(len(seen)) / float64(nonNull)
+		}
+		metric.Low = !metric.Categorical && len(data) >= MinDiversityRows && nonNull > 0 && metric.Ratio < threshold
+
+		metrics = append(metrics, metric)
+	}
+
+	return metrics
+}
+
+// LowDiversityFields returns the names of the flagged fields
+func LowDiversityFields(metrics []FieldDiversity) []string {
+	var fields []string
+	for _, metric := range metrics {
+		if metric.Low {
+			fields = append(fields, metric.Field)
+		}
+	}
+	return fields
+}
+
+// hasCategoricalName reports whether a whole token of the field name is a
+// categorical hint, so user_type and orderStatus match but prototype and
+// estimate do not
+func hasCategoricalName(field string) bool {
+	tokens := nameTokens(field)
+	if len(tokens) == 0 {
+		return false
+	}
+	for _, prefix := range flagPrefixes {
+		if tokens[0] == prefix && len(tokens) > 1 {
+			return true
+		}
+	}
+	for _, token := range tokens {
+		for _, hint := range categoricalHints {
+			if token == hint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameTokens splits a field name into lower-case words at underscores,
+// hyphens, spaces and camelCase boundaries
+func nameTokens(field string) []string {
+	var tokens []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(field)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			// orderStatus and HTTPStatus both split before "Status"
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	return tokens
+}
+
+/*
+DiversifyFields regenerates the given fields of every row with follow-up
+prompts asking for distinct values, the same way per-field prompts are
+filled. It is used for columns that came back too repetitive.
+*/
+func (s *OpenAIService) DiversifyFields(ctx context.Context, scenario string, data []map[string]interface{}, fieldNames, fields []string, opts GenerateOptions) error {
+	opts.FieldPrompts = make(map[string]string, len(fields))
+	for _, field := range fields {
+		opts.FieldPrompts[field] = "Give every record a realistic value that differs from the other records; the current values repeat too often."
+	}
+
+	log.Printf("🔁 Regenerating low-diversity fields: %s", strings.Join(fields, ", "))
+
+	_, err := s.fillFields(ctx, s.modelFor(opts), scenario, data, fieldNames, opts)
+	return err
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMeasureDiversity tests distinct-value ratios and low-diversity flags
+func TestMeasureDiversity(t *testing.T) {
+	data := make([]map[string]interface{}, 10)
+	for i := range data {
+		data[i] = map[string]interface{}{
+			"id":     float64(i + 1),
+			"name":   []string{"Jane", "John"}[i%2],
+			"status": []string{"open", "closed"}[i%2],
+			"active": i%2 == 0,
+			"mixed":  []interface{}{float64(1), "1"}[i%2],
+			"note":   nil,
+		}
+	}
+	fields := []string{"id", "name", "status", "active", "mixed", "note"}
+
+	metrics := MeasureDiversity(data, fields, 0.5)
+	require.Len(t, metrics, len(fields))
+
+	byField := map[string]FieldDiversity{}
+	for _, metric := range metrics {
+		byField[metric.Field] = metric
+	}
+
+	assert.Equal(t, FieldDiversity{Field: "id", Distinct: 10, NonNull: 10, Ratio: 1}, byField["id"])
+	assert.Equal(t, FieldDiversity{Field: "name", Distinct: 2, NonNull: 10, Ratio: 0.2, Low: true}, byField["name"])
+	assert.True(t, byField["status"].Categorical, "Categorical by name")
+	assert.False(t, byField["status"].Low)
+	assert.True(t, byField["active"].Categorical, "Booleans are categorical")
+	assert.Equal(t, 2, byField["mixed"].Distinct, "1 and \"1\" are different values")
+	assert.False(t, byField["note"].Low, "All-null fields are not flagged")
+
+	assert.Equal(t, []string{"name", "mixed"}, LowDiversityFields(metrics))
+
+	// Too few rows to judge
+	assert.Empty(t, LowDiversityFields(MeasureDiversity(data[:MinDiversityRows-1], fields, 0.5)))
+}
+
+// TestHasCategoricalName tests matching whole name tokens only
+func TestHasCategoricalName(t *testing.T) {
+	for _, field := range []string{"status", "user_type", "orderStatus", "HTTPStatus", "Country", "shipping-country", "is_active", "isActive", "hasPaid", "subscription plan"} {
+		assert.True(t, hasCategoricalName(field), field)
+	}
+	for _, field := range []string{"prototype", "estimate", "planet", "roleplay", "description", "this_is", "island", "hash", "is", "email"} {
+		assert.False(t, hasCategoricalName(field), field)
+	}
+
+	assert.Equal(t, []string{"http", "status", "code"}, nameTokens("HTTPStatusCode"))
+	assert.Equal(t, []string{"user", "id2", "type"}, nameTokens("user_id2Type"))
+}
+
+// TestDiversifyFields tests regenerating repetitive columns with a fake client
+func TestDiversifyFields(t *testing.T) {
+	data := []map[string]interface{}{{"id": 1, "name": "Jane"}, {"id": 2, "name": "Jane"}}
+	client := &scriptedChatClient{replies: []string{values("name", 2)}}
+	svc := &OpenAIService{client: client}
+
+	err := svc.DiversifyFields(context.Background(), "people", data, []string{"id", "name"}, []string{"name"}, GenerateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "name1", data[0]["name"])
+	assert.Equal(t, "name2", data[1]["name"])
+	assert.Contains(t, client.prompts[0], fmt.Sprintf("%q", "name"))
+}
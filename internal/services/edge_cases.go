@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// EdgeCaseLongStringLength is the length of injected long_string values
+const EdgeCaseLongStringLength = 1000
+
+/*
+InjectEdgeCases replaces random values with boundary values for QA testing
+and returns how many values were replaced.
+
+Each value of a field with a rule is replaced with the rule's probability by
+one of the rule's kinds that fits the value: empty and long_string for
+strings, zero and negative for numbers, null for anything. Values no kind
+fits are left alone. Rows and fields are visited in a fixed order, so the
+same rng seed always changes the same cells.
+*/
+func InjectEdgeCases(data []map[string]interface{}, rules map[string]models.EdgeCaseRule, rng *rand.Rand) int {
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	injected := 0
+	for _, row := range data {
+		for _, field := range fields {
+			value, ok := row[field]
+			if !ok {
+				continue
+			}
+
+			rule := rules[field]
+			if rule.Probability <= 0 || rng.Float64() >= rule.Probability {
+				continue
+			}
+
+			kinds := edgeCaseKindsFor(value, rule.Kinds)
+			if len(kinds) == 0 {
+				continue
+			}
+
+			row[field] = edgeCaseValue(value, kinds[rng.Intn(len(kinds))])
+			injected++
+		}
+	}
+
+	return injected
+}
+
+// UnconstrainedEdgeCases returns the rules of fields without schema
+// constraints; boundary values would break the constraints of the others
+func UnconstrainedEdgeCases(rules map[string]models.EdgeCaseRule, schema []models.SchemaField) map[string]models.EdgeCaseRule {
+	constrained := make(map[string]bool)
+	for _, field := range schema {
+		if field.Unique || field.Min != nil || field.Max != nil {
+			constrained[field.Name] = true
+		}
+	}
+	if len(constrained) == 0 {
+		return rules
+	}
+
+	kept := make(map[string]models.EdgeCaseRule, len(rules))
+	for field, rule := range rules {
+		if !constrained[field] {
+			kept[field] = rule
+		}
+	}
+	return kept
+}
+
+// edgeCaseKindsFor returns the kinds that fit a value, in a fixed order;
+// allowed limits the result unless it is empty
+func edgeCaseKindsFor(value interface{}, allowed []string) []string {
+	fitting := []string{models.EdgeCaseNull}
+	switch value.(type) {
+	case string:
+		fitting = append(fitting, models.EdgeCaseEmpty, models.EdgeCaseLongString)
+	case float64, json.Number:
+		fitting = append(fitting, models.EdgeCaseZero, models.EdgeCaseNegative)
+	}
+
+	if len(allowed) == 0 {
+		return fitting
+	}
+
+	kinds := []string{}
+	for _, kind := range fitting {
+		if containsString(allowed, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// edgeCaseValue returns the boundary value of a kind, keeping the number type of value
+func edgeCaseValue(value interface{}, kind string) interface{} {
+	switch kind {
+	case models.EdgeCaseEmpty:
+		return ""
+	case models.EdgeCaseLongString:
+		return strings.Repeat("x", EdgeCaseLongStringLength)
+	case models.EdgeCaseZero:
+		if _, ok := value.(json.Number); ok {
+			return json.Number("0")
+		}
+		return float64(0)
+	case models.EdgeCaseNegative:
+		return negativeNumber(value)
+	default:
+		return nil
+	}
+}
+
+// negativeNumber returns -|value|, or -1 for zero
+func negativeNumber(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		text := strings.TrimPrefix(v.String(), "-")
+		if f, err := strconv.ParseFloat(text, 64); err != nil || f == 0 {
+			return json.Number("-1")
+		}
+		return json.Number("-" + text)
+	case float64:
+		if v == 0 {
+			return float64(-1)
+		}
+		return -math.Abs(v)
+	}
+	return value
+}
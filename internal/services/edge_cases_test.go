@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// edgeCaseRows returns n rows with a string, a float and a json.Number field
+func edgeCaseRows(n int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"name": "Alice", "age": float64(30), "balance": json.Number("125.50"), "active": true}
+	}
+	return rows
+}
+
+// TestInjectEdgeCases tests edge case injection with a seeded RNG
+func TestInjectEdgeCases(t *testing.T) {
+	t.Run("Probability one replaces every value with a fitting kind", func(t *testing.T) {
+		data := edgeCaseRows(20)
+		rules := map[string]models.EdgeCaseRule{
+			"name":    {Probability: 1},
+			"age":     {Probability: 1, Kinds: []string{models.EdgeCaseZero, models.EdgeCaseNegative}},
+			"balance": {Probability: 1, Kinds: []string{models.EdgeCaseNegative}},
+			"active":  {Probability: 1, Kinds: []string{models.EdgeCaseEmpty}}, // nothing fits a boolean
+		}
+
+		injected := InjectEdgeCases(data, rules, rand.New(rand.NewSource(1)))
+		assert.Equal(t, 60, injected)
+
+		for _, row := range data {
+			switch name := row["name"].(type) {
+			case nil:
+			case string:
+				assert.True(t, name == "" || name == strings.Repeat("x", EdgeCaseLongStringLength), name)
+			default:
+				t.Errorf("unexpected name %v", name)
+			}
+			assert.Contains(t, []interface{}{float64(0), float64(-30)}, row["age"])
+			assert.Equal(t, json.Number("-125.50"), row["balance"])
+			assert.Equal(t, true, row["active"])
+		}
+	})
+
+	t.Run("Probability zero and unknown fields change nothing", func(t *testing.T) {
+		data := edgeCaseRows(5)
+		rules := map[string]models.EdgeCaseRule{"name": {Probability: 0}, "missing": {Probability: 1}}
+
+		assert.Equal(t, 0, InjectEdgeCases(data, rules, rand.New(rand.NewSource(1))))
+		assert.Equal(t, edgeCaseRows(5), data)
+	})
+
+	t.Run("Same seed, same result", func(t *testing.T) {
+		rules := map[string]models.EdgeCaseRule{"name": {Probability: 0.3}, "age": {Probability: 0.3}}
+
+		first, second := edgeCaseRows(50), edgeCaseRows(50)
+		n := InjectEdgeCases(first, rules, rand.New(rand.NewSource(42)))
+		InjectEdgeCases(second, rules, rand.New(rand.NewSource(42)))
+
+		assert.Equal(t, first, second)
+		assert.Greater(t, n, 0)
+		assert.Less(t, n, 100, "Only some values are replaced")
+	})
+}
+
+// TestUnconstrainedEdgeCases tests dropping the rules of constrained fields
+func TestUnconstrainedEdgeCases(t *testing.T) {
+	min := float64(18)
+	rules := map[string]models.EdgeCaseRule{"name": {Probability: 1}, "age": {Probability: 1}, "email": {Probability: 1}}
+	schema := []models.SchemaField{
+		{Name: "name", Type: models.SchemaTypeString},
+		{Name: "age", Type: models.SchemaTypeNumber, Min: &min},
+		{Name: "email", Type: models.SchemaTypeString, Unique: true},
+	}
+
+	assert.Equal(t, map[string]models.EdgeCaseRule{"name": {Probability: 1}}, UnconstrainedEdgeCases(rules, schema))
+	assert.Equal(t, rules, UnconstrainedEdgeCases(rules, nil))
+}
+
+// TestNegativeNumber tests negative edge values
+func TestNegativeNumber(t *testing.T) {
+	assert.Equal(t, float64(-2.5), negativeNumber(float64(2.5)))
+	assert.Equal(t, float64(-7), negativeNumber(float64(-7)))
+	assert.Equal(t, float64(-1), negativeNumber(float64(0)))
+	assert.Equal(t, json.Number("-42"), negativeNumber(json.Number("42")))
+	assert.Equal(t, json.Number("-42"), negativeNumber(json.Number("-42")))
+	assert.Equal(t, json.Number("-1"), negativeNumber(json.Number("0")))
+}
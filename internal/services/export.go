@@ -5,17 +5,34 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 )
 
-type ExportService struct{}
+type ExportService struct {
+	options ExportOptions
+}
 
 func NewExportService() *ExportService {
-	return &ExportService{}
+	return &ExportService{options: DefaultExportOptions()}
+}
+
+// WithOptions returns a copy of the service that renders values using opts
+func (s *ExportService) WithOptions(opts ExportOptions) *ExportService {
+	return &ExportService{options: opts}
 }
 
 func (s *ExportService) ToJSON(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if replacement, ok := s.options.jsonNull(); ok {
+		data = replaceNulls(data, fieldNames, replacement)
+	}
+
 	// Create a structured response
 	response := map[string]interface{}{
 		"fields": fieldNames,
@@ -23,6 +40,10 @@ func (s *ExportService) ToJSON(data []map[string]interface{}, fieldNames []strin
 		"count":  len(data),
 	}
 
+	if s.options.Metadata != nil {
+		response["_meta"] = s.options.Metadata.forRows(data)
+	}
+
 	jsonData, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
@@ -31,14 +52,44 @@ func (s *ExportService) ToJSON(data []map[string]interface{}, fieldNames []strin
 	return jsonData, nil
 }
 
+// utf8BOM is the UTF-8 byte order mark
+const utf8BOM = "\xEF\xBB\xBF"
+
+// ToCSV exports data as comma-separated values, prefixed with a UTF-8 byte
+// order mark when the BOM option is set
 func (s *ExportService) ToCSV(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	content, err := s.toDelimited(data, fieldNames, ',')
+	if err != nil || !s.options.BOM {
+		return content, err
+	}
+	return append([]byte(utf8BOM), content...), nil
+}
+
+// ToTSV exports data as tab-separated values, quoting fields that contain
+// tabs, newlines or quotes the same way CSV does
+func (s *ExportService) ToTSV(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	return s.toDelimited(data, fieldNames, '\t')
+}
+
+// toDelimited writes a header row and one record per row with the given separator
+func (s *ExportService) toDelimited(data []map[string]interface{}, fieldNames []string, comma rune) ([]byte, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("no data to export")
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create a buffer to write CSV data
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
+	writer.Comma = comma
+
+	if s.options.Metadata != nil {
+		buf.WriteString(s.options.Metadata.forRows(data).commentLines("# "))
+	}
 
 	// Write header row
 	if err := writer.Write(fieldNames); err != nil {
@@ -49,7 +100,7 @@ func (s *ExportService) ToCSV(data []map[string]interface{}, fieldNames []string
 	for _, row := range data {
 		values := make([]string, len(fieldNames))
 		for i, field := range fieldNames {
-			values[i] = formatValue(row[field])
+			values[i] = s.options.formatValue(row[field])
 		}
 
 		if err := writer.Write(values); err != nil {
@@ -84,7 +135,12 @@ Format:
 */
 func (s *ExportService) ToMarkdownTable(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("no data to export")
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
 	}
 
 	var buf bytes.Buffer
@@ -106,7 +162,7 @@ func (s *ExportService) ToMarkdownTable(data []map[string]interface{}, fieldName
 		buf.WriteString("| ")
 		values := make([]string, len(fieldNames))
 		for i, field := range fieldNames {
-			values[i] = formatValue(row[field])
+			values[i] = s.options.formatValue(row[field])
 		}
 		buf.WriteString(strings.Join(values, " | "))
 		buf.WriteString(" |\n")
@@ -116,7 +172,8 @@ func (s *ExportService) ToMarkdownTable(data []map[string]interface{}, fieldName
 }
 
 /*
-ToSQL generates INSERT statements for the data.
+ToSQL generates INSERT statements for the data, or a COPY block when the
+Copy option is set.
 
 CONCEPT: SQL Export
 
@@ -129,25 +186,60 @@ We use a generic table name that can be customized.
 */
 func (s *ExportService) ToSQL(data []map[string]interface{}, fieldNames []string, tableName string) ([]byte, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("no data to export")
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
 	}
 
 	if tableName == "" {
 		tableName = "mock_data"
 	}
 
+	primaryKey, err := resolvePrimaryKey(data, fieldNames, s.options.PrimaryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Upserts replace existing rows that collide on the conflict column,
+	// which the table declares UNIQUE unless it is the primary key
+	conflictColumn, conflictClause := "", ""
+	if s.options.Upsert && !s.options.Copy {
+		conflictColumn = s.options.ConflictColumn
+		if conflictColumn == "" {
+			conflictColumn = primaryKey
+		}
+
+		clause, err := s.options.upsertClause(fieldNames, conflictColumn)
+		if err != nil {
+			return nil, err
+		}
+		conflictClause = " " + clause
+	}
+
 	var buf bytes.Buffer
 
 	// Write CREATE TABLE statement
 	buf.WriteString("-- Generated data\n")
+	if s.options.Metadata != nil {
+		buf.WriteString(s.options.Metadata.forRows(data).commentLines("-- "))
+	}
 	buf.WriteString(fmt.Sprintf("-- Table: %s\n\n", tableName))
+
+	tableName = s.options.quoteTable(tableName)
 	buf.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", tableName))
 
-	// Infer column types from first row
-	firstRow := data[0]
+	colTypes := s.sqlColumnTypes(data, fieldNames)
 	for i, field := range fieldNames {
-		colType := inferSQLType(firstRow[field])
-		buf.WriteString(fmt.Sprintf("  %s %s", field, colType))
+		colType := colTypes[i]
+		buf.WriteString(fmt.Sprintf("  %s %s", s.options.quoteIdent(field), s.options.columnType(colType, field == primaryKey || field == conflictColumn)))
+		if field == primaryKey {
+			buf.WriteString(" PRIMARY KEY")
+		} else if field == conflictColumn {
+			buf.WriteString(" UNIQUE")
+		}
 		if i < len(fieldNames)-1 {
 			buf.WriteString(",")
 		}
@@ -155,33 +247,331 @@ func (s *ExportService) ToSQL(data []map[string]interface{}, fieldNames []string
 	}
 	buf.WriteString(");\n\n")
 
+	if s.options.Copy {
+		if s.options.Upsert {
+			return nil, fmt.Errorf("%w: copy cannot be combined with upsert", ErrInvalidExportOption)
+		}
+		if s.options.SQLMode == SQLModeBatch {
+			return nil, fmt.Errorf("%w: copy cannot be combined with sql_mode=batch", ErrInvalidExportOption)
+		}
+		if s.options.mysql() {
+			return nil, fmt.Errorf("%w: copy is only available for PostgreSQL", ErrInvalidExportOption)
+		}
+		s.writeCopy(&buf, data, fieldNames, colTypes, tableName)
+		return buf.Bytes(), nil
+	}
+
+	if s.options.SQLMode == SQLModeBatch {
+		s.writeBatchInserts(&buf, data, fieldNames, colTypes, tableName, conflictColumn, conflictClause)
+		return buf.Bytes(), nil
+	}
+
 	// Write INSERT statements
+	columns := s.options.quoteIdents(fieldNames)
 	for _, row := range data {
 		buf.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES (",
 			tableName,
-			strings.Join(fieldNames, ", ")))
+			columns))
 
 		values := make([]string, len(fieldNames))
 		for i, field := range fieldNames {
-			values[i] = formatSQLValue(row[field])
+			values[i] = s.options.formatSQLColumnValue(row[field], colTypes[i])
 		}
 
 		buf.WriteString(strings.Join(values, ", "))
-		buf.WriteString(");\n")
+		buf.WriteString(")")
+		buf.WriteString(conflictClause)
+		buf.WriteString(";\n")
 	}
 
 	return buf.Bytes(), nil
 }
 
-// formatValue converts any value to a string for CSV/Markdown
+// SQL insert modes accepted by ExportOptions.SQLMode
+const (
+	SQLModeRow   = ""      // one INSERT per row (historical behavior)
+	SQLModeBatch = "batch" // one INSERT per SQLBatchSize rows
+)
+
+// SQLBatchSize is the number of value tuples per batched INSERT, small
+// enough to stay under statement size limits of common drivers
+const SQLBatchSize = 100
+
+/*
+writeBatchInserts writes multi-row INSERT statements with up to SQLBatchSize
+value tuples each, one tuple per line. Imports run much faster than with one
+statement per row.
+
+PostgreSQL rejects an upsert that touches the same row twice, so with a
+conflict column a statement also ends before a key it already holds; the
+later row then updates the earlier one, as with one statement per row.
+*/
+func (s *ExportService) writeBatchInserts(buf *bytes.Buffer, data []map[string]interface{}, fieldNames, colTypes []string, tableName, conflictColumn, conflictClause string) {
+	columns := s.options.quoteIdents(fieldNames)
+
+	for start := 0; start < len(data); {
+		end := start + SQLBatchSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if conflictColumn != "" {
+			end = start + distinctKeyPrefix(data[start:end], conflictColumn)
+		}
+
+		buf.WriteString(fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", tableName, columns))
+
+		for i, row := range data[start:end] {
+			values := make([]string, len(fieldNames))
+			for j, field := range fieldNames {
+				values[j] = s.options.formatSQLColumnValue(row[field], colTypes[j])
+			}
+
+			buf.WriteString("  (" + strings.Join(values, ", ") + ")")
+			if i < end-start-1 {
+				buf.WriteString(",\n")
+			}
+		}
+
+		buf.WriteString(conflictClause)
+		buf.WriteString(";\n")
+		start = end
+	}
+}
+
+// distinctKeyPrefix returns how many leading rows have distinct values of
+// field; at least one
+func distinctKeyPrefix(rows []map[string]interface{}, field string) int {
+	seen := make(map[string]bool, len(rows))
+	for i, row := range rows {
+		key := fmt.Sprintf("%T:%v", row[field], row[field])
+		if seen[key] {
+			return i
+		}
+		seen[key] = true
+	}
+	return len(rows)
+}
+
+/*
+writeCopy writes a PostgreSQL COPY ... FROM stdin block (text format).
+
+Columns are tab-delimited, one row per line, and the block ends with "\.".
+This loads much faster than individual INSERTs when run through psql.
+*/
+func (s *ExportService) writeCopy(buf *bytes.Buffer, data []map[string]interface{}, fieldNames, colTypes []string, tableName string) {
+	buf.WriteString(fmt.Sprintf("COPY %s (%s) FROM stdin;\n", tableName, quoteIdents(fieldNames)))
+
+	for _, row := range data {
+		values := make([]string, len(fieldNames))
+		for i, field := range fieldNames {
+			values[i] = s.options.formatCopyColumnValue(row[field], colTypes[i])
+		}
+		buf.WriteString(strings.Join(values, "\t"))
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("\\.\n")
+}
+
+/*
+ToPostgresCOPY exports only the COPY ... FROM stdin block of the SQL export,
+for bulk loading into an existing PostgreSQL table with psql. Nulls are
+written as \N (or the null_as replacement) and backslashes, tabs and line
+breaks in values are escaped. The dialect option does not apply.
+*/
+func (s *ExportService) ToPostgresCOPY(data []map[string]interface{}, fieldNames []string, tableName string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if tableName == "" {
+		tableName = DefaultTableName
+	}
+
+	var buf bytes.Buffer
+	s.writeCopy(&buf, data, fieldNames, s.sqlColumnTypes(data, fieldNames), tableName)
+	return buf.Bytes(), nil
+}
+
+// sqlColumnTypes returns the column type of each field: the stored type,
+// or else the type inferred from every row
+func (s *ExportService) sqlColumnTypes(data []map[string]interface{}, fieldNames []string) []string {
+	inferred := InferFieldTypes(data, fieldNames)
+	colTypes := make([]string, len(fieldNames))
+	for i, field := range fieldNames {
+		colType, ok := s.options.FieldTypes[field]
+		if !ok {
+			colType = inferred[field]
+		}
+		colTypes[i] = colType
+	}
+	return colTypes
+}
+
+// textColumn reports whether a column of colType holds text, and so can
+// hold the null_as replacement
+func textColumn(colType string) bool {
+	upper := strings.ToUpper(colType)
+	return strings.Contains(upper, "TEXT") || strings.Contains(upper, "CHAR")
+}
+
+/*
+upsertClause builds the ON CONFLICT clause for an upsert (ON DUPLICATE KEY
+UPDATE for MySQL, which matches on any unique key instead of a column).
+
+Every column except the conflict column is overwritten with the incoming
+value; when there is nothing else to update the row is left as is. Without
+a conflict column there is no key to match on, so the upsert is refused.
+*/
+func (o ExportOptions) upsertClause(fieldNames []string, conflictColumn string) (string, error) {
+	if conflictColumn == "" {
+		return "", fmt.Errorf("%w: upsert needs a key, but no primary key was detected; set pk or conflict", ErrInvalidExportOption)
+	}
+
+	found := false
+	updates := []string{}
+	for _, field := range fieldNames {
+		if field == conflictColumn {
+			found = true
+			continue
+		}
+		column := o.quoteIdent(field)
+		if o.mysql() {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", column, column))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("%w: conflict column '%s' is not a field of this dataset", ErrInvalidExportOption, conflictColumn)
+	}
+
+	if o.mysql() {
+		if len(updates) == 0 {
+			// A no-op assignment keeps the existing row
+			column := o.quoteIdent(conflictColumn)
+			return fmt.Sprintf("ON DUPLICATE KEY UPDATE %s = %s", column, column), nil
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", "), nil
+	}
+
+	if len(updates) == 0 {
+		return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", quoteIdent(conflictColumn)), nil
+	}
+
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", quoteIdent(conflictColumn), strings.Join(updates, ", ")), nil
+}
+
+// simpleIdent matches identifiers that are safe to write unquoted
+var simpleIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+/*
+quoteIdent makes a field name safe to use as a SQL column name.
+
+Plain ASCII names are written as is, so existing exports don't change. Names
+with spaces, punctuation or non-ASCII letters (e.g. localized field names
+like "Straße" or "名前") are double-quoted, with embedded quotes doubled.
+*/
+func quoteIdent(name string) string {
+	if simpleIdent.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteIdents quotes each name and joins them into a column list
+func quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// primaryKeyCandidates are field names treated as a likely primary key
+var primaryKeyCandidates = map[string]bool{"id": true, "uuid": true}
+
+/*
+resolvePrimaryKey picks the primary key column for SQL export.
+
+An explicit key must exist and hold unique, non-null values in every row.
+Without one, the first field named id/uuid that satisfies the same rules is
+used; if none does, the table gets no primary key.
+*/
+func resolvePrimaryKey(data []map[string]interface{}, fieldNames []string, explicit string) (string, error) {
+	if explicit != "" {
+		if !containsString(fieldNames, explicit) {
+			return "", fmt.Errorf("%w: primary key '%s' is not a field of this dataset", ErrInvalidExportOption, explicit)
+		}
+		if !isUniqueNonNull(data, explicit) {
+			return "", fmt.Errorf("%w: primary key '%s' must be unique and non-null in every row", ErrInvalidExportOption, explicit)
+		}
+		return explicit, nil
+	}
+
+	for _, field := range fieldNames {
+		if primaryKeyCandidates[strings.ToLower(field)] && isUniqueNonNull(data, field) {
+			return field, nil
+		}
+	}
+
+	return "", nil
+}
+
+// isUniqueNonNull reports whether field has a distinct, non-null value in every row
+func isUniqueNonNull(data []map[string]interface{}, field string) bool {
+	seen := make(map[string]bool, len(data))
+	for _, row := range data {
+		value, ok := row[field]
+		if !ok || value == nil {
+			return false
+		}
+
+		key := fmt.Sprintf("%T:%v", value, value)
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// formatValue converts any value to a string for CSV/Markdown using the default options
 func formatValue(value any) string {
+	return DefaultExportOptions().formatValue(value)
+}
+
+// formatValue converts any value to a string for CSV/Markdown
+func (o ExportOptions) formatValue(value any) string {
 	if value == nil {
-		return ""
+		return o.textNull()
 	}
 
 	switch v := value.(type) {
 	case string:
 		return v
+	case bool:
+		if v {
+			return o.TrueLabel
+		}
+		return o.FalseLabel
+	case json.Number:
+		return v.String()
 	case float64:
 		// Remove unnecessary decimal places
 		if v == float64(int64(v)) {
@@ -193,40 +583,108 @@ func formatValue(value any) string {
 	}
 }
 
-// formatSQLValue formats a value for SQL INSERT statement
+// formatSQLValue formats a value for SQL INSERT statement using the default options
 func formatSQLValue(value interface{}) string {
+	return DefaultExportOptions().formatSQLValue(value)
+}
+
+// formatSQLValue formats a value for SQL INSERT statement
+func (o ExportOptions) formatSQLValue(value interface{}) string {
 	if value == nil {
-		return "NULL"
+		return o.sqlNull()
 	}
 
 	switch v := value.(type) {
 	case string:
-		// Escape single quotes
-		escaped := strings.ReplaceAll(v, "'", "''")
-		return fmt.Sprintf("'%s'", escaped)
+		// Escape single quotes (and backslashes for MySQL)
+		return o.sqlString(v)
+	case json.Number:
+		return v.String()
 	case float64:
 		if v == float64(int64(v)) {
 			return fmt.Sprintf("%d", int64(v))
 		}
 		return fmt.Sprintf("%v", v)
 	case bool:
+		if o.mysql() {
+			if v {
+				return "1"
+			}
+			return "0"
+		}
 		if v {
 			return "TRUE"
 		}
 		return "FALSE"
 	default:
-		return fmt.Sprintf("'%v'", v)
+		return o.sqlString(fmt.Sprintf("%v", v))
+	}
+}
+
+// formatSQLColumnValue formats a value of a column of colType; nulls stay
+// NULL in non-text columns, which could not parse the null_as replacement
+func (o ExportOptions) formatSQLColumnValue(value interface{}, colType string) string {
+	if value == nil && !textColumn(colType) {
+		return "NULL"
+	}
+	return o.formatSQLValue(value)
+}
+
+// formatCopyColumnValue is formatSQLColumnValue for COPY rows
+func (o ExportOptions) formatCopyColumnValue(value interface{}, colType string) string {
+	if value == nil && !textColumn(colType) {
+		return `\N`
+	}
+	return o.formatCopyValue(value)
+}
+
+// formatCopyValue formats a value for a COPY text-format row
+func (o ExportOptions) formatCopyValue(value interface{}) string {
+	if value == nil {
+		return o.copyNull()
+	}
+
+	switch v := value.(type) {
+	case string:
+		return escapeCopy(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%v", v)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		return escapeCopy(fmt.Sprintf("%v", v))
 	}
 }
 
-// inferSQLType infers SQL column type from a value
+// copyEscaper escapes the characters COPY treats specially in text format
+var copyEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\t", `\t`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// escapeCopy escapes backslashes, tabs and line breaks for COPY text format
+func escapeCopy(value string) string {
+	return copyEscaper.Replace(value)
+}
+
+// inferSQLType infers the SQL column type of a single value
 func inferSQLType(value interface{}) string {
 	if value == nil {
 		return "TEXT"
 	}
 
 	switch value.(type) {
-	case float64:
+	case float64, json.Number:
 		return "NUMERIC"
 	case bool:
 		return "BOOLEAN"
@@ -250,7 +708,7 @@ This makes it easy to:
 - Document supported formats
 */
 func (s *ExportService) GetAvailableFormats() []string {
-	formats := []string{"json", "csv", "markdown", "sql"}
+	formats := []string{"json", "jsonschema", "ndjson", "csv", "markdown", "html", "sql", "copy", "sqlite", "ts", "tsv", "xlsx", "yaml", "zip"}
 	sort.Strings(formats)
 	return formats
 }
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned for unknown export format names
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// ExportResult is a rendered export with the metadata needed to serve it
+type ExportResult struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// exportFormat describes how a rendered format is served
+type exportFormat struct {
+	contentType string
+	extension   string
+}
+
+// exportFormats maps accepted format names ("md" is an alias) to their metadata
+var exportFormats = map[string]exportFormat{
+	"json":       {"application/json", "json"},
+	"ndjson":     {"application/x-ndjson", "ndjson"},
+	"csv":        {"text/csv", "csv"},
+	"markdown":   {"text/markdown", "md"},
+	"md":         {"text/markdown", "md"},
+	"sql":        {"application/sql", "sql"},
+	"tsv":        {"text/tab-separated-values", "tsv"},
+	"html":       {"text/html", "html"},
+	"yaml":       {"application/x-yaml", "yaml"},
+	"xlsx":       {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	"ts":         {"application/typescript", "ts"},
+	"zip":        {"application/zip", "zip"},
+	"sqlite":     {"application/x-sqlite3", "sqlite"},
+	"copy":       {"application/sql", "copy.sql"},
+	"jsonschema": {"application/schema+json", "schema.json"},
+}
+
+// FormatExtension returns the file extension for a format name
+func FormatExtension(format string) (string, error) {
+	info, ok := exportFormats[format]
+	if !ok {
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedFormat, format)
+	}
+	return info.extension, nil
+}
+
+/*
+ParseFormatList splits a comma-separated list of format names into the
+supported and the unsupported ones, both in the order given.
+
+Formats sharing a file extension ("md" and "markdown") are kept once, so
+every supported format maps to a distinct file, and "zip" counts as
+unsupported since archives are not nested.
+*/
+func ParseFormatList(raw string) (supported, unsupported []string) {
+	seen := map[string]bool{}
+
+	for _, format := range strings.Split(raw, ",") {
+		format = strings.TrimSpace(format)
+		if format == "" {
+			continue
+		}
+
+		info, ok := exportFormats[format]
+		if !ok || format == "zip" {
+			if !seen[format] {
+				seen[format] = true
+				unsupported = append(unsupported, format)
+			}
+			continue
+		}
+
+		if !seen[info.extension] {
+			seen[info.extension] = true
+			supported = append(supported, format)
+		}
+	}
+
+	return supported, unsupported
+}
+
+/*
+Export renders data in the named format.
+
+It is the single place that maps format names to exporters, so the single
+dataset export and bundle exports behave the same.
+*/
+func (s *ExportService) Export(format string, data []map[string]interface{}, fieldNames []string, tableName string) (*ExportResult, error) {
+	info, ok := exportFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedFormat, format)
+	}
+
+	var content []byte
+	var err error
+
+	switch format {
+	case "json":
+		content, err = s.ToJSON(data, fieldNames)
+	case "ndjson":
+		content, err = s.ToNDJSON(data, fieldNames)
+	case "csv":
+		content, err = s.ToCSV(data, fieldNames)
+	case "tsv":
+		content, err = s.ToTSV(data, fieldNames)
+	case "markdown", "md":
+		content, err = s.ToMarkdownTable(data, fieldNames)
+	case "html":
+		content, err = s.ToHTMLTable(data, fieldNames)
+	case "sql":
+		content, err = s.ToSQL(data, fieldNames, tableName)
+	case "yaml":
+		content, err = s.ToYAML(data, fieldNames)
+	case "xlsx":
+		content, err = s.ToXLSX(data, fieldNames)
+	case "ts":
+		content, err = s.ToTypeScript(data, fieldNames, s.options.TypeName)
+	case "jsonschema":
+		content, err = s.ToJSONSchema(data, fieldNames)
+	case "copy":
+		content, err = s.ToPostgresCOPY(data, fieldNames, tableName)
+	case "sqlite":
+		content, err = s.ToSQLite(data, fieldNames, tableName)
+	case "zip":
+		content, err = s.ToZip(data, fieldNames, tableName, s.options.ArchiveName)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportResult{Data: content, ContentType: info.contentType, Extension: info.extension}, nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrInvalidExportOption is returned when export options don't fit the dataset
+	ErrInvalidExportOption = errors.New("invalid export option")
+
+	// ErrNoData is returned when there are no rows left to export
+	ErrNoData = errors.New("no data to export")
+)
+
+/*
+ExportOptions controls how values are rendered by the text exporters.
+
+The zero value is not useful on its own; start from DefaultExportOptions
+and override what you need.
+*/
+type ExportOptions struct {
+	// Labels used for booleans in CSV and Markdown output
+	TrueLabel  string
+	FalseLabel string
+
+	// Upsert makes SQL exports update rows that already exist instead of
+	// failing on conflicts; ConflictColumn defaults to the primary key
+	Upsert         bool
+	ConflictColumn string
+
+	// PrimaryKey overrides primary key detection for SQL exports
+	PrimaryKey string
+
+	// FieldTypes holds stored column types for SQL exports; fields not
+	// listed fall back to inference
+	FieldTypes map[string]string
+
+	// Copy makes SQL exports load rows with a PostgreSQL COPY block
+	// instead of INSERT statements; it cannot be combined with Upsert
+	Copy bool
+
+	// Dialect selects the SQL flavor of SQL exports; see the SQLDialect*
+	// constants (empty means PostgreSQL)
+	Dialect string
+
+	// SQLMode chooses one INSERT per row or multi-row INSERTs; see the
+	// SQLMode* constants
+	SQLMode string
+
+	// Metadata, when set, is embedded in JSON exports and written as
+	// leading comments in CSV and SQL exports
+	Metadata *ExportMetadata
+
+	// StrictFields controls rows with keys outside the field list; see the
+	// StrictFields* constants
+	StrictFields string
+
+	// TypeName names the interface of TypeScript exports (default
+	// DefaultTypeName)
+	TypeName string
+
+	// ArchiveName is the file base name inside zip exports (default
+	// DefaultArchiveName)
+	ArchiveName string
+
+	// BOM prefixes CSV exports with a UTF-8 byte order mark so Excel
+	// detects the encoding
+	BOM bool
+
+	// NullAs controls how null values are rendered; see the Null* constants.
+	// Any other non-empty value is used as a literal token.
+	NullAs string
+}
+
+/*
+Null representations accepted by ExportOptions.NullAs.
+
+	             CSV/Markdown   SQL       SQL COPY   JSON
+	(default)    ""             NULL      \N         null
+	empty        ""             ''        (empty)    ""
+	null         null           NULL      \N         null
+	<token>      <token>        '<token>' <token>    "<token>"
+
+In SQL, only text columns get the empty or token replacement; other columns
+keep NULL (\N) since the text would not parse as their type. In JSON,
+fields missing from a row get the replacement too when nulls are replaced
+(empty or a token); with null or the default they stay missing.
+*/
+const (
+	NullDefault = ""
+	NullEmpty   = "empty"
+	NullLiteral = "null"
+)
+
+// textNull renders a null for CSV/Markdown
+func (o ExportOptions) textNull() string {
+	switch o.NullAs {
+	case NullDefault, NullEmpty:
+		return ""
+	default:
+		return o.NullAs
+	}
+}
+
+// sqlNull renders a null for SQL INSERT values
+func (o ExportOptions) sqlNull() string {
+	switch o.NullAs {
+	case NullDefault, NullLiteral:
+		return "NULL"
+	case NullEmpty:
+		return "''"
+	default:
+		return o.sqlString(o.NullAs)
+	}
+}
+
+// copyNull renders a null for SQL COPY rows
+func (o ExportOptions) copyNull() string {
+	switch o.NullAs {
+	case NullDefault, NullLiteral:
+		return `\N`
+	case NullEmpty:
+		return ""
+	default:
+		return escapeCopy(o.NullAs)
+	}
+}
+
+// jsonNull returns the JSON replacement for nulls, if nulls are replaced at all
+func (o ExportOptions) jsonNull() (string, bool) {
+	switch o.NullAs {
+	case NullDefault, NullLiteral:
+		return "", false
+	case NullEmpty:
+		return "", true
+	default:
+		return o.NullAs, true
+	}
+}
+
+// replaceNulls returns a copy of data with null or missing fields set to replacement
+func replaceNulls(data []map[string]interface{}, fieldNames []string, replacement string) []map[string]interface{} {
+	result := make([]map[string]interface{}, len(data))
+	for i, row := range data {
+		copied := make(map[string]interface{}, len(row))
+		for key, value := range row {
+			copied[key] = value
+		}
+		for _, field := range fieldNames {
+			if copied[field] == nil {
+				copied[field] = replacement
+			}
+		}
+		result[i] = copied
+	}
+	return result
+}
+
+// DefaultExportOptions returns the options matching the historical output
+func DefaultExportOptions() ExportOptions {
+	return ExportOptions{
+		TrueLabel:  "true",
+		FalseLabel: "false",
+	}
+}
+
+// boolPresets maps preset names to their true/false labels
+var boolPresets = map[string][2]string{
+	"truefalse": {"true", "false"},
+	"TRUEFALSE": {"TRUE", "FALSE"},
+	"yesno":     {"Yes", "No"},
+	"yn":        {"Y", "N"},
+	"10":        {"1", "0"},
+}
+
+// BoolPreset returns the labels for a named boolean preset
+func BoolPreset(name string) (trueLabel, falseLabel string, err error) {
+	labels, ok := boolPresets[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown boolean format '%s' (use one of %v)", name, BoolPresetNames())
+	}
+	return labels[0], labels[1], nil
+}
+
+// BoolPresetNames returns the supported boolean preset names
+func BoolPresetNames() []string {
+	names := make([]string, 0, len(boolPresets))
+	for name := range boolPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
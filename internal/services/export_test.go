@@ -1,6 +1,8 @@
 package services
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +35,114 @@ func TestExportService_ToJSON(t *testing.T) {
 	assert.Contains(t, string(result), "John", "Should contain test data")
 }
 
+// TestExportService_ToNDJSON tests newline-delimited JSON export functionality
+func TestExportService_ToNDJSON(t *testing.T) {
+	service := NewExportService()
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John", "age": float64(30), "internal": "x"},
+		{"id": float64(2), "name": "Jane\nDoe"},
+	}
+	fieldNames := []string{"name", "id", "age"}
+
+	result, err := service.ToNDJSON(data, fieldNames)
+	require.NoError(t, err, "ToNDJSON should not return an error")
+
+	lines := strings.Split(strings.TrimSuffix(string(result), "\n"), "\n")
+	require.Len(t, lines, 2, "One line per row")
+	assert.Equal(t, `{"name":"John","id":1,"age":30}`, lines[0], "Only listed keys, in field order")
+
+	for _, line := range lines {
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &row), line)
+		assert.Len(t, row, len(fieldNames))
+	}
+
+	var second map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "Jane\nDoe", second["name"], "Embedded newlines stay escaped")
+	assert.Nil(t, second["age"], "Missing keys are null")
+}
+
+// TestExportService_ToTypeScript tests TypeScript interface export
+func TestExportService_ToTypeScript(t *testing.T) {
+	service := NewExportService()
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "email": "john@example.com", "active": true, "first name": "John", "meta": map[string]interface{}{"a": 1}, "note": nil},
+		{"id": float64(2), "email": nil, "active": false, "first name": "Jane", "meta": nil, "note": nil},
+	}
+	fieldNames := []string{"id", "email", "active", "first name", "meta", "note"}
+
+	result, err := service.ToTypeScript(data, fieldNames, "hospital patients")
+	require.NoError(t, err)
+
+	assert.Equal(t, `export interface HospitalPatients {
+  id: number;
+  email: string | null;
+  active: boolean;
+  "first name": string;
+  meta: unknown | null;
+  note: null;
+}
+`, string(result))
+
+	result, err = service.ToTypeScript(data, fieldNames, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(result), "export interface MockData {"), "Default interface name")
+
+	// Through Export, the name comes from the options
+	opts := DefaultExportOptions()
+	opts.TypeName = "patient"
+	exported, err := service.WithOptions(opts).Export("ts", data, fieldNames, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ts", exported.Extension)
+	assert.Contains(t, string(exported.Data), "export interface Patient {")
+}
+
+// TestTypeName tests interface name normalization
+func TestTypeName(t *testing.T) {
+	assert.Equal(t, "MockData", typeName(""))
+	assert.Equal(t, "MockData", typeName("  --"))
+	assert.Equal(t, "UserAccounts", typeName("user_accounts"))
+	assert.Equal(t, "_2024Orders", typeName("2024 orders"))
+	assert.Equal(t, "Café", typeName("café"))
+}
+
+// TestExportService_ToYAML tests YAML export functionality
+func TestExportService_ToYAML(t *testing.T) {
+	service := NewExportService()
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John", "age": float64(30), "score": 9.5},
+		{"id": float64(2), "name": "Jane", "age": float64(25), "score": float64(8)},
+	}
+	fieldNames := []string{"id", "name", "age", "score"}
+
+	result, err := service.ToYAML(data, fieldNames)
+
+	require.NoError(t, err, "ToYAML should not return an error")
+	assert.Equal(t, `fields:
+  - id
+  - name
+  - age
+  - score
+data:
+  - id: 1
+    name: John
+    age: 30
+    score: 9.5
+  - id: 2
+    name: Jane
+    age: 25
+    score: 8
+count: 2
+`, string(result), "Whole numbers should render as integers and keys keep field order")
+
+	_, err = service.ToYAML(nil, fieldNames)
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
 // TestExportService_ToCSV tests CSV export functionality
 func TestExportService_ToCSV(t *testing.T) {
 	service := NewExportService()
@@ -72,6 +182,41 @@ func TestExportService_ToCSV_EmptyData(t *testing.T) {
 	assert.Contains(t, err.Error(), "no data", "Error message should mention no data")
 }
 
+// TestExportService_ToCSV_BOM tests the optional UTF-8 byte order mark
+func TestExportService_ToCSV_BOM(t *testing.T) {
+	data := []map[string]interface{}{{"name": "José"}}
+
+	plain, err := NewExportService().ToCSV(data, []string{"name"})
+	require.NoError(t, err)
+	assert.Equal(t, "name\nJosé\n", string(plain), "No BOM by default")
+
+	opts := DefaultExportOptions()
+	opts.BOM = true
+	result, err := NewExportService().WithOptions(opts).ToCSV(data, []string{"name"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xEF, 0xBB, 0xBF}, result[:3])
+	assert.Equal(t, string(plain), string(result[3:]))
+}
+
+// TestExportService_ToTSV tests tab-separated export functionality
+func TestExportService_ToTSV(t *testing.T) {
+	service := NewExportService()
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John, Jr.", "note": "tab\there"},
+		{"id": float64(2), "name": "Jane", "note": "two\nlines"},
+	}
+	fieldNames := []string{"id", "name", "note"}
+
+	result, err := service.ToTSV(data, fieldNames)
+	require.NoError(t, err, "ToTSV should not return an error")
+
+	tsv := string(result)
+	assert.True(t, strings.HasPrefix(tsv, "id\tname\tnote\n"), "Header row should be tab-joined")
+	assert.Contains(t, tsv, "1\tJohn, Jr.\t\"tab\there\"\n", "Commas stay unquoted, tabs are quoted")
+	assert.Contains(t, tsv, "2\tJane\t\"two\nlines\"\n", "Newlines are quoted")
+}
+
 // TestExportService_ToMarkdownTable tests Markdown export
 func TestExportService_ToMarkdownTable(t *testing.T) {
 	service := NewExportService()
@@ -98,6 +243,31 @@ func TestExportService_ToMarkdownTable(t *testing.T) {
 	assert.Contains(t, md, "| 1 | John |", "Should contain data row")
 }
 
+// TestExportService_ToHTMLTable tests HTML table export functionality
+func TestExportService_ToHTMLTable(t *testing.T) {
+	service := NewExportService()
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "<script>alert(1)</script>", "company": "Smith & Co"},
+		{"id": float64(2), "name": "Jane", "company": nil},
+	}
+	fieldNames := []string{"id", "name", "company"}
+
+	result, err := service.ToHTMLTable(data, fieldNames)
+	require.NoError(t, err, "ToHTMLTable should not return an error")
+
+	table := string(result)
+	for _, field := range fieldNames {
+		assert.Contains(t, table, "<th>"+field+"</th>", "Should contain a header cell per field")
+	}
+	assert.Contains(t, table, "<thead>")
+	assert.Contains(t, table, "<tbody>")
+	assert.NotContains(t, table, "<script>", "Values should be escaped")
+	assert.Contains(t, table, "<td>&lt;script&gt;alert(1)&lt;/script&gt;</td>")
+	assert.Contains(t, table, "<td>Smith &amp; Co</td>")
+	assert.Contains(t, table, "<tr><td>2</td><td>Jane</td><td></td></tr>")
+}
+
 // TestExportService_ToSQL tests SQL export
 func TestExportService_ToSQL(t *testing.T) {
 	service := NewExportService()
@@ -126,6 +296,25 @@ func TestExportService_ToSQL(t *testing.T) {
 	assert.Contains(t, sql, "TRUE", "Should contain boolean value")
 }
 
+// TestExportService_ToSQL_InferAllRows tests that column types come from every row
+func TestExportService_ToSQL_InferAllRows(t *testing.T) {
+	data := []map[string]interface{}{
+		{"name": "John", "age": nil, "active": nil, "note": nil},
+		{"name": "Jane", "age": float64(31), "active": true, "note": nil},
+		{"name": "Max", "age": float64(45), "active": "yes", "note": nil},
+	}
+	fieldNames := []string{"name", "age", "active", "note"}
+
+	result, err := NewExportService().ToSQL(data, fieldNames, "people")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Contains(t, sql, "age NUMERIC", "A leading null should not hide later numbers")
+	assert.Contains(t, sql, "active TEXT", "Mixed columns fall back to TEXT")
+	assert.Contains(t, sql, "note TEXT", "Entirely null columns are TEXT")
+	assert.Contains(t, sql, "VALUES ('Jane', 31, TRUE, NULL)", "Numbers stay unquoted")
+}
+
 // TestExportService_GetAvailableFormats tests format enumeration
 func TestExportService_GetAvailableFormats(t *testing.T) {
 	service := NewExportService()
@@ -137,6 +326,15 @@ func TestExportService_GetAvailableFormats(t *testing.T) {
 	assert.Contains(t, formats, "csv", "Should include csv")
 	assert.Contains(t, formats, "markdown", "Should include markdown")
 	assert.Contains(t, formats, "sql", "Should include sql")
+	assert.Contains(t, formats, "yaml", "Should include yaml")
+	assert.Contains(t, formats, "xlsx", "Should include xlsx")
+	assert.Contains(t, formats, "tsv", "Should include tsv")
+	assert.Contains(t, formats, "html", "Should include html")
+	assert.Contains(t, formats, "ndjson", "Should include ndjson")
+	assert.Contains(t, formats, "sqlite", "Should include sqlite")
+	assert.Contains(t, formats, "copy", "Should include copy")
+	assert.Contains(t, formats, "ts", "Should include ts")
+	assert.Contains(t, formats, "jsonschema", "Should include jsonschema")
 }
 
 // TestFormatValue tests the value formatting helper
@@ -184,3 +382,474 @@ func TestFormatSQLValue(t *testing.T) {
 		})
 	}
 }
+
+// TestExportService_BooleanLabels tests configurable boolean rendering
+func TestExportService_BooleanLabels(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "active": true},
+		{"id": float64(2), "active": false},
+	}
+	fieldNames := []string{"id", "active"}
+
+	tests := []struct {
+		name       string
+		preset     string
+		trueLabel  string
+		falseLabel string
+	}{
+		{"Default", "truefalse", "true", "false"},
+		{"Uppercase", "TRUEFALSE", "TRUE", "FALSE"},
+		{"Yes/No", "yesno", "Yes", "No"},
+		{"Y/N", "yn", "Y", "N"},
+		{"Numeric", "10", "1", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trueLabel, falseLabel, err := BoolPreset(tt.preset)
+			require.NoError(t, err)
+			assert.Equal(t, tt.trueLabel, trueLabel)
+			assert.Equal(t, tt.falseLabel, falseLabel)
+
+			opts := DefaultExportOptions()
+			opts.TrueLabel, opts.FalseLabel = trueLabel, falseLabel
+			service := NewExportService().WithOptions(opts)
+
+			csv, err := service.ToCSV(data, fieldNames)
+			require.NoError(t, err)
+			assert.Contains(t, string(csv), "1,"+tt.trueLabel+"\n")
+			assert.Contains(t, string(csv), "2,"+tt.falseLabel+"\n")
+
+			md, err := service.ToMarkdownTable(data, fieldNames)
+			require.NoError(t, err)
+			assert.Contains(t, string(md), "| 1 | "+tt.trueLabel+" |")
+			assert.Contains(t, string(md), "| 2 | "+tt.falseLabel+" |")
+		})
+	}
+}
+
+// TestExportService_CustomBooleanLabels tests arbitrary labels
+func TestExportService_CustomBooleanLabels(t *testing.T) {
+	opts := DefaultExportOptions()
+	opts.TrueLabel, opts.FalseLabel = "on", "off"
+
+	assert.Equal(t, "on", opts.formatValue(true))
+	assert.Equal(t, "off", opts.formatValue(false))
+
+	_, _, err := BoolPreset("maybe")
+	assert.Error(t, err, "Unknown presets should be rejected")
+}
+
+// TestExportService_ToSQL_Upsert tests ON CONFLICT upsert generation
+func TestExportService_ToSQL_Upsert(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John", "active": true},
+		{"id": float64(2), "name": "Jane", "active": false},
+	}
+	fieldNames := []string{"id", "name", "active"}
+
+	opts := DefaultExportOptions()
+	opts.Upsert = true
+	service := NewExportService().WithOptions(opts)
+
+	result, err := service.ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Contains(t, sql,
+		"INSERT INTO users (id, name, active) VALUES (1, 'John', TRUE) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, active = EXCLUDED.active;",
+		"Should upsert on id by default")
+
+	// Custom conflict column
+	opts.ConflictColumn = "name"
+	result, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "  id NUMERIC PRIMARY KEY,\n  name TEXT UNIQUE,\n", "The conflict column needs a unique constraint")
+	assert.Contains(t, string(result), "ON CONFLICT (name) DO UPDATE SET id = EXCLUDED.id, active = EXCLUDED.active;")
+
+	mysqlOpts := opts
+	mysqlOpts.Dialect = SQLDialectMySQL
+	result, err = NewExportService().WithOptions(mysqlOpts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "  `name` VARCHAR(255) UNIQUE,\n", "MySQL can't index TEXT")
+
+	// Only the key column: nothing to update
+	result, err = NewExportService().WithOptions(opts).ToSQL([]map[string]interface{}{{"name": "John"}}, []string{"name"}, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "ON CONFLICT (name) DO NOTHING;")
+
+	// Unknown conflict column
+	opts.ConflictColumn = "email"
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+
+	// Without a detected key there is nothing to conflict on
+	opts.ConflictColumn = ""
+	_, err = NewExportService().WithOptions(opts).ToSQL([]map[string]interface{}{{"id": nil, "name": "John"}}, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+	assert.ErrorContains(t, err, "upsert needs a key")
+
+	// Default export has no conflict clause
+	result, err = NewExportService().ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.NotContains(t, string(result), "ON CONFLICT")
+}
+
+// TestExportService_ToSQL_PrimaryKey tests primary key detection and overrides
+func TestExportService_ToSQL_PrimaryKey(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "code": "A1", "name": "John"},
+		{"id": float64(2), "code": "B2", "name": "John"},
+	}
+	fieldNames := []string{"id", "code", "name"}
+
+	// Detected from the id field
+	result, err := NewExportService().ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "  id NUMERIC PRIMARY KEY,")
+	assert.NotContains(t, string(result), "code TEXT PRIMARY KEY")
+
+	// Explicit override
+	opts := DefaultExportOptions()
+	opts.PrimaryKey = "code"
+	result, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "  code TEXT PRIMARY KEY,")
+	assert.NotContains(t, string(result), "id NUMERIC PRIMARY KEY")
+
+	// Override with duplicate values is rejected
+	opts.PrimaryKey = "name"
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+
+	// Override with an unknown field is rejected
+	opts.PrimaryKey = "email"
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+
+	// Duplicate or null ids are not detected as a key
+	dupes := []map[string]interface{}{
+		{"id": float64(1), "name": "John"},
+		{"id": nil, "name": "Jane"},
+	}
+	result, err = NewExportService().ToSQL(dupes, []string{"id", "name"}, "users")
+	require.NoError(t, err)
+	assert.NotContains(t, string(result), "PRIMARY KEY")
+
+	// Upsert defaults to the detected key
+	opts = DefaultExportOptions()
+	opts.Upsert = true
+	uuidData := []map[string]interface{}{{"uuid": "a-1", "name": "John"}}
+	result, err = NewExportService().WithOptions(opts).ToSQL(uuidData, []string{"uuid", "name"}, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "uuid TEXT PRIMARY KEY")
+	assert.Contains(t, string(result), "ON CONFLICT (uuid)")
+}
+
+// TestExportService_NullAs tests consistent null rendering across formats
+func TestExportService_NullAs(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": nil},
+	}
+	fieldNames := []string{"id", "name"}
+
+	tests := []struct {
+		nullAs   string
+		csv      string
+		markdown string
+		sql      string
+		json     string
+	}{
+		{NullDefault, "1,\n", "| 1 |  |", "VALUES (1, NULL)", `"name": null`},
+		{NullEmpty, "1,\n", "| 1 |  |", "VALUES (1, '')", `"name": ""`},
+		{NullLiteral, "1,null\n", "| 1 | null |", "VALUES (1, NULL)", `"name": null`},
+		{"N/A", "1,N/A\n", "| 1 | N/A |", "VALUES (1, 'N/A')", `"name": "N/A"`},
+		{"it's", "1,it's\n", "| 1 | it's |", "VALUES (1, 'it''s')", `"name": "it's"`},
+	}
+
+	for _, tt := range tests {
+		t.Run("null_as="+tt.nullAs, func(t *testing.T) {
+			opts := DefaultExportOptions()
+			opts.NullAs = tt.nullAs
+			service := NewExportService().WithOptions(opts)
+
+			csv, err := service.ToCSV(data, fieldNames)
+			require.NoError(t, err)
+			assert.Contains(t, string(csv), tt.csv)
+
+			md, err := service.ToMarkdownTable(data, fieldNames)
+			require.NoError(t, err)
+			assert.Contains(t, string(md), tt.markdown)
+
+			sql, err := service.ToSQL(data, fieldNames, "users")
+			require.NoError(t, err)
+			assert.Contains(t, string(sql), tt.sql)
+
+			json, err := service.ToJSON(data, fieldNames)
+			require.NoError(t, err)
+			assert.Contains(t, string(json), tt.json)
+		})
+	}
+
+	assert.Nil(t, data[0]["name"], "Export should not modify the source data")
+}
+
+// TestExportService_NullAs_MissingJSONFields tests that missing fields are filled once null_as is set
+func TestExportService_NullAs_MissingJSONFields(t *testing.T) {
+	data := []map[string]interface{}{{"id": float64(1)}}
+
+	opts := DefaultExportOptions()
+	opts.NullAs = "-"
+	result, err := NewExportService().WithOptions(opts).ToJSON(data, []string{"id", "name"})
+	require.NoError(t, err)
+	assert.Contains(t, string(result), `"name": "-"`)
+
+	result, err = NewExportService().ToJSON(data, []string{"id", "name"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(result), `"name"`+": ", "Default JSON keeps missing fields omitted")
+}
+
+func TestExportService_NullAs_TypedColumns(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": nil, "score": 9.5},
+		{"id": float64(2), "name": "Jane", "score": nil},
+	}
+	fieldNames := []string{"id", "name", "score"}
+
+	for _, nullAs := range []string{NullEmpty, "n/a"} {
+		opts := DefaultExportOptions()
+		opts.NullAs = nullAs
+		result, err := NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+		require.NoError(t, err)
+		assert.Contains(t, string(result), "VALUES (2, 'Jane', NULL)", "null_as=%s: the numeric score stays NULL", nullAs)
+		assert.NotContains(t, string(result), "9.5, NULL")
+	}
+}
+
+// TestExportService_ToSQL_Copy tests the COPY block structure
+func TestExportService_ToSQL_Copy(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John", "active": true, "score": 9.5},
+		{"id": float64(2), "name": nil, "active": false, "score": nil},
+	}
+	fieldNames := []string{"id", "name", "active", "score"}
+
+	opts := DefaultExportOptions()
+	opts.Copy = true
+	result, err := NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Contains(t, sql, "CREATE TABLE IF NOT EXISTS users (", "Should still create the table")
+	assert.Contains(t, sql, "COPY users (id, name, active, score) FROM stdin;\n"+
+		"1\tJohn\ttrue\t9.5\n"+
+		"2\t\\N\tfalse\t\\N\n"+
+		"\\.\n")
+	assert.NotContains(t, sql, "INSERT INTO", "COPY replaces INSERT statements")
+
+	// Custom null token
+	opts.NullAs = "n/a"
+	result, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "2\tn/a\tfalse\t\\N\n", "The numeric score stays null")
+
+	// Upsert needs INSERT statements
+	opts.Upsert = true
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+}
+
+// TestExportService_ToPostgresCOPY tests the standalone COPY export
+func TestExportService_ToPostgresCOPY(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John\tSmith", "note": "C:\\temp\nline 2"},
+		{"id": float64(2), "name": nil},
+	}
+	fieldNames := []string{"id", "name", "note"}
+
+	result, err := NewExportService().Export("copy", data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "copy.sql", result.Extension)
+	assert.Equal(t, "COPY users (id, name, note) FROM stdin;\n"+
+		"1\tJohn\\tSmith\tC:\\\\temp\\nline 2\n"+
+		"2\t\\N\t\\N\n"+
+		"\\.\n", string(result.Data), "Nulls become \\N and tabs in values are escaped")
+
+	result, err = NewExportService().Export("copy", data, fieldNames, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(result.Data), "COPY mock_data ("))
+
+	_, err = NewExportService().ToPostgresCOPY(nil, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+// TestExportService_ToSQL_Batch tests multi-row INSERT statements
+func TestExportService_ToSQL_Batch(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "O'Brien", "active": true},
+		{"id": float64(2), "name": nil, "active": false},
+		{"id": float64(3), "name": "Max", "active": true},
+	}
+	fieldNames := []string{"id", "name", "active"}
+
+	opts := DefaultExportOptions()
+	opts.SQLMode = SQLModeBatch
+	result, err := NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Equal(t, 1, strings.Count(sql, "INSERT INTO"), "Rows should share a single INSERT")
+	assert.Contains(t, sql, "INSERT INTO users (id, name, active) VALUES\n"+
+		"  (1, 'O''Brien', TRUE),\n"+
+		"  (2, NULL, FALSE),\n"+
+		"  (3, 'Max', TRUE);\n")
+
+	// Upserts apply to the whole statement
+	opts.Upsert = true
+	result, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "  (3, 'Max', TRUE) ON CONFLICT (id) DO UPDATE SET")
+
+	// A key repeated within a batch starts a new statement, since PostgreSQL
+	// can't update the same row twice in one upsert
+	opts.ConflictColumn = "name"
+	dupes := []map[string]interface{}{
+		{"id": float64(1), "name": "Max", "active": true},
+		{"id": float64(2), "name": "Ann", "active": true},
+		{"id": float64(3), "name": "Max", "active": false},
+	}
+	result, err = NewExportService().WithOptions(opts).ToSQL(dupes, fieldNames, "users")
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(result), "INSERT INTO"))
+	assert.Contains(t, string(result), "  (1, 'Max', TRUE),\n  (2, 'Ann', TRUE) ON CONFLICT (name) DO UPDATE SET")
+	assert.Contains(t, string(result), "VALUES\n  (3, 'Max', FALSE) ON CONFLICT (name) DO UPDATE SET")
+	opts.ConflictColumn = ""
+
+	// Copy has no INSERTs to batch
+	opts.Upsert = false
+	opts.Copy = true
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+}
+
+// TestExportService_ToSQL_Dialects tests the same dataset as PostgreSQL and MySQL
+func TestExportService_ToSQL_Dialects(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": "a1", "path": `C:\tmp`, "score": 1.5, "active": true},
+		{"id": "b2", "path": "O'Brien", "score": float64(2), "active": false},
+	}
+	fieldNames := []string{"id", "path", "score", "active"}
+
+	opts := DefaultExportOptions()
+	opts.Upsert = true
+	result, err := NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "files")
+	require.NoError(t, err)
+
+	postgres := string(result)
+	assert.Contains(t, postgres, "CREATE TABLE IF NOT EXISTS files (\n  id TEXT PRIMARY KEY,\n  path TEXT,\n  score NUMERIC,\n  active BOOLEAN\n);")
+	assert.Contains(t, postgres, `INSERT INTO files (id, path, score, active) VALUES ('a1', 'C:\tmp', 1.5, TRUE)`)
+	assert.Contains(t, postgres, "('b2', 'O''Brien', 2, FALSE) ON CONFLICT (id) DO UPDATE SET")
+
+	opts.Dialect = SQLDialectMySQL
+	result, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "files")
+	require.NoError(t, err)
+
+	mysql := string(result)
+	assert.Contains(t, mysql, "CREATE TABLE IF NOT EXISTS `files` (\n  `id` VARCHAR(255) PRIMARY KEY,\n  `path` TEXT,\n  `score` DOUBLE,\n  `active` BOOLEAN\n);")
+	assert.Contains(t, mysql, "INSERT INTO `files` (`id`, `path`, `score`, `active`) VALUES ('a1', 'C:\\\\tmp', 1.5, 1)")
+	assert.Contains(t, mysql, "('b2', 'O''Brien', 2, 0) ON DUPLICATE KEY UPDATE `path` = VALUES(`path`), `score` = VALUES(`score`), `active` = VALUES(`active`);")
+	assert.NotContains(t, mysql, "TRUE")
+
+	// COPY only exists in PostgreSQL
+	opts.Upsert = false
+	opts.Copy = true
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "files")
+	assert.ErrorIs(t, err, ErrInvalidExportOption)
+}
+
+// TestParseSQLDialect tests dialect names and aliases
+func TestParseSQLDialect(t *testing.T) {
+	for name, expected := range map[string]string{"": SQLDialectPostgres, "PostgreSQL": SQLDialectPostgres, "mysql": SQLDialectMySQL} {
+		dialect, err := ParseSQLDialect(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, dialect, name)
+	}
+
+	_, err := ParseSQLDialect("oracle")
+	assert.Error(t, err)
+}
+
+// TestExportService_ToSQL_BatchSize tests splitting large datasets into several INSERTs
+func TestExportService_ToSQL_BatchSize(t *testing.T) {
+	data := make([]map[string]interface{}, SQLBatchSize*2+1)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": float64(i + 1)}
+	}
+
+	opts := DefaultExportOptions()
+	opts.SQLMode = SQLModeBatch
+	result, err := NewExportService().WithOptions(opts).ToSQL(data, []string{"id"}, "items")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Equal(t, 3, strings.Count(sql, "INSERT INTO"))
+	assert.Equal(t, len(data), strings.Count(sql, "  ("), "Every row is written once")
+	assert.Contains(t, sql, "  (99),\n  (100);\nINSERT INTO items (id) VALUES\n  (101),\n")
+	assert.Contains(t, sql, "  (200);\nINSERT INTO items (id) VALUES\n  (201);\n")
+}
+
+// TestEscapeCopy tests COPY text-format escaping
+func TestEscapeCopy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"tab\there", `tab\there`},
+		{"line\nbreak", `line\nbreak`},
+		{"carriage\rreturn", `carriage\rreturn`},
+		{`back\slash`, `back\\slash`},
+		{`\N`, `\\N`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, escapeCopy(tt.input), "escapeCopy(%q)", tt.input)
+	}
+}
+
+// TestQuoteIdent tests quoting of non-simple SQL identifiers
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"name", "name"},
+		{"first_name", "first_name"},
+		{"firstName", "firstName"},
+		{"Straße", `"Straße"`},
+		{"名前", `"名前"`},
+		{"first name", `"first name"`},
+		{`say "hi"`, `"say ""hi"""`},
+		{"1st", `"1st"`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, quoteIdent(tt.input), "quoteIdent(%q)", tt.input)
+	}
+}
+
+// TestExportService_ToSQL_LocalizedFields tests SQL export of non-ASCII field names
+func TestExportService_ToSQL_LocalizedFields(t *testing.T) {
+	data := []map[string]interface{}{{"id": float64(1), "Straße": "Hauptstraße 1"}}
+	fieldNames := []string{"id", "Straße"}
+
+	opts := DefaultExportOptions()
+	opts.Upsert = true
+	result, err := NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "adressen")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Contains(t, sql, `  "Straße" TEXT`)
+	assert.Contains(t, sql, `INSERT INTO adressen (id, "Straße") VALUES (1, 'Hauptstraße 1') ON CONFLICT (id) DO UPDATE SET "Straße" = EXCLUDED."Straße";`)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+FakerService produces best-effort mock data without calling any model.
+
+It is the fallback used while OpenAI is unavailable: the scenario is only
+scanned for a few keywords (people, products, orders, places) to pick a
+plausible set of fields, and values come from small built-in word lists.
+Reference values, when given, are reused for their fields.
+*/
+type FakerService struct {
+	now func() time.Time
+}
+
+// NewFakerService creates a new fallback data generator
+func NewFakerService() *FakerService {
+	return &FakerService{now: time.Now}
+}
+
+// fakerField generates the value of one field for the given row index
+type fakerField struct {
+	name  string
+	value func(rng *rand.Rand, i int) interface{}
+}
+
+var (
+	fakerFirstNames = []string{"Alex", "Maria", "James", "Aisha", "Chen", "Sofia", "Liam", "Noah", "Emma", "Yuki", "Omar", "Grace"}
+	fakerLastNames  = []string{"Smith", "Garcia", "Kim", "Okafor", "Müller", "Rossi", "Tanaka", "Brown", "Nowak", "Silva"}
+	fakerProducts   = []string{"Wireless Mouse", "Desk Lamp", "Water Bottle", "Notebook", "Headphones", "Backpack", "Coffee Mug", "USB Cable"}
+	fakerStatuses   = []string{"pending", "paid", "shipped", "delivered", "cancelled"}
+	fakerCities     = []string{"New York", "London", "Berlin", "Tokyo", "Lagos", "São Paulo", "Toronto", "Sydney"}
+	fakerCountries  = []string{"USA", "UK", "Germany", "Japan", "Nigeria", "Brazil", "Canada", "Australia"}
+)
+
+// fakerKeywords maps scenario keywords to the fields they add
+var fakerKeywords = []struct {
+	words  []string
+	fields []fakerField
+}{
+	{
+		words: []string{"user", "customer", "employee", "person", "people", "member", "patient", "student", "contact", "client"},
+		fields: []fakerField{
+			{"first_name", func(rng *rand.Rand, i int) interface{} { return pick(rng, fakerFirstNames) }},
+			{"last_name", func(rng *rand.Rand, i int) interface{} { return pick(rng, fakerLastNames) }},
+			{"email", func(rng *rand.Rand, i int) interface{} { return fmt.Sprintf("user%d@example.com", i+1) }},
+		},
+	},
+	{
+		words: []string{"product", "item", "inventory", "catalog"},
+		fields: []fakerField{
+			{"product_name", func(rng *rand.Rand, i int) interface{} { return pick(rng, fakerProducts) }},
+			{"price", func(rng *rand.Rand, i int) interface{} { return float64(rng.Intn(20000)+100) / 100 }},
+		},
+	},
+	{
+		words: []string{"order", "transaction", "purchase", "sale", "payment", "invoice"},
+		fields: []fakerField{
+			{"amount", func(rng *rand.Rand, i int) interface{} { return float64(rng.Intn(100000)+100) / 100 }},
+			{"status", func(rng *rand.Rand, i int) interface{} { return pick(rng, fakerStatuses) }},
+		},
+	},
+	{
+		words: []string{"address", "city", "location", "store", "office", "country"},
+		fields: []fakerField{
+			{"city", func(rng *rand.Rand, i int) interface{} { return pick(rng, fakerCities) }},
+			{"country", func(rng *rand.Rand, i int) interface{} { return pick(rng, fakerCountries) }},
+		},
+	},
+}
+
+// FallbackModelName is recorded as the model of data from the fallback generator
+const FallbackModelName = "fallback"
+
+// ModelName implements ModelNamer
+func (s *FakerService) ModelName(opts GenerateOptions) string {
+	return FallbackModelName
+}
+
+// GenerateMockData implements Generator; a request seed makes the data reproducible
+func (s *FakerService) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, []string, error) {
+	seed := s.now().UnixNano()
+	if opts.Seed != nil {
+		seed = int64(*opts.Seed)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	if len(opts.Schema) > 0 {
+		data, names := schemaRows(rng, opts.Schema, rowCount, opts.RowOffset, opts.ReferenceValues)
+		return data, names, nil
+	}
+
+	data, names := fakeRows(rng, scenario, rowCount, opts.ReferenceValues)
+	return data, names, nil
+}
+
+// fakeRows generates rowCount rows for a scenario from rng
+func fakeRows(rng *rand.Rand, scenario string, rowCount int, references map[string][]string) ([]map[string]interface{}, []string) {
+	fields := fakerFieldsFor(scenario, references)
+
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.name
+	}
+
+	data := make([]map[string]interface{}, rowCount)
+	for i := range data {
+		row := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			row[field.name] = field.value(rng, i)
+		}
+		data[i] = row
+	}
+
+	return data, names
+}
+
+// fakerFieldsFor picks the fields for a scenario: an id, keyword fields
+// (or a generic name when nothing matches), a timestamp and reference fields
+func fakerFieldsFor(scenario string, references map[string][]string) []fakerField {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	fields := []fakerField{{"id", func(rng *rand.Rand, i int) interface{} { return float64(i + 1) }}}
+
+	words := strings.Fields(strings.ToLower(scenario))
+	matched := false
+	for _, group := range fakerKeywords {
+		if !mentionsAny(words, group.words) {
+			continue
+		}
+		matched = true
+		fields = append(fields, group.fields...)
+	}
+	if !matched {
+		fields = append(fields, fakerField{"name", func(rng *rand.Rand, i int) interface{} {
+			return fmt.Sprintf("Item %d", i+1)
+		}})
+	}
+
+	fields = append(fields, fakerField{"created_at", func(rng *rand.Rand, i int) interface{} {
+		return start.Add(time.Duration(rng.Intn(365*24)) * time.Hour).Format(time.RFC3339)
+	}})
+
+	// Reference fields reuse existing values, replacing a built-in field of the same name
+	for _, name := range sortedKeys(references) {
+		values := references[name]
+		if len(values) == 0 {
+			continue
+		}
+		fields = appendOrReplaceField(fields, fakerField{name, func(rng *rand.Rand, i int) interface{} { return pick(rng, values) }})
+	}
+
+	return fields
+}
+
+// mentionsAny reports whether a word starts with any keyword ("users", "orders")
+func mentionsAny(words, keywords []string) bool {
+	for _, word := range words {
+		for _, keyword := range keywords {
+			if strings.HasPrefix(word, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func appendOrReplaceField(fields []fakerField, field fakerField) []fakerField {
+	for i := range fields {
+		if fields[i].name == field.name {
+			fields[i] = field
+			return fields
+		}
+	}
+	return append(fields, field)
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// Generator produces mock data for a scenario; implemented by the OpenAI
+// service, the TemplateService and the FakerService fallback
+type Generator interface {
+	GenerateMockData(ctx context.Context, scenario string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, []string, error)
+}
+
+// ModelNamer is implemented by generators that can tell which model a
+// request will use, so it can be recorded with the request
+type ModelNamer interface {
+	ModelName(opts GenerateOptions) string
+}
+
+// Diversifier is implemented by generators that can regenerate repetitive
+// fields of their own output in place, like the OpenAI service
+type Diversifier interface {
+	DiversifyFields(ctx context.Context, scenario string, data []map[string]interface{}, fieldNames, fields []string, opts GenerateOptions) error
+}
+
+/*
+GenerateWithFallback runs the primary generator and, when it fails and a
+fallback is configured, returns the fallback's data instead with degraded
+set to true.
+
+Content-filter rejections are not outages and are returned as is, and nothing
+falls back once ctx is done, since the result could not be saved anyway.
+*/
+func GenerateWithFallback(ctx context.Context, primary, fallback Generator, scenario string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, []string, bool, error) {
+	data, fields, err := primary.GenerateMockData(ctx, scenario, rowCount, opts)
+	if err == nil {
+		return data, fields, false, nil
+	}
+
+	if fallback == nil || errors.Is(err, ErrContentFiltered) || ctx.Err() != nil {
+		return nil, nil, false, err
+	}
+
+	log.Printf("⚠️ Primary generator failed (%v), using fallback data", err)
+
+	data, fields, fallbackErr := fallback.GenerateMockData(ctx, scenario, rowCount, opts)
+	if fallbackErr != nil {
+		return nil, nil, false, errors.Join(err, fallbackErr)
+	}
+
+	return data, fields, true, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateWithFallback tests falling back to the faker when OpenAI fails
+func TestGenerateWithFallback(t *testing.T) {
+	ctx := context.Background()
+	outage := &OpenAIService{client: &fakeChatClient{err: errors.New("503 service unavailable")}}
+	faker := NewFakerService()
+
+	t.Run("OpenAI failure uses the fallback", func(t *testing.T) {
+		data, fields, degraded, err := GenerateWithFallback(ctx, outage, faker, "customers", 5, GenerateOptions{})
+		require.NoError(t, err)
+		assert.True(t, degraded)
+		assert.Len(t, data, 5)
+		assert.Contains(t, fields, "email")
+	})
+
+	t.Run("Success is not degraded", func(t *testing.T) {
+		svc := newFakeService(`{"fields": ["id"], "data": [{"id": 1}]}`, openai.FinishReasonStop)
+		data, _, degraded, err := GenerateWithFallback(ctx, svc, faker, "ids", 1, GenerateOptions{})
+		require.NoError(t, err)
+		assert.False(t, degraded)
+		assert.Len(t, data, 1)
+	})
+
+	t.Run("Disabled fallback returns the error", func(t *testing.T) {
+		_, _, degraded, err := GenerateWithFallback(ctx, outage, nil, "customers", 5, GenerateOptions{})
+		assert.ErrorContains(t, err, "503")
+		assert.False(t, degraded)
+	})
+
+	t.Run("Content filter does not fall back", func(t *testing.T) {
+		svc := newFakeService("", openai.FinishReasonContentFilter)
+		_, _, _, err := GenerateWithFallback(ctx, svc, faker, "customers", 5, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrContentFiltered)
+	})
+
+	t.Run("Cancelled context does not fall back", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _, _, err := GenerateWithFallback(cancelled, outage, faker, "customers", 5, GenerateOptions{})
+		assert.Error(t, err)
+	})
+}
+
+// TestFakerService tests the keyword-based fallback data
+func TestFakerService(t *testing.T) {
+	faker := NewFakerService()
+	ctx := context.Background()
+
+	data, fields, err := faker.GenerateMockData(ctx, "Orders placed by customers", 3, GenerateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "first_name", "last_name", "email", "amount", "status", "created_at"}, fields)
+	require.Len(t, data, 3)
+	for i, row := range data {
+		assert.Equal(t, float64(i+1), row["id"])
+		assert.Len(t, row, len(fields))
+	}
+
+	// Unknown scenarios still get a usable shape
+	_, fields, err = faker.GenerateMockData(ctx, "spaceships", 1, GenerateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "created_at"}, fields)
+
+	// Reference values are reused, replacing built-in fields of the same name
+	data, fields, err = faker.GenerateMockData(ctx, "customers", 4, GenerateOptions{
+		ReferenceValues: map[string][]string{"email": {"a@example.com"}, "customer_id": {"7", "8"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "first_name", "last_name", "email", "created_at", "customer_id"}, fields)
+	for _, row := range data {
+		assert.Equal(t, "a@example.com", row["email"])
+		assert.Contains(t, []interface{}{"7", "8"}, row["customer_id"])
+	}
+
+	// A seed makes the data reproducible
+	seed := 3
+	first, _, err := faker.GenerateMockData(ctx, "customers", 5, GenerateOptions{Seed: &seed})
+	require.NoError(t, err)
+	second, _, err := faker.GenerateMockData(ctx, "customers", 5, GenerateOptions{Seed: &seed})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
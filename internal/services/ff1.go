@@ -0,0 +1,170 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// ff1Rounds is the number of Feistel rounds of FF1
+const ff1Rounds = 10
+
+/*
+ff1 is the FF1 format-preserving cipher of NIST SP 800-38G with AES.
+
+It encrypts numeral strings (digits in [0, radix)) of at least two numerals
+to numeral strings of the same length and radix; the tweak is public data
+that changes the permutation, like the field name of a value.
+*/
+type ff1 struct {
+	block cipher.Block
+	radix int
+}
+
+// newFF1 creates an FF1 cipher for an AES key of 16, 24 or 32 bytes
+func newFF1(key []byte, radix int) (*ff1, error) {
+	if radix < 2 || radix > 1<<16 {
+		return nil, fmt.Errorf("ff1: radix %d out of range", radix)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ff1: %w", err)
+	}
+	return &ff1{block: block, radix: radix}, nil
+}
+
+// encrypt enciphers the numeral string x under tweak
+func (f *ff1) encrypt(x []uint16, tweak []byte) []uint16 {
+	return f.cipher(x, tweak, true)
+}
+
+// decrypt reverses encrypt
+func (f *ff1) decrypt(x []uint16, tweak []byte) []uint16 {
+	return f.cipher(x, tweak, false)
+}
+
+// cipher runs the FF1 Feistel network (algorithms 7 and 8 of SP 800-38G)
+func (f *ff1) cipher(x []uint16, tweak []byte, encrypt bool) []uint16 {
+	n := len(x)
+	u := n / 2
+	v := n - u
+	a := append([]uint16(nil), x[:u]...)
+	b := append([]uint16(nil), x[u:]...)
+
+	radix := big.NewInt(int64(f.radix))
+	modU := new(big.Int).Exp(radix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(radix, big.NewInt(int64(v)), nil)
+
+	// Bytes needed for a numeral string of length v, and for the round value
+	bLen := (new(big.Int).Sub(modV, big.NewInt(1)).BitLen() + 7) / 8
+	dLen := 4*((bLen+3)/4) + 4
+
+	p := make([]byte, aes.BlockSize)
+	p[0], p[1], p[2] = 1, 2, 1
+	p[3], p[4], p[5] = byte(f.radix>>16), byte(f.radix>>8), byte(f.radix)
+	p[6], p[7] = 10, byte(u)
+	binary.BigEndian.PutUint32(p[8:12], uint32(n))
+	binary.BigEndian.PutUint32(p[12:16], uint32(len(tweak)))
+
+	// Q is the tweak, zero padding, the round number and the numeral half
+	pad := (((-len(tweak) - bLen - 1) % aes.BlockSize) + aes.BlockSize) % aes.BlockSize
+	q := make([]byte, len(tweak)+pad+1+bLen)
+	copy(q, tweak)
+
+	for round := 0; round < ff1Rounds; round++ {
+		i := round
+		if !encrypt {
+			i = ff1Rounds - 1 - round
+		}
+
+		// The half that feeds the round function: B when encrypting, A when decrypting
+		in, out := b, a
+		if !encrypt {
+			in, out = a, b
+		}
+
+		q[len(tweak)+pad] = byte(i)
+		num := numeralsToInt(in, f.radix)
+		numBytes := num.Bytes()
+		for j := range q[len(q)-bLen:] {
+			q[len(q)-bLen+j] = 0
+		}
+		copy(q[len(q)-len(numBytes):], numBytes)
+
+		y := new(big.Int).SetBytes(f.roundValue(p, q, dLen))
+
+		m, mod := u, modU
+		if i%2 == 1 {
+			m, mod = v, modV
+		}
+
+		c := numeralsToInt(out, f.radix)
+		if encrypt {
+			c.Add(c, y)
+		} else {
+			c.Sub(c, y)
+		}
+		c.Mod(c, mod)
+		next := intToNumerals(c, f.radix, m)
+
+		if encrypt {
+			a, b = b, next
+		} else {
+			a, b = next, a
+		}
+	}
+
+	return append(a, b...)
+}
+
+// roundValue computes S, the first dLen bytes of the expanded CBC-MAC of P || Q
+func (f *ff1) roundValue(p, q []byte, dLen int) []byte {
+	r := make([]byte, aes.BlockSize)
+	for _, data := range [][]byte{p, q} {
+		for off := 0; off < len(data); off += aes.BlockSize {
+			for j := 0; j < aes.BlockSize; j++ {
+				r[j] ^= data[off+j]
+			}
+			f.block.Encrypt(r, r)
+		}
+	}
+
+	s := append([]byte(nil), r...)
+	for j := 1; len(s) < dLen; j++ {
+		block := append([]byte(nil), r...)
+		var counter [aes.BlockSize]byte
+		binary.BigEndian.PutUint64(counter[8:], uint64(j))
+		for k := range block {
+			block[k] ^= counter[k]
+		}
+		f.block.Encrypt(block, block)
+		s = append(s, block...)
+	}
+	return s[:dLen]
+}
+
+// numeralsToInt reads a numeral string as a number, most significant first
+func numeralsToInt(x []uint16, radix int) *big.Int {
+	n := new(big.Int)
+	r := big.NewInt(int64(radix))
+	for _, digit := range x {
+		n.Mul(n, r)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	return n
+}
+
+// intToNumerals writes n as a numeral string of length m, most significant first
+func intToNumerals(n *big.Int, radix, m int) []uint16 {
+	x := make([]uint16, m)
+	n = new(big.Int).Set(n)
+	r := big.NewInt(int64(radix))
+	digit := new(big.Int)
+	for i := m - 1; i >= 0; i-- {
+		n.DivMod(n, r, digit)
+		x[i] = uint16(digit.Int64())
+	}
+	return x
+}
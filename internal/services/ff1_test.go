@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFF1_NISTSamples tests FF1 against the NIST SP 800-38G sample vectors
+func TestFF1_NISTSamples(t *testing.T) {
+	const (
+		key128 = "2B7E151628AED2A6ABF7158809CF4F3C"
+		key256 = "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94"
+	)
+
+	tests := []struct {
+		name       string
+		key        string
+		radix      int
+		tweak      string
+		plaintext  string
+		ciphertext string
+	}{
+		{"Sample 1", key128, 10, "", "0123456789", "2433477484"},
+		{"Sample 2", key128, 10, "39383736353433323130", "0123456789", "6124200773"},
+		{"Sample 3", key128, 36, "3737373770717273373737", "0123456789abcdefghi", "a9tv40mll9kdu509eum"},
+		{"Sample 7", key256, 10, "", "0123456789", "6657667009"},
+		{"Sample 8", key256, 10, "39383736353433323130", "0123456789", "1001623463"},
+		{"Sample 9", key256, 36, "3737373770717273373737", "0123456789abcdefghi", "xs8a0azh2avyalyzuwd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := hex.DecodeString(tt.key)
+			require.NoError(t, err)
+			tweak, err := hex.DecodeString(tt.tweak)
+			require.NoError(t, err)
+
+			cipher, err := newFF1(key, tt.radix)
+			require.NoError(t, err)
+
+			encrypted := cipher.encrypt(parseNumerals(t, tt.plaintext, tt.radix), tweak)
+			assert.Equal(t, tt.ciphertext, formatNumerals(encrypted, tt.radix))
+			assert.Equal(t, tt.plaintext, formatNumerals(cipher.decrypt(encrypted, tweak), tt.radix))
+		})
+	}
+}
+
+// parseNumerals reads a numeral string written with the digits 0-9a-z
+func parseNumerals(t *testing.T, s string, radix int) []uint16 {
+	x := make([]uint16, len(s))
+	for i, c := range s {
+		digit, err := strconv.ParseUint(string(c), radix, 16)
+		require.NoError(t, err)
+		x[i] = uint16(digit)
+	}
+	return x
+}
+
+// formatNumerals writes a numeral string with the digits 0-9a-z
+func formatNumerals(x []uint16, radix int) string {
+	var b strings.Builder
+	for _, digit := range x {
+		b.WriteString(strconv.FormatUint(uint64(digit), radix))
+	}
+	return b.String()
+}
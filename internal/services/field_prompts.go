@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// FieldPromptBatchSize is how many rows one follow-up call fills
+const FieldPromptBatchSize = 25
+
+// Rough token costs used to budget field-prompt requests up front
+const (
+	fieldPromptOverheadTokens = 150 // instructions and scenario per call
+	fieldPromptRowTokens      = 60  // a row sent as context plus its answer
+)
+
+// ErrTokenBudgetExceeded is returned when field prompts would cost too many tokens
+var ErrTokenBudgetExceeded = errors.New("field prompts exceed the token budget")
+
+/*
+EstimateFieldPromptTokens approximates the extra tokens spent on follow-up
+calls: every field is filled in batches of FieldPromptBatchSize rows, and each
+call pays a fixed overhead, its mini-prompt (about four characters per token)
+and a per-row cost for the context row and the returned value.
+*/
+func EstimateFieldPromptTokens(rowCount int, prompts map[string]string) int {
+	if rowCount < 1 || len(prompts) == 0 {
+		return 0
+	}
+
+	batches := (rowCount + FieldPromptBatchSize - 1) / FieldPromptBatchSize
+
+	total := 0
+	for _, prompt := range prompts {
+		perCall := fieldPromptOverheadTokens + len(prompt)/4
+		total += batches*perCall + rowCount*fieldPromptRowTokens
+	}
+	return total
+}
+
+// CheckFieldPromptBudget rejects field prompts whose estimate exceeds budget
+func CheckFieldPromptBudget(rowCount int, prompts map[string]string, budget int) error {
+	estimate := EstimateFieldPromptTokens(rowCount, prompts)
+	if estimate > budget {
+		return fmt.Errorf("%w: about %d tokens needed, budget is %d", ErrTokenBudgetExceeded, estimate, budget)
+	}
+	return nil
+}
+
+/*
+fillFields overwrites the prompted fields of every row with values from
+follow-up calls, one field and one batch of rows at a time. Rows are sent
+without the field being filled so the model can keep values consistent with
+the rest of the record. Fields missing from the skeleton are appended to the
+returned field list.
+*/
+func (s *OpenAIService) fillFields(ctx context.Context, model, scenario string, data []map[string]interface{}, fields []string, opts GenerateOptions) ([]string, error) {
+	prompts := opts.FieldPrompts
+
+	// Sorted for a stable call order
+	names := make([]string, 0, len(prompts))
+	for name := range prompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for start := 0; start < len(data); start += FieldPromptBatchSize {
+			end := start + FieldPromptBatchSize
+			if end > len(data) {
+				end = len(data)
+			}
+
+			if err := s.fillBatch(ctx, model, scenario, name, prompts[name], data[start:end], opts); err != nil {
+				return nil, fmt.Errorf("field %q rows %d-%d: %w", name, start+1, end, err)
+			}
+		}
+
+		if !containsString(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+
+	log.Printf("🧩 Filled %d fields with per-field prompts", len(names))
+
+	return fields, nil
+}
+
+// fillBatch asks for one value of field per row and stores them in order
+func (s *OpenAIService) fillBatch(ctx context.Context, model, scenario, field, fieldPrompt string, rows []map[string]interface{}, opts GenerateOptions) error {
+	content, err := s.complete(ctx, model, "", buildFieldPrompt(scenario, field, fieldPrompt, rows), opts)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Values []interface{} `json:"values"`
+	}
+	if err := DecodeJSON([]byte(content), &result, s.useNumber); err != nil {
+		return fmt.Errorf("failed to parse OpenAI response as JSON: %w (response: %s)", err, s.redactor.Text(content))
+	}
+
+	if len(result.Values) != len(rows) {
+		return fmt.Errorf("expected %d values, got %d", len(rows), len(result.Values))
+	}
+
+	for i, row := range rows {
+		row[field] = result.Values[i]
+	}
+	return nil
+}
+
+// buildFieldPrompt constructs the follow-up prompt for one field and batch
+func buildFieldPrompt(scenario, field, fieldPrompt string, rows []map[string]interface{}) string {
+	records := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		records[i] = make(map[string]interface{}, len(row))
+		for key, value := range row {
+			if key != field {
+				records[i][key] = value
+			}
+		}
+	}
+	encoded, _ := json.Marshal(records)
+
+	return fmt.Sprintf(`Scenario: "%s"
+
+Generate a value for the field %q of each of the following %d records.
+Instructions for this field: %s
+
+Records:
+%s
+
+Return ONLY a valid JSON object of the form {"values": [...]} with exactly %d values, in the same order as the records.`, scenario, field, len(rows), fieldPrompt, encoded, len(rows))
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedChatClient returns one canned reply per call and records the prompts
+type scriptedChatClient struct {
+	replies []string
+	prompts []string
+}
+
+func (f *scriptedChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.prompts = append(f.prompts, request.Messages[len(request.Messages)-1].Content)
+	if len(f.replies) == 0 {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("unexpected call %d", len(f.prompts))
+	}
+
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: reply},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}, nil
+}
+
+// skeleton returns a generation reply with n rows of ids
+func skeleton(n int) string {
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = fmt.Sprintf(`{"id": %d, "bio": ""}`, i+1)
+	}
+	return fmt.Sprintf(`{"fields": ["id", "bio"], "data": [%s]}`, strings.Join(rows, ","))
+}
+
+// values returns a field-prompt reply with n numbered values
+func values(prefix string, n int) string {
+	quoted := make([]string, n)
+	for i := range quoted {
+		quoted[i] = fmt.Sprintf("%q", fmt.Sprintf("%s%d", prefix, i+1))
+	}
+	return fmt.Sprintf(`{"values": [%s]}`, strings.Join(quoted, ","))
+}
+
+// TestGenerateMockDataFieldPrompts tests the skeleton-then-fill orchestration
+func TestGenerateMockDataFieldPrompts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Fills fields in batches", func(t *testing.T) {
+		rows := FieldPromptBatchSize + 5
+		client := &scriptedChatClient{replies: []string{
+			skeleton(rows),
+			values("bio", FieldPromptBatchSize), values("bio", 5),
+			values("motto", FieldPromptBatchSize), values("motto", 5),
+		}}
+		svc := &OpenAIService{client: client}
+
+		data, fields, err := svc.GenerateMockData(ctx, "people", rows, GenerateOptions{
+			FieldPrompts: map[string]string{"motto": "a short motto", "bio": "a two sentence bio"},
+		})
+		require.NoError(t, err)
+		require.Len(t, client.prompts, 5)
+
+		assert.Equal(t, []string{"id", "bio", "motto"}, fields)
+		assert.Equal(t, "bio1", data[0]["bio"])
+		assert.Equal(t, "bio5", data[rows-1]["bio"])
+		assert.Equal(t, "motto1", data[FieldPromptBatchSize]["motto"])
+
+		// Skeleton prompt announces the fields, follow-ups carry their mini-prompts
+		assert.Contains(t, client.prompts[0], `["bio","motto"]`)
+		assert.Contains(t, client.prompts[1], "a two sentence bio")
+		assert.Contains(t, client.prompts[3], "a short motto")
+	})
+
+	t.Run("Context rows omit the filled field", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{skeleton(1), values("bio", 1)}}
+		svc := &OpenAIService{client: client}
+
+		_, _, err := svc.GenerateMockData(ctx, "people", 1, GenerateOptions{
+			FieldPrompts: map[string]string{"bio": "a bio"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, client.prompts[1], `[{"id":1}]`)
+	})
+
+	t.Run("Value count mismatch", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{skeleton(2), values("bio", 1)}}
+		svc := &OpenAIService{client: client}
+
+		_, _, err := svc.GenerateMockData(ctx, "people", 2, GenerateOptions{
+			FieldPrompts: map[string]string{"bio": "a bio"},
+		})
+		assert.ErrorContains(t, err, "expected 2 values, got 1")
+	})
+
+	t.Run("Follow-up errors propagate", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{skeleton(1), " "}}
+		svc := &OpenAIService{client: client}
+
+		_, _, err := svc.GenerateMockData(ctx, "people", 1, GenerateOptions{
+			FieldPrompts: map[string]string{"bio": "a bio"},
+		})
+		assert.ErrorIs(t, err, ErrEmptyContent)
+	})
+
+	t.Run("No prompts makes a single call", func(t *testing.T) {
+		client := &scriptedChatClient{replies: []string{skeleton(3)}}
+		svc := &OpenAIService{client: client}
+
+		_, _, err := svc.GenerateMockData(ctx, "people", 3, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Len(t, client.prompts, 1)
+	})
+}
+
+// TestCheckFieldPromptBudget tests the token estimate and budget check
+func TestCheckFieldPromptBudget(t *testing.T) {
+	prompts := map[string]string{"bio": strings.Repeat("x", 40)}
+
+	assert.Equal(t, 0, EstimateFieldPromptTokens(10, nil))
+
+	// One batch: overhead + prompt/4, plus the per-row cost
+	assert.Equal(t, fieldPromptOverheadTokens+10+10*fieldPromptRowTokens, EstimateFieldPromptTokens(10, prompts))
+
+	// Costs grow with rows and with the number of fields
+	assert.Greater(t, EstimateFieldPromptTokens(100, prompts), EstimateFieldPromptTokens(10, prompts))
+	two := map[string]string{"bio": "x", "motto": "y"}
+	assert.Greater(t, EstimateFieldPromptTokens(10, two), EstimateFieldPromptTokens(10, map[string]string{"bio": "x"}))
+
+	assert.NoError(t, CheckFieldPromptBudget(10, prompts, 10000))
+	assert.ErrorIs(t, CheckFieldPromptBudget(1000, prompts, 10000), ErrTokenBudgetExceeded)
+}
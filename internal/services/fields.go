@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrFieldMismatch is returned by strict exports when rows have unlisted keys
+var ErrFieldMismatch = errors.New("rows contain fields that are not in the field list")
+
+// Strict field modes accepted by ExportOptions.StrictFields
+const (
+	StrictFieldsOff     = ""        // ignore unlisted keys (historical behavior)
+	StrictFieldsError   = "error"   // fail the export
+	StrictFieldsInclude = "include" // export unlisted keys as extra columns
+)
+
+// maxReportedMismatches caps how many rows are listed in a mismatch error
+const maxReportedMismatches = 10
+
+// FieldMismatch lists the unlisted keys of one row (1-based)
+type FieldMismatch struct {
+	Row    int
+	Fields []string
+}
+
+// FieldMismatches returns the rows that contain keys outside fieldNames
+func FieldMismatches(data []map[string]interface{}, fieldNames []string) []FieldMismatch {
+	known := make(map[string]bool, len(fieldNames))
+	for _, field := range fieldNames {
+		known[field] = true
+	}
+
+	mismatches := []FieldMismatch{}
+	for i, row := range data {
+		extra := []string{}
+		for key := range row {
+			if !known[key] {
+				extra = append(extra, key)
+			}
+		}
+		if len(extra) > 0 {
+			sort.Strings(extra)
+			mismatches = append(mismatches, FieldMismatch{Row: i + 1, Fields: extra})
+		}
+	}
+	return mismatches
+}
+
+/*
+resolveFields applies the strict field mode before exporting.
+
+In error mode any unlisted key fails the export with a report of the
+offending rows; in include mode unlisted keys are appended as extra columns
+in order of first appearance.
+*/
+func (o ExportOptions) resolveFields(data []map[string]interface{}, fieldNames []string) ([]string, error) {
+	if o.StrictFields == StrictFieldsOff {
+		return fieldNames, nil
+	}
+
+	mismatches := FieldMismatches(data, fieldNames)
+	if len(mismatches) == 0 {
+		return fieldNames, nil
+	}
+
+	if o.StrictFields == StrictFieldsError {
+		return nil, fmt.Errorf("%w: %s", ErrFieldMismatch, describeMismatches(mismatches))
+	}
+
+	fields := append([]string{}, fieldNames...)
+	added := map[string]bool{}
+	for _, mismatch := range mismatches {
+		for _, field := range mismatch.Fields {
+			if !added[field] {
+				added[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// describeMismatches formats mismatches as "row 2: [x y]; row 5: [z]"
+func describeMismatches(mismatches []FieldMismatch) string {
+	parts := []string{}
+	for i, mismatch := range mismatches {
+		if i == maxReportedMismatches {
+			parts = append(parts, fmt.Sprintf("and %d more rows", len(mismatches)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("row %d: %v", mismatch.Row, mismatch.Fields))
+	}
+	return strings.Join(parts, "; ")
+}
+
+/*
+CheckFieldConsistency verifies that the field list and the row keys agree.
+
+Field names must be unique and every key of every row must be a listed
+field. Rows may omit a field; exports treat that the same as null.
+*/
+func CheckFieldConsistency(data []map[string]interface{}, fieldNames []string) error {
+	known := make(map[string]bool, len(fieldNames))
+	for _, field := range fieldNames {
+		if known[field] {
+			return fmt.Errorf("%w: field '%s' is listed twice", ErrInconsistentFields, field)
+		}
+		known[field] = true
+	}
+
+	for i, row := range data {
+		for key := range row {
+			if !known[key] {
+				return fmt.Errorf("%w: row %d has unknown key '%s'", ErrInconsistentFields, i+1, key)
+			}
+		}
+	}
+
+	return nil
+}
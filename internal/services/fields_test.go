@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckFieldConsistency tests that row keys must match the field list
+func TestCheckFieldConsistency(t *testing.T) {
+	fieldNames := []string{"名前", "年齢"}
+
+	assert.NoError(t, CheckFieldConsistency([]map[string]interface{}{
+		{"名前": "田中", "年齢": float64(30)},
+		{"名前": "佐藤"},
+	}, fieldNames), "Missing keys count as null")
+
+	err := CheckFieldConsistency([]map[string]interface{}{
+		{"名前": "田中", "年齢": float64(30)},
+		{"name": "Sato", "年齢": float64(41)},
+	}, fieldNames)
+	assert.ErrorIs(t, err, ErrInconsistentFields, "Untranslated key")
+	assert.Contains(t, err.Error(), "row 2")
+
+	err = CheckFieldConsistency([]map[string]interface{}{{"名前": "田中"}}, []string{"名前", "名前"})
+	assert.ErrorIs(t, err, ErrInconsistentFields, "Duplicate field")
+}
+
+// TestGenerateMockData_FieldNameLanguage tests key validation for translated field names
+func TestGenerateMockData_FieldNameLanguage(t *testing.T) {
+	svc := newFakeService(`{"fields": ["Name", "Alter"], "data": [{"Name": "Anna", "age": 30}]}`, openai.FinishReasonStop)
+
+	_, _, err := svc.GenerateMockData(context.Background(), "users", 1, GenerateOptions{FieldNameLanguage: "German"})
+	assert.ErrorIs(t, err, ErrInconsistentFields)
+
+	prompt := buildPrompt("users", 1, GenerateOptions{FieldNameLanguage: "German"})
+	assert.Contains(t, prompt, "Write every field name in German")
+}
+
+// TestExportService_StrictFields tests the strict field modes
+func TestExportService_StrictFields(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John"},
+		{"id": float64(2), "name": "Jane", "nickname": "JJ", "age": float64(30)},
+		{"id": float64(3), "name": "Max", "nickname": "M"},
+	}
+	fieldNames := []string{"id", "name"}
+
+	// Lenient by default
+	result, err := NewExportService().ToCSV(data, fieldNames)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n1,John\n2,Jane\n3,Max\n", string(result))
+
+	opts := DefaultExportOptions()
+	opts.StrictFields = StrictFieldsError
+	_, err = NewExportService().WithOptions(opts).ToSQL(data, fieldNames, "users")
+	assert.ErrorIs(t, err, ErrFieldMismatch)
+	assert.Contains(t, err.Error(), "row 2: [age nickname]; row 3: [nickname]")
+
+	opts.StrictFields = StrictFieldsInclude
+	result, err = NewExportService().WithOptions(opts).ToCSV(data, fieldNames)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name,age,nickname\n1,John,,\n2,Jane,30,JJ\n3,Max,,M\n", string(result))
+
+	// Consistent rows pass in strict mode
+	opts.StrictFields = StrictFieldsError
+	_, err = NewExportService().WithOptions(opts).ToJSON(data[:1], fieldNames)
+	assert.NoError(t, err)
+}
+
+// TestDescribeMismatches tests that long mismatch reports are truncated
+func TestDescribeMismatches(t *testing.T) {
+	mismatches := make([]FieldMismatch, 12)
+	for i := range mismatches {
+		mismatches[i] = FieldMismatch{Row: i + 1, Fields: []string{"x"}}
+	}
+
+	report := describeMismatches(mismatches)
+	assert.Contains(t, report, "row 10: [x]; and 2 more rows")
+	assert.NotContains(t, report, "row 11")
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFPEService_RoundTrip tests that decrypting an encrypted value restores it
+func TestFPEService_RoundTrip(t *testing.T) {
+	service := NewFPEService("test-key")
+	require.NotNil(t, service)
+
+	values := []string{
+		"John Doe",
+		"jane.smith@example.com",
+		"+1 (555) 123-4567",
+		"AB-1234-xy",
+		"Zoë Ünal",
+		"",
+		"7",
+		"a",
+		strings.Repeat("Long value 42 ", 70),
+	}
+
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			encrypted := service.Encrypt("name", value)
+			assert.Equal(t, value, service.Decrypt("name", encrypted))
+		})
+	}
+}
+
+// TestFPEService_PreservesFormat tests that character classes and separators are kept
+func TestFPEService_PreservesFormat(t *testing.T) {
+	service := NewFPEService("test-key")
+
+	value := "Jane.Smith-42@example.com"
+	encrypted := service.Encrypt("email", value)
+
+	assert.NotEqual(t, value, encrypted, "Value should be masked")
+	require.Len(t, encrypted, len(value))
+
+	for i := range value {
+		orig, enc := value[i], encrypted[i]
+		switch {
+		case orig >= '0' && orig <= '9':
+			assert.True(t, enc >= '0' && enc <= '9', "Digit should stay a digit")
+		case orig >= 'a' && orig <= 'z':
+			assert.True(t, enc >= 'a' && enc <= 'z', "Lowercase should stay lowercase")
+		case orig >= 'A' && orig <= 'Z':
+			assert.True(t, enc >= 'A' && enc <= 'Z', "Uppercase should stay uppercase")
+		default:
+			assert.Equal(t, orig, enc, "Separators should be untouched")
+		}
+	}
+}
+
+// TestFPEService_KeyAndFieldDependence tests that the key and field name change the output
+func TestFPEService_KeyAndFieldDependence(t *testing.T) {
+	a := NewFPEService("key-a")
+	b := NewFPEService("key-b")
+
+	value := "Alexander Hamilton"
+	assert.NotEqual(t, a.Encrypt("name", value), b.Encrypt("name", value), "Different keys should differ")
+	assert.NotEqual(t, a.Encrypt("name", value), a.Encrypt("alias", value), "Different fields should differ")
+	assert.NotEqual(t, value, b.Decrypt("name", a.Encrypt("name", value)), "Wrong key should not decrypt")
+}
+
+// TestFPEService_NoSharedKeystream tests that one known pair says nothing
+// about other values of the same length, unlike a per-position shift
+func TestFPEService_NoSharedKeystream(t *testing.T) {
+	service := NewFPEService("test-key")
+
+	shifts := func(value string) []int {
+		encrypted := service.Encrypt("code", value)
+		diff := make([]int, len(value))
+		for i := range value {
+			diff[i] = (int(encrypted[i]) - int(value[i]) + 26) % 26
+		}
+		return diff
+	}
+
+	assert.NotEqual(t, shifts("ABCDEFGH"), shifts("QRSTUVWX"))
+	assert.NotEqual(t, service.Encrypt("code", "ABCDEFGH")[1:], service.Encrypt("code", "ZBCDEFGH")[1:], "Changing one character changes the others")
+}
+
+// TestFPEService_Rows tests row-level encryption of selected fields
+func TestFPEService_Rows(t *testing.T) {
+	service := NewFPEService("test-key")
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John", "email": "john@example.com"},
+		{"id": float64(2), "name": "Jane", "email": nil},
+	}
+
+	service.EncryptRows(data, []string{"name", "email", "id"})
+
+	assert.NotEqual(t, "John", data[0]["name"])
+	assert.Equal(t, float64(1), data[0]["id"], "Non-string values should pass through")
+	assert.Nil(t, data[1]["email"], "Nulls should pass through")
+
+	service.DecryptRows(data, []string{"name", "email", "id"})
+
+	assert.Equal(t, "John", data[0]["name"])
+	assert.Equal(t, "john@example.com", data[0]["email"])
+	assert.Equal(t, "Jane", data[1]["name"])
+}
+
+// TestNewFPEService_NoKey tests that encryption is disabled without a key
+func TestNewFPEService_NoKey(t *testing.T) {
+	assert.Nil(t, NewFPEService(""))
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+/*
+GenerationCache keeps recent generator output in memory so identical
+requests don't pay for the same completion twice.
+
+Entries expire after the TTL and the least recently used one is evicted
+once the cache is full. Rows are copied in and out, since the handler
+replaces values in place (patterns, edge cases, encryption); values
+themselves are shared and must not be modified.
+*/
+type GenerationCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries *list.List // most recently used first
+	index   map[string]*list.Element
+	now     func() time.Time
+}
+
+type generationCacheEntry struct {
+	key     string
+	data    []map[string]interface{}
+	fields  []string
+	expires time.Time
+}
+
+// NewGenerationCache creates a cache of up to size entries kept for ttl
+func NewGenerationCache(size int, ttl time.Duration) *GenerationCache {
+	return &GenerationCache{
+		size:    size,
+		ttl:     ttl,
+		entries: list.New(),
+		index:   map[string]*list.Element{},
+		now:     time.Now,
+	}
+}
+
+// GenerationCacheKey identifies a generation by everything that changes its
+// output; an empty model and nil temperature stand for the server defaults
+func GenerationCacheKey(scenario string, rowCount int, model string, temperature *float64, seed *int) string {
+	key, _ := json.Marshal([]interface{}{scenario, rowCount, model, temperature, seed})
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns a copy of the cached data and fields for key, if present and fresh
+func (c *GenerationCache) Get(key string) ([]map[string]interface{}, []string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.index[key]
+	if !ok {
+		return nil, nil, false
+	}
+
+	entry := element.Value.(*generationCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.entries.Remove(element)
+		delete(c.index, key)
+		return nil, nil, false
+	}
+
+	c.entries.MoveToFront(element)
+	return copyRows(entry.data), append([]string{}, entry.fields...), true
+}
+
+// Put stores a copy of data and fields under key, evicting the least
+// recently used entry when the cache is full
+func (c *GenerationCache) Put(key string, data []map[string]interface{}, fields []string) {
+	if c.size < 1 || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &generationCacheEntry{key: key, data: copyRows(data), fields: append([]string{}, fields...), expires: c.now().Add(c.ttl)}
+
+	if element, ok := c.index[key]; ok {
+		element.Value = entry
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.index[key] = c.entries.PushFront(entry)
+	for c.entries.Len() > c.size {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.index, oldest.Value.(*generationCacheEntry).key)
+	}
+}
+
+// Len returns the number of cached entries, including expired ones not yet dropped
+func (c *GenerationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+// copyRows returns new row maps with the same values
+func copyRows(data []map[string]interface{}) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(data))
+	for i, row := range data {
+		rows[i] = make(map[string]interface{}, len(row))
+		for key, value := range row {
+			rows[i][key] = value
+		}
+	}
+	return rows
+}
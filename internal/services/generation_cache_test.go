@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerationCache tests expiry, eviction and copying of cached rows
+func TestGenerationCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewGenerationCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	rows := []map[string]interface{}{{"id": 1, "name": "Ann"}}
+	cache.Put("a", rows, []string{"id", "name"})
+
+	t.Run("Hit returns a copy", func(t *testing.T) {
+		data, fields, ok := cache.Get("a")
+		require.True(t, ok)
+		assert.Equal(t, rows, data)
+		assert.Equal(t, []string{"id", "name"}, fields)
+
+		data[0]["name"] = "changed"
+		fields[0] = "changed"
+		again, fields, _ := cache.Get("a")
+		assert.Equal(t, "Ann", again[0]["name"], "Changes to returned rows don't reach the cache")
+		assert.Equal(t, "id", fields[0])
+
+		rows[0]["name"] = "changed too"
+		again, _, _ = cache.Get("a")
+		assert.Equal(t, "Ann", again[0]["name"], "Changes to stored rows don't reach the cache")
+	})
+
+	t.Run("Least recently used is evicted", func(t *testing.T) {
+		cache.Put("b", rows, nil)
+		cache.Get("a")
+		cache.Put("c", rows, nil)
+
+		assert.Equal(t, 2, cache.Len())
+		_, _, ok := cache.Get("b")
+		assert.False(t, ok)
+		_, _, ok = cache.Get("a")
+		assert.True(t, ok)
+	})
+
+	t.Run("Entries expire", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, _, ok := cache.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 1, cache.Len(), "Expired entries are dropped when read")
+	})
+
+	t.Run("Disabled cache stores nothing", func(t *testing.T) {
+		disabled := NewGenerationCache(0, time.Minute)
+		disabled.Put("a", rows, nil)
+		assert.Zero(t, disabled.Len())
+	})
+}
+
+// TestGenerationCacheKey tests which settings distinguish cache entries
+func TestGenerationCacheKey(t *testing.T) {
+	low, high := 0.2, 1.2
+	zero, seed := 0, 42
+	key := GenerationCacheKey("users", 10, "", nil, nil)
+
+	assert.Equal(t, key, GenerationCacheKey("users", 10, "", nil, nil))
+	assert.NotEqual(t, key, GenerationCacheKey("users ", 10, "", nil, nil))
+	assert.NotEqual(t, key, GenerationCacheKey("users", 11, "", nil, nil))
+	assert.NotEqual(t, key, GenerationCacheKey("users", 10, "gpt-4o", nil, nil))
+	assert.NotEqual(t, key, GenerationCacheKey("users", 10, "", &low, nil))
+	assert.NotEqual(t, GenerationCacheKey("users", 10, "", &low, nil), GenerationCacheKey("users", 10, "", &high, nil))
+	assert.NotEqual(t, key, GenerationCacheKey("users", 10, "", nil, &zero), "A zero seed is a seed")
+	assert.NotEqual(t, GenerationCacheKey("users", 10, "", nil, &zero), GenerationCacheKey("users", 10, "", nil, &seed))
+}
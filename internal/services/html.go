@@ -0,0 +1,44 @@
+package services
+
+import (
+	"bytes"
+	"html"
+)
+
+/*
+ToHTMLTable exports data as an HTML <table> fragment for pasting into wikis
+and pages.
+
+Field names and values are HTML-escaped, so values containing markup are
+shown as text. Values are formatted like CSV (boolean labels, null_as).
+*/
+func (s *ExportService) ToHTMLTable(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("<table>\n<thead>\n<tr>")
+	for _, field := range fieldNames {
+		buf.WriteString("<th>" + html.EscapeString(field) + "</th>")
+	}
+	buf.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	for _, row := range data {
+		buf.WriteString("<tr>")
+		for _, field := range fieldNames {
+			buf.WriteString("<td>" + html.EscapeString(s.options.formatValue(row[field])) + "</td>")
+		}
+		buf.WriteString("</tr>\n")
+	}
+
+	buf.WriteString("</tbody>\n</table>\n")
+
+	return buf.Bytes(), nil
+}
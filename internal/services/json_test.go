@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecodeJSON tests that UseNumber keeps large integers exact
+func TestDecodeJSON(t *testing.T) {
+	input := []byte(`[{"id": 9007199254740993, "big": 99999999999999999999, "price": 9.99}]`)
+
+	var exact []map[string]interface{}
+	require.NoError(t, DecodeJSON(input, &exact, true))
+	assert.Equal(t, json.Number("9007199254740993"), exact[0]["id"])
+
+	var lossy []map[string]interface{}
+	require.NoError(t, DecodeJSON(input, &lossy, false))
+	assert.IsType(t, float64(0), lossy[0]["id"])
+
+	assert.Error(t, DecodeJSON([]byte(`{"a": 1} trailing`), &exact, true), "Trailing data is rejected")
+}
+
+// TestExportService_LargeIntegers tests exporting json.Number values without precision loss
+func TestExportService_LargeIntegers(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": json.Number("9007199254740993"), "big": json.Number("99999999999999999999"), "price": json.Number("9.99")},
+	}
+	fieldNames := []string{"id", "big", "price"}
+	service := NewExportService()
+
+	csvData, err := service.ToCSV(data, fieldNames)
+	require.NoError(t, err)
+	assert.Contains(t, string(csvData), "9007199254740993,99999999999999999999,9.99")
+
+	sqlData, err := service.ToSQL(data, fieldNames, "items")
+	require.NoError(t, err)
+	assert.Contains(t, string(sqlData), "  id NUMERIC PRIMARY KEY,")
+	assert.Contains(t, string(sqlData), "VALUES (9007199254740993, 99999999999999999999, 9.99);")
+
+	jsonData, err := service.ToJSON(data, fieldNames)
+	require.NoError(t, err)
+	assert.Contains(t, string(jsonData), `"id": 9007199254740993`)
+	assert.NotContains(t, string(jsonData), "e+")
+
+	assert.Equal(t, "9007199254740993", formatValue(json.Number("9007199254740993")))
+	assert.Equal(t, "9007199254740993", formatSQLValue(json.Number("9007199254740993")))
+}
+
+// TestGenerateMockData_UseNumber tests that generated integers stay exact
+func TestGenerateMockData_UseNumber(t *testing.T) {
+	svc := newFakeService(`{"fields": ["id"], "data": [{"id": 9999999999999999}]}`, openai.FinishReasonStop)
+	svc.useNumber = true
+
+	data, _, err := svc.GenerateMockData(context.Background(), "ids", 1, GenerateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("9999999999999999"), data[0]["id"])
+}
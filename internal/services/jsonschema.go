@@ -0,0 +1,119 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonSchemaDraft07 identifies the JSON Schema version of ToJSONSchema output
+const jsonSchemaDraft07 = "http://json-schema.org/draft-07/schema#"
+
+/*
+ToJSONSchema describes the dataset as a draft-07 JSON Schema: an array whose
+items are objects with one property per field, in field order.
+
+Properties are typed number, boolean, string, object or array from all of a
+field's values, with "null" added to the type when any value is null; mixed
+fields accept anything. "required" lists the fields whose key is present in
+every row, even when some of those values are null.
+*/
+func (s *ExportService) ToJSONSchema(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	types := inferColumns(data, fieldNames, jsonSchemaType, "")
+	required := []string{}
+
+	// Properties are written by hand to keep field order
+	var properties bytes.Buffer
+	properties.WriteByte('{')
+
+	for i, field := range fieldNames {
+		present, nulls := 0, 0
+		for _, row := range data {
+			value, ok := row[field]
+			if ok {
+				present++
+			}
+			if value == nil {
+				nulls++
+			}
+		}
+		if present == len(data) {
+			required = append(required, field)
+		}
+
+		property := map[string]interface{}{}
+		switch {
+		case nulls == len(data):
+			property["type"] = "null"
+		case types[field] == "":
+			// Mixed types: any value
+		case nulls > 0:
+			property["type"] = []string{types[field], "null"}
+		default:
+			property["type"] = types[field]
+		}
+
+		key, err := json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON Schema: %w", err)
+		}
+		value, err := json.Marshal(property)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON Schema: %w", err)
+		}
+
+		if i > 0 {
+			properties.WriteByte(',')
+		}
+		properties.Write(key)
+		properties.WriteByte(':')
+		properties.Write(value)
+	}
+	properties.WriteByte('}')
+
+	schema := struct {
+		Schema string `json:"$schema"`
+		Type   string `json:"type"`
+		Items  struct {
+			Type       string          `json:"type"`
+			Properties json.RawMessage `json:"properties"`
+			Required   []string        `json:"required"`
+		} `json:"items"`
+	}{Schema: jsonSchemaDraft07, Type: "array"}
+
+	schema.Items.Type = "object"
+	schema.Items.Properties = properties.Bytes()
+	schema.Items.Required = required
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON Schema: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// jsonSchemaType classifies a single non-null value as a JSON Schema type
+func jsonSchemaType(value interface{}) string {
+	switch value.(type) {
+	case float64, json.Number, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compileSchema compiles an exported schema with a draft-07 validator
+func compileSchema(t *testing.T, schema []byte) *jsonschema.Schema {
+	t.Helper()
+	compiled, err := jsonschema.CompileString("export.schema.json", string(schema))
+	require.NoError(t, err, "Export should be a valid JSON Schema")
+	return compiled
+}
+
+// decodeJSON decodes JSON the way a client receives it
+func decodeJSON(t *testing.T, raw []byte) interface{} {
+	t.Helper()
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	return decoded
+}
+
+// TestExportService_ToJSONSchema tests the schema and that it validates the data
+func TestExportService_ToJSONSchema(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John", "active": true, "email": "john@example.com", "tags": []interface{}{"a"}, "extra": "x"},
+		{"id": float64(2), "name": "Jane", "active": false, "email": nil, "tags": []interface{}{}},
+	}
+	fieldNames := []string{"id", "name", "active", "email", "tags", "extra"}
+
+	result, err := NewExportService().ToJSONSchema(data, fieldNames)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "array",
+  "items": {
+    "type": "object",
+    "properties": {
+      "id": {
+        "type": "number"
+      },
+      "name": {
+        "type": "string"
+      },
+      "active": {
+        "type": "boolean"
+      },
+      "email": {
+        "type": [
+          "string",
+          "null"
+        ]
+      },
+      "tags": {
+        "type": "array"
+      },
+      "extra": {
+        "type": [
+          "string",
+          "null"
+        ]
+      }
+    },
+    "required": [
+      "id",
+      "name",
+      "active",
+      "email",
+      "tags"
+    ]
+  }
+}
+`, string(result))
+
+	schema := compileSchema(t, result)
+
+	// The rows of the JSON export, as a client would receive them, validate
+	exported, err := NewExportService().ToJSON(data, fieldNames)
+	require.NoError(t, err)
+	assert.NoError(t, schema.Validate(decodeJSON(t, exported).(map[string]interface{})["data"]))
+
+	// And the schema is strict enough to reject wrong shapes
+	for _, invalid := range []string{
+		`[{"id": "1", "name": "John", "active": true, "email": null, "tags": []}]`,
+		`[{"id": 1, "active": true, "email": null, "tags": []}]`,
+		`[{"id": 1, "name": "John", "active": "yes", "email": null, "tags": []}]`,
+		`[{"id": 1, "name": "John", "active": true, "email": null, "tags": {}}]`,
+		`{"id": 1}`,
+	} {
+		var validationErr *jsonschema.ValidationError
+		assert.ErrorAs(t, schema.Validate(decodeJSON(t, []byte(invalid))), &validationErr, invalid)
+	}
+}
+
+// TestExportService_ToJSONSchema_Mixed tests mixed and all-null fields
+func TestExportService_ToJSONSchema_Mixed(t *testing.T) {
+	data := []map[string]interface{}{
+		{"code": float64(1), "note": nil},
+		{"code": "A2", "note": nil},
+	}
+
+	result, err := NewExportService().ToJSONSchema(data, []string{"code", "note"})
+	require.NoError(t, err)
+
+	assert.NoError(t, compileSchema(t, result).Validate(decodeJSON(t, []byte(`[{"code": 1, "note": null}, {"code": "A2", "note": null}]`))))
+	assert.Error(t, compileSchema(t, result).Validate(decodeJSON(t, []byte(`[{"code": 1, "note": "x"}]`))), "All-null fields only accept null")
+
+	var schema struct {
+		Items struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(result, &schema))
+	assert.Empty(t, schema.Items.Properties["code"], "Mixed fields accept any value")
+	assert.Equal(t, "null", schema.Items.Properties["note"]["type"])
+}
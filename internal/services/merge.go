@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SourceColumn is the column added by merged exports to identify each row's request
+const SourceColumn = "source_request_id"
+
+// ErrIncompatibleSchemas is returned when merged datasets have different fields
+var ErrIncompatibleSchemas = errors.New("datasets have incompatible fields")
+
+// MergeSource is one dataset taking part in a merged export
+type MergeSource struct {
+	RequestID  int64
+	Data       []map[string]interface{}
+	FieldNames []string
+}
+
+/*
+MergeDatasets stacks the rows of datasets that share the same set of fields
+into one table, in source order. Field order follows the first dataset.
+
+When withSource is set, every row gets a SourceColumn with its request id as
+the first column. Datasets whose field sets differ are rejected with an
+ErrIncompatibleSchemas listing the missing and extra fields of each one.
+Rows are copied, the sources are left untouched.
+*/
+func MergeDatasets(sources []MergeSource, withSource bool) ([]map[string]interface{}, []string, error) {
+	if len(sources) == 0 {
+		return nil, nil, ErrNoData
+	}
+
+	fieldNames := sources[0].FieldNames
+
+	var differences []string
+	for _, source := range sources[1:] {
+		missing := missingFields(fieldNames, source.FieldNames)
+		extra := missingFields(source.FieldNames, fieldNames)
+		if len(missing) == 0 && len(extra) == 0 {
+			continue
+		}
+
+		var parts []string
+		if len(missing) > 0 {
+			parts = append(parts, "missing "+strings.Join(missing, ", "))
+		}
+		if len(extra) > 0 {
+			parts = append(parts, "extra "+strings.Join(extra, ", "))
+		}
+		differences = append(differences, fmt.Sprintf("request %d: %s", source.RequestID, strings.Join(parts, "; ")))
+	}
+
+	if len(differences) > 0 {
+		return nil, nil, fmt.Errorf("%w (compared to request %d): %s", ErrIncompatibleSchemas, sources[0].RequestID, strings.Join(differences, " | "))
+	}
+
+	if withSource {
+		if containsString(fieldNames, SourceColumn) {
+			return nil, nil, fmt.Errorf("%w: datasets already have a '%s' field", ErrInvalidExportOption, SourceColumn)
+		}
+		fieldNames = append([]string{SourceColumn}, fieldNames...)
+	}
+
+	merged := []map[string]interface{}{}
+	for _, source := range sources {
+		// json.Number renders as a plain number in every format
+		sourceID := json.Number(strconv.FormatInt(source.RequestID, 10))
+
+		for _, row := range source.Data {
+			copied := make(map[string]interface{}, len(row)+1)
+			for key, value := range row {
+				copied[key] = value
+			}
+			if withSource {
+				copied[SourceColumn] = sourceID
+			}
+			merged = append(merged, copied)
+		}
+	}
+
+	return merged, fieldNames, nil
+}
+
+// missingFields returns the fields of want that are not in have, in order
+func missingFields(want, have []string) []string {
+	var missing []string
+	for _, field := range want {
+		if !containsString(have, field) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
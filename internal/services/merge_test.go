@@ -0,0 +1,59 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMergeDatasets tests stacking datasets with compatible and incompatible fields
+func TestMergeDatasets(t *testing.T) {
+	first := MergeSource{RequestID: 1, FieldNames: []string{"id", "name"}, Data: []map[string]interface{}{
+		{"id": float64(1), "name": "Jane"},
+	}}
+	second := MergeSource{RequestID: 2, FieldNames: []string{"name", "id"}, Data: []map[string]interface{}{
+		{"id": float64(1), "name": "Ann"},
+		{"id": float64(2), "name": "Bob"},
+	}}
+
+	t.Run("Compatible", func(t *testing.T) {
+		data, fields, err := MergeDatasets([]MergeSource{first, second}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name"}, fields, "Field order follows the first dataset")
+		require.Len(t, data, 3)
+		assert.Equal(t, "Jane", data[0]["name"])
+		assert.Equal(t, "Bob", data[2]["name"])
+	})
+
+	t.Run("Source column", func(t *testing.T) {
+		data, fields, err := MergeDatasets([]MergeSource{first, second}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{SourceColumn, "id", "name"}, fields)
+		assert.Equal(t, json.Number("1"), data[0][SourceColumn])
+		assert.Equal(t, json.Number("2"), data[2][SourceColumn])
+		assert.NotContains(t, first.Data[0], SourceColumn, "Sources are not modified")
+
+		csv, err := NewExportService().ToCSV(data, fields)
+		require.NoError(t, err)
+		assert.Equal(t, "source_request_id,id,name\n1,1,Jane\n2,1,Ann\n2,2,Bob\n", string(csv))
+	})
+
+	t.Run("Incompatible", func(t *testing.T) {
+		third := MergeSource{RequestID: 3, FieldNames: []string{"id", "email"}}
+		fourth := MergeSource{RequestID: 4, FieldNames: []string{"id", "name", "age"}}
+
+		_, _, err := MergeDatasets([]MergeSource{first, second, third, fourth}, false)
+		assert.ErrorIs(t, err, ErrIncompatibleSchemas)
+		assert.ErrorContains(t, err, "request 3: missing name; extra email")
+		assert.ErrorContains(t, err, "request 4: extra age")
+		assert.NotContains(t, err.Error(), "request 2")
+	})
+
+	t.Run("Source column clash", func(t *testing.T) {
+		clash := MergeSource{RequestID: 5, FieldNames: []string{SourceColumn}}
+		_, _, err := MergeDatasets([]MergeSource{clash}, true)
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+	})
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+ExportMetadata describes how a dataset was generated.
+
+It is embedded as a "_meta" object in JSON exports and written as comment
+lines at the top of CSV and SQL exports, always with the same fields.
+RowCount is filled in by the exporter with the number of exported rows.
+*/
+type ExportMetadata struct {
+	Scenario    string    `json:"scenario"`
+	Model       string    `json:"model,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	RowCount    int       `json:"row_count"`
+}
+
+// forRows returns a copy of the metadata describing the given rows
+func (m ExportMetadata) forRows(data []map[string]interface{}) ExportMetadata {
+	m.RowCount = len(data)
+	return m
+}
+
+// commentLines renders the metadata as "key: value" lines with the given prefix
+func (m ExportMetadata) commentLines(prefix string) string {
+	var b strings.Builder
+
+	// Line breaks in the scenario would end the comment early
+	scenario := strings.Join(strings.Fields(m.Scenario), " ")
+
+	b.WriteString(fmt.Sprintf("%sscenario: %s\n", prefix, scenario))
+	if m.Model != "" {
+		b.WriteString(fmt.Sprintf("%smodel: %s\n", prefix, m.Model))
+	}
+	b.WriteString(fmt.Sprintf("%sgenerated_at: %s\n", prefix, m.GeneratedAt.UTC().Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("%srow_count: %d\n", prefix, m.RowCount))
+
+	return b.String()
+}
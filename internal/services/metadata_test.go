@@ -0,0 +1,72 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func metadataOptions() ExportOptions {
+	opts := DefaultExportOptions()
+	opts.Metadata = &ExportMetadata{
+		Scenario:    "Users with\ncontact info",
+		Model:       "gpt-4o-mini",
+		GeneratedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		RowCount:    99, // replaced by the exported row count
+	}
+	return opts
+}
+
+var metadataRows = []map[string]interface{}{
+	{"id": float64(1), "name": "John"},
+	{"id": float64(2), "name": "Jane"},
+}
+
+// TestExportMetadata_JSON tests the _meta block in JSON exports
+func TestExportMetadata_JSON(t *testing.T) {
+	result, err := NewExportService().WithOptions(metadataOptions()).ToJSON(metadataRows, []string{"id", "name"})
+	require.NoError(t, err)
+
+	var parsed struct {
+		Meta ExportMetadata `json:"_meta"`
+	}
+	require.NoError(t, json.Unmarshal(result, &parsed))
+	assert.Equal(t, "gpt-4o-mini", parsed.Meta.Model)
+	assert.Equal(t, 2, parsed.Meta.RowCount)
+	assert.True(t, parsed.Meta.GeneratedAt.Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)))
+
+	plain, err := NewExportService().ToJSON(metadataRows, []string{"id", "name"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(plain), "_meta", "Metadata is opt-in")
+}
+
+// TestExportMetadata_CSV tests leading comment lines in CSV exports
+func TestExportMetadata_CSV(t *testing.T) {
+	result, err := NewExportService().WithOptions(metadataOptions()).ToCSV(metadataRows, []string{"id", "name"})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(result),
+		"# scenario: Users with contact info\n"+
+			"# model: gpt-4o-mini\n"+
+			"# generated_at: 2024-01-15T10:30:00Z\n"+
+			"# row_count: 2\n"+
+			"id,name\n"))
+}
+
+// TestExportMetadata_SQL tests leading comment lines in SQL exports
+func TestExportMetadata_SQL(t *testing.T) {
+	result, err := NewExportService().WithOptions(metadataOptions()).ToSQL(metadataRows, []string{"id", "name"}, "users")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(result),
+		"-- Generated data\n"+
+			"-- scenario: Users with contact info\n"+
+			"-- model: gpt-4o-mini\n"+
+			"-- generated_at: 2024-01-15T10:30:00Z\n"+
+			"-- row_count: 2\n"+
+			"-- Table: users\n"))
+}
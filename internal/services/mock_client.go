@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/sashabaranov/go-openai"
+)
+
+// MockOptions configures the in-process chat client used instead of OpenAI
+// in end-to-end test environments (OPENAI_MODE=mock)
+type MockOptions struct {
+	// Latency is the average response time; each call takes between half
+	// and one and a half times as long
+	Latency time.Duration
+
+	// FailureRate is the share of calls, from 0 to 1, that fail with a
+	// simulated 503 so retry and fallback paths get exercised
+	FailureRate float64
+}
+
+// Prompt shapes the mock client understands; they mirror buildPrompt and buildFieldPrompt
+var (
+	mockGeneratePrompt  = regexp.MustCompile(`(?s)^Generate (\d+) rows of realistic mock data based on the following scenario: "(.*)"\n\nThe "data" array must contain`)
+	mockReferenceField  = regexp.MustCompile(`(?m)^- Include a field named ("(?:[^"\\]|\\.)*") whose values are taken only from: (.*)$`)
+	mockPromptedFields  = regexp.MustCompile(`Include these fields in every row: (\[.*?\])\. Their values`)
+	mockSchemaField     = regexp.MustCompile(`(?m)^- ("(?:[^"\\]|\\.)*"): (string|number|boolean|date)\b`)
+	mockFieldFillPrompt = regexp.MustCompile(`Generate a value for the field ("(?:[^"\\]|\\.)*") of each of the following (\d+) records\.`)
+)
+
+/*
+mockChatClient answers chat completions without calling OpenAI.
+
+Generation prompts get rows from the same keyword-based generator as the
+offline fallback, with reference fields and prompted fields included, and
+per-field prompts get one placeholder value per record. Responses are
+deterministic: the random source of each call is seeded from its prompt, so
+the same request always yields the same data. Only latency and simulated
+failures vary between calls.
+*/
+type mockChatClient struct {
+	opts  MockOptions
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu  sync.Mutex
+	rng *rand.Rand // latency jitter and failures
+}
+
+func newMockChatClient(opts MockOptions) *mockChatClient {
+	return &mockChatClient{
+		opts:  opts,
+		sleep: sleepContext,
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (m *mockChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	m.mu.Lock()
+	latency := time.Duration(0)
+	if m.opts.Latency > 0 {
+		latency = m.opts.Latency/2 + time.Duration(m.rng.Int63n(int64(m.opts.Latency)+1))
+	}
+	fail := m.rng.Float64() < m.opts.FailureRate
+	m.mu.Unlock()
+
+	if err := m.sleep(ctx, latency); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	if fail {
+		return openai.ChatCompletionResponse{}, &openai.APIError{
+			Type:           "server_error",
+			Message:        "simulated OpenAI failure (OPENAI_MODE=mock)",
+			HTTPStatusCode: http.StatusServiceUnavailable,
+		}
+	}
+
+	prompt := ""
+	if len(request.Messages) > 0 {
+		prompt = request.Messages[len(request.Messages)-1].Content
+	}
+
+	content, err := mockCompletion(prompt)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	promptTokens := 0
+	for _, message := range request.Messages {
+		promptTokens += len(message.Content) / 4
+	}
+
+	return openai.ChatCompletionResponse{
+		Model: request.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			FinishReason: openai.FinishReasonStop,
+		}},
+		Usage: openai.Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: len(content) / 4,
+			TotalTokens:      promptTokens + len(content)/4,
+		},
+	}, nil
+}
+
+// mockCompletion returns the JSON answer to a generation or field prompt
+func mockCompletion(prompt string) (string, error) {
+	hash := fnv.New64a()
+	hash.Write([]byte(prompt))
+	rng := rand.New(rand.NewSource(int64(hash.Sum64())))
+
+	var result interface{}
+
+	if match := mockFieldFillPrompt.FindStringSubmatch(prompt); match != nil {
+		field, _ := strconv.Unquote(match[1])
+		count, _ := strconv.Atoi(match[2])
+
+		values := make([]string, count)
+		for i := range values {
+			values[i] = fmt.Sprintf("%s %d", field, rng.Intn(1000000))
+		}
+		result = map[string]interface{}{"values": values}
+	} else if match := mockGeneratePrompt.FindStringSubmatch(prompt); match != nil {
+		rowCount, _ := strconv.Atoi(match[1])
+		var data []map[string]interface{}
+		var fields []string
+		if schema := mockSchema(prompt); len(schema) > 0 {
+			data, fields = schemaRows(rng, schema, rowCount, 0, mockReferences(prompt))
+		} else {
+			data, fields = fakeRows(rng, match[2], rowCount, mockReferences(prompt))
+		}
+
+		// Prompted fields only need placeholders; they are refilled by follow-up calls
+		if prompted := mockPromptedFields.FindStringSubmatch(prompt); prompted != nil {
+			var names []string
+			if err := json.Unmarshal([]byte(prompted[1]), &names); err == nil {
+				for _, name := range names {
+					if !containsString(fields, name) {
+						fields = append(fields, name)
+					}
+					for _, row := range data {
+						row[name] = "placeholder"
+					}
+				}
+			}
+		}
+
+		result = map[string]interface{}{"fields": fields, "data": data}
+	} else {
+		return "", fmt.Errorf("mock OpenAI client does not recognize the prompt")
+	}
+
+	content, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// mockSchema extracts the explicit schema listed in a generation prompt
+func mockSchema(prompt string) []models.SchemaField {
+	var schema []models.SchemaField
+	for _, match := range mockSchemaField.FindAllStringSubmatch(prompt, -1) {
+		name, err := strconv.Unquote(match[1])
+		if err != nil {
+			continue
+		}
+		schema = append(schema, models.SchemaField{Name: name, Type: match[2]})
+	}
+	return schema
+}
+
+// mockReferences extracts the reference values listed in a generation prompt
+func mockReferences(prompt string) map[string][]string {
+	references := map[string][]string{}
+	for _, match := range mockReferenceField.FindAllStringSubmatch(prompt, -1) {
+		field, err := strconv.Unquote(match[1])
+		if err != nil {
+			continue
+		}
+		var values []string
+		if err := json.Unmarshal([]byte(match[2]), &values); err != nil {
+			continue
+		}
+		references[field] = values
+	}
+	return references
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
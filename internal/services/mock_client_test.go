@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockService returns an OpenAI service backed by the mock client without real sleeps
+func newMockService(opts MockOptions, slept *[]time.Duration) *OpenAIService {
+	client := newMockChatClient(opts)
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		return ctx.Err()
+	}
+	noBackoff := func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return &OpenAIService{client: client, sleep: noBackoff}
+}
+
+// TestMockChatClient_Generate tests that mock generation goes through the normal pipeline
+func TestMockChatClient_Generate(t *testing.T) {
+	var slept []time.Duration
+	svc := newMockService(MockOptions{Latency: 100 * time.Millisecond}, &slept)
+	ctx := context.Background()
+
+	opts := GenerateOptions{ReferenceValues: map[string][]string{"customer_name": {"Alice", "Bob"}}}
+	data, fields, err := svc.GenerateMockData(ctx, "customer orders", 8, opts)
+	require.NoError(t, err)
+
+	assert.Len(t, data, 8)
+	assert.Contains(t, fields, "amount", "Fields follow the scenario keywords")
+	assert.Contains(t, fields, "customer_name")
+	for _, row := range data {
+		assert.Contains(t, []interface{}{"Alice", "Bob"}, row["customer_name"])
+	}
+
+	again, _, err := svc.GenerateMockData(ctx, "customer orders", 8, opts)
+	require.NoError(t, err)
+	assert.Equal(t, data, again, "The same request yields the same data")
+
+	require.Len(t, slept, 2)
+	for _, d := range slept {
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 150*time.Millisecond)
+	}
+}
+
+// TestMockChatClient_FieldPrompts tests follow-up calls for prompted fields
+func TestMockChatClient_FieldPrompts(t *testing.T) {
+	var slept []time.Duration
+	svc := newMockService(MockOptions{}, &slept)
+
+	opts := GenerateOptions{FieldPrompts: map[string]string{"bio": "a short bio"}}
+	data, fields, err := svc.GenerateMockData(context.Background(), "users", 30, opts)
+	require.NoError(t, err)
+
+	assert.Contains(t, fields, "bio")
+	for _, row := range data {
+		assert.NotEqual(t, "placeholder", row["bio"], "Prompted fields are filled by the follow-up calls")
+	}
+	assert.Len(t, slept, 3, "One generation call and two batches of field values")
+}
+
+// TestMockChatClient_Failures tests simulated outages
+func TestMockChatClient_Failures(t *testing.T) {
+	var slept []time.Duration
+	svc := newMockService(MockOptions{FailureRate: 1}, &slept)
+
+	_, _, err := svc.GenerateMockData(context.Background(), "users", 5, GenerateOptions{})
+	var apiErr *openai.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 503, apiErr.HTTPStatusCode)
+	assert.Len(t, slept, DefaultMaxAttempts, "Simulated 503s are retried")
+
+	// The fallback takes over, like during a real outage
+	_, _, degraded, err := GenerateWithFallback(context.Background(), svc, NewFakerService(), "users", 5, GenerateOptions{})
+	require.NoError(t, err)
+	assert.True(t, degraded)
+}
+
+// TestMockChatClient_Cancelled tests that latency respects the context
+func TestMockChatClient_Cancelled(t *testing.T) {
+	svc := &OpenAIService{client: newMockChatClient(MockOptions{Latency: time.Hour})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err := svc.GenerateMockData(ctx, "users", 5, GenerateOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
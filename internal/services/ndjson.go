@@ -0,0 +1,58 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+ToNDJSON exports data as newline-delimited JSON (JSON Lines): one compact
+object per row, each terminated by "\n", without the {fields, data, count}
+envelope of ToJSON.
+
+Objects hold exactly the keys in fieldNames, in that order; a key missing
+from a row is written as null.
+*/
+func (s *ExportService) ToNDJSON(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if replacement, ok := s.options.jsonNull(); ok {
+		data = replaceNulls(data, fieldNames, replacement)
+	}
+
+	// Keys never change between rows, so encode them once
+	keys := make([][]byte, len(fieldNames))
+	for i, field := range fieldNames {
+		if keys[i], err = json.Marshal(field); err != nil {
+			return nil, fmt.Errorf("failed to marshal NDJSON: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, row := range data {
+		buf.WriteByte('{')
+		for i := range fieldNames {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			value, err := json.Marshal(row[fieldNames[i]])
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal NDJSON: %w", err)
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteString("}\n")
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultOllamaModel is used when no Ollama model is configured
+const DefaultOllamaModel = "llama3.1"
+
+/*
+OllamaService generates data with a local model served by Ollama, or any
+server with the same OpenAI-compatible /v1/chat/completions endpoint
+(GENERATOR_PROVIDER=ollama), for environments that cannot reach OpenAI.
+
+Like AnthropicService it runs the OpenAI service's pipeline, here with the
+OpenAI client pointed at the local server. JSON mode is always requested,
+since Ollama supports it for every model, and request models are ignored.
+*/
+type OllamaService struct {
+	*OpenAIService
+}
+
+// NewOllamaService creates a new Ollama service for the server at host
+// (e.g. http://localhost:11434); opts.DefaultModel is the local model
+// (DefaultOllamaModel when empty), and Mock and LogPromptCache do not apply
+func NewOllamaService(host string, opts OpenAIOptions) *OllamaService {
+	if opts.DefaultModel == "" {
+		opts.DefaultModel = DefaultOllamaModel
+	}
+
+	opts.Mock = nil
+	opts.LogPromptCache = false
+	service := NewOpenAIService("", opts)
+
+	// Ollama ignores the key, the client only needs one to build requests
+	config := openai.DefaultConfig("ollama")
+	config.BaseURL = strings.TrimRight(host, "/") + "/v1"
+	service.client = ollamaClient{openai.NewClientWithConfig(config)}
+	service.fixedModel = true
+
+	return &OllamaService{OpenAIService: service}
+}
+
+// ollamaClient requests JSON mode on every call
+type ollamaClient struct {
+	chatClient
+}
+
+func (c ollamaClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	return c.chatClient.CreateChatCompletion(ctx, request)
+}
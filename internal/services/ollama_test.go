@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ollamaCompletion is a canned /v1/chat/completions response from Ollama
+const ollamaCompletion = `{
+  "id": "chatcmpl-512",
+  "object": "chat.completion",
+  "created": 1718000000,
+  "model": "llama3.1",
+  "system_fingerprint": "fp_ollama",
+  "choices": [
+    {
+      "index": 0,
+      "message": {
+        "role": "assistant",
+        "content": "{\"fields\": [\"id\", \"city\"], \"data\": [{\"id\": 1, \"city\": \"Kigali\"}, {\"id\": 2, \"city\": \"Nairobi\"}, {\"id\": 3, \"city\": \"Accra\"}]}"
+      },
+      "finish_reason": "stop"
+    }
+  ],
+  "usage": {"prompt_tokens": 380, "completion_tokens": 52, "total_tokens": 432}
+}`
+
+// TestOllamaService_GenerateMockData tests generating against an Ollama-compatible server
+func TestOllamaService_GenerateMockData(t *testing.T) {
+	var path string
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(ollamaCompletion))
+	}))
+	defer server.Close()
+
+	svc := NewOllamaService(server.URL+"/", OpenAIOptions{DefaultModel: "mistral"})
+	data, fields, err := svc.GenerateMockData(context.Background(), "cities", 3, GenerateOptions{Model: "gpt-4o"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"id", "city"}, fields)
+	require.Len(t, data, 3)
+	assert.Equal(t, "Accra", data[2]["city"])
+
+	assert.Equal(t, "/v1/chat/completions", path)
+	assert.Equal(t, "mistral", request["model"], "The configured model is used, not the request's")
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, request["response_format"])
+}
+
+// TestNewOllamaService_DefaultModel tests the fallback model
+func TestNewOllamaService_DefaultModel(t *testing.T) {
+	svc := NewOllamaService("http://localhost:11434", OpenAIOptions{})
+	assert.Equal(t, DefaultOllamaModel, svc.modelFor(GenerateOptions{}))
+}
@@ -3,9 +3,16 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/kennyg37/wrapperX/backend/internal/models"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -25,15 +32,199 @@ Services should:
 - Return domain errors, not HTTP status codes
 */
 
+var (
+	// ErrEmptyContent is returned when a choice comes back without any content
+	ErrEmptyContent = errors.New("model returned empty content, possibly filtered")
+
+	// ErrContentFiltered is returned when the model stopped due to content filtering
+	ErrContentFiltered = errors.New("model response was blocked by the content filter")
+
+	// ErrResponseTruncated is returned when the model stopped at the token limit
+	ErrResponseTruncated = errors.New("response truncated at the token limit, reduce row count or increase max tokens")
+
+	// ErrInconsistentFields is returned when data keys don't match the field list
+	ErrInconsistentFields = errors.New("generated data keys do not match the field names")
+)
+
+// DefaultTemperature is the sampling temperature when none is configured
+const DefaultTemperature = 0.7
+
+// GenerateOptions carries optional per-request generation settings
+type GenerateOptions struct {
+	// Model overrides the default chat model when set
+	Model string
+
+	// ReferenceValues maps field names to existing values the generated
+	// rows must reuse (e.g. customer names from a customers dataset)
+	ReferenceValues map[string][]string
+
+	// FieldNameLanguage asks for field names in the given language
+	FieldNameLanguage string
+
+	// Locale asks for names, addresses and text of the given BCP 47
+	// locale, e.g. "fr-FR"
+	Locale string
+
+	// FieldPrompts maps field names to focused prompts; each listed field
+	// is filled by follow-up calls after the skeleton rows are generated
+	FieldPrompts map[string]string
+
+	// Seed asks for reproducible sampling on models that support it
+	Seed *int
+
+	// Temperature overrides the service's sampling temperature when set
+	Temperature *float64
+
+	// Fields and RowOffset continue an existing dataset: the new rows use
+	// exactly these field names and are numbered after RowOffset rows
+	Fields    []string
+	RowOffset int
+
+	// EdgeCases are injected into the generated rows with a RNG seeded by
+	// EdgeCaseSeed; generators themselves ignore them
+	EdgeCases    map[string]models.EdgeCaseRule
+	EdgeCaseSeed int64
+
+	// Patterns maps field names to regular expressions their values are
+	// generated from after generation; generators ignore them
+	Patterns map[string]string
+
+	// Schema fixes the fields and their value types; it takes the place of
+	// Fields when set
+	Schema []models.SchemaField
+}
+
+// UsageRecorder persists token usage of completed API calls
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, model string, promptTokens, completionTokens, totalTokens int) error
+}
+
+// OpenAIOptions configures the OpenAI service
+type OpenAIOptions struct {
+	// RedactLogs hides scenario and response text in log output
+	RedactLogs bool
+
+	// UsageRecorder, when set, receives the token usage of every call
+	UsageRecorder UsageRecorder
+
+	// UseNumber decodes numbers as json.Number to keep large integers exact
+	UseNumber bool
+
+	// LogPromptCache logs how many prompt tokens were served from OpenAI's
+	// prompt cache on every call
+	LogPromptCache bool
+
+	// DefaultModel is used when a request does not choose a model
+	// (gpt-3.5-turbo when empty)
+	DefaultModel string
+
+	// Temperature is used when a request does not set one
+	// (DefaultTemperature when nil)
+	Temperature *float64
+
+	// MaxAttempts bounds the calls per completion when OpenAI answers with
+	// a rate limit or server error (DefaultMaxAttempts when zero)
+	MaxAttempts int
+
+	// ChunkConcurrency bounds the chunks of a large request generated in
+	// parallel (DefaultChunkConcurrency when zero)
+	ChunkConcurrency int
+
+	// Azure, when set, sends every call to an Azure OpenAI deployment
+	Azure *AzureOptions
+
+	// Mock, when set, replaces the OpenAI API with an in-process client
+	// that returns deterministic data (for end-to-end test environments)
+	Mock *MockOptions
+}
+
+// DefaultAzureAPIVersion is the Azure OpenAI api-version used when none is
+// configured; older versions lack JSON mode
+const DefaultAzureAPIVersion = "2024-02-01"
+
+// AzureOptions points the service at an Azure OpenAI deployment
+type AzureOptions struct {
+	// Endpoint is the resource URL, e.g. https://my-resource.openai.azure.com
+	Endpoint string
+
+	// Deployment receives every call; request models are ignored, and
+	// DefaultModel should name the model behind the deployment so its
+	// capabilities are known
+	Deployment string
+
+	// APIVersion is the api-version query parameter
+	// (DefaultAzureAPIVersion when empty)
+	APIVersion string
+}
+
+// chatClient is the subset of the OpenAI client used by the service,
+// so tests can substitute a fake
+type chatClient interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+}
+
 type OpenAIService struct {
-	client *openai.Client
+	client       chatClient
+	redactor     LogRedactor
+	usage        UsageRecorder
+	useNumber    bool
+	defaultModel string
+	temperature  *float64
+
+	// fixedModel ignores request models, which name OpenAI models, for
+	// providers that always use their configured one
+	fixedModel bool
+
+	// Retries: maxAttempts calls with backoff from retryDelay, waiting with
+	// sleep (zero values use the defaults)
+	maxAttempts int
+	retryDelay  time.Duration
+	sleep       func(ctx context.Context, d time.Duration) error
+
+	chunkConcurrency int
 }
 
 // NewOpenAIService creates a new OpenAI service
-func NewOpenAIService(apiKey string) *OpenAIService {
+func NewOpenAIService(apiKey string, opts OpenAIOptions) *OpenAIService {
+	var client chatClient = openai.NewClientWithConfig(clientConfig(apiKey, opts))
+	if opts.Mock != nil {
+		log.Printf("🧪 OpenAI mock mode: no API calls are made (latency %s, failure rate %.2f)", opts.Mock.Latency, opts.Mock.FailureRate)
+		client = newMockChatClient(*opts.Mock)
+	}
+
 	return &OpenAIService{
-		client: openai.NewClient(apiKey),
+		client:       client,
+		redactor:     LogRedactor{Enabled: opts.RedactLogs},
+		usage:        opts.UsageRecorder,
+		useNumber:    opts.UseNumber,
+		defaultModel: opts.DefaultModel,
+		temperature:  opts.Temperature,
+		maxAttempts:  opts.MaxAttempts,
+		fixedModel:   opts.Azure != nil,
+
+		chunkConcurrency: opts.ChunkConcurrency,
+	}
+}
+
+// clientConfig returns the OpenAI client configuration, for OpenAI itself
+// or an Azure deployment
+func clientConfig(apiKey string, opts OpenAIOptions) openai.ClientConfig {
+	config := openai.DefaultConfig(apiKey)
+	if opts.Azure != nil {
+		config = openai.DefaultAzureConfig(apiKey, strings.TrimRight(opts.Azure.Endpoint, "/"))
+		config.APIVersion = DefaultAzureAPIVersion
+		if opts.Azure.APIVersion != "" {
+			config.APIVersion = opts.Azure.APIVersion
+		}
+
+		deployment := opts.Azure.Deployment
+		config.AzureModelMapperFunc = func(string) string { return deployment }
+	}
+
+	if opts.LogPromptCache {
+		config.HTTPClient = &http.Client{Transport: promptCacheTransport{base: http.DefaultTransport}}
 	}
+	return config
 }
 
 /*
@@ -56,29 +247,42 @@ This is powerful because GPT can generate:
 - Complex nested structures
 - Domain-specific data (medical, financial, etc.)
 */
-func (s *OpenAIService) GenerateMockData(ctx context.Context, scenario string, rowCount int) ([]map[string]interface{}, []string, error) {
-	// Construct a prompt that instructs GPT to generate JSON data
-	prompt := fmt.Sprintf(`Generate %d rows of realistic mock data based on the following scenario: "%s"
+func (s *OpenAIService) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, []string, error) {
+	model := s.modelFor(opts)
 
-Requirements:
-1. Return ONLY a valid JSON object with this structure: {"fields": ["field1", "field2", ...], "data": [{...}, {...}, ...]}
-2. The "fields" array should list all field names
-3. The "data" array should contain %d objects, each with the same fields
-4. Make the data realistic and varied
-5. Use appropriate data types (strings, numbers, booleans)
-6. Do not include any explanation, only the JSON object
-7. Ensure all field names are consistent across all rows
+	log.Printf("🤖 Requesting mock data from OpenAI (%s) for scenario: %s (%d rows)", model, s.redactor.Text(scenario), rowCount)
 
-Example for "users with contact info":
-{
-  "fields": ["id", "name", "email", "age", "city"],
-  "data": [
-    {"id": 1, "name": "John Doe", "email": "john@example.com", "age": 28, "city": "New York"},
-    {"id": 2, "name": "Jane Smith", "email": "jane@example.com", "age": 34, "city": "Los Angeles"}
-  ]
-}`, rowCount, scenario, rowCount)
+	// Large requests are split into chunks (see generateChunks); the first
+	// one defines the fields
+	data, fields, err := s.generateChunk(ctx, model, scenario, min(rowCount, ChunkSize), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rowCount > ChunkSize {
+		data, err = s.generateChunks(ctx, model, scenario, data, fields, rowCount, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Advanced mode: refine selected fields with their own focused prompts
+	if len(opts.FieldPrompts) > 0 {
+		fields, err = s.fillFields(ctx, model, scenario, data, fields, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	log.Printf("🤖 Requesting mock data from OpenAI for scenario: %s (%d rows)", scenario, rowCount)
+	log.Printf("✅ Successfully generated %d rows with %d fields", len(data), len(fields))
+
+	return data, fields, nil
+}
+
+// generateChunk makes a single generation call for rowCount rows
+func (s *OpenAIService) generateChunk(ctx context.Context, model, scenario string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, []string, error) {
+	// Construct a prompt that instructs GPT to generate JSON data
+	prompt := buildPrompt(scenario, rowCount, opts)
 
 	/*
 	CONCEPT: ChatGPT API
@@ -94,59 +298,283 @@ Example for "users with contact info":
 	- 1.0 = creative, varied
 	- 0.7 is a good balance for mock data
 	*/
-	resp, err := s.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo, // Using GPT-3.5 for cost-efficiency
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are a helpful assistant that generates realistic mock data in JSON format. Always respond with valid JSON only, no additional text.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: 0.7,  // Balance between creativity and consistency
-			MaxTokens:   4000, // Limit response size
-		},
-	)
+	content, err := s.complete(ctx, model, generationInstructions, prompt, opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	// Parse the JSON response
+	result, err := decodeGeneration([]byte(content), s.useNumber)
 	if err != nil {
-		return nil, nil, fmt.Errorf("OpenAI API error: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse OpenAI response as JSON: %w (response: %s)", err, s.redactor.Text(content))
+	}
+
+	// Validate the response
+	if len(result.Fields) == 0 {
+		return nil, nil, fmt.Errorf("OpenAI response missing fields")
+	}
+
+	if len(result.Data) == 0 {
+		return nil, nil, fmt.Errorf("OpenAI response missing data")
+	}
+
+	// Translated names drift more easily between "fields" and the row keys
+	if opts.FieldNameLanguage != "" {
+		if err := CheckFieldConsistency(result.Data, result.Fields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return result.Data, result.Fields, nil
+}
+
+// generationResult is the {"fields": [...], "data": [...]} envelope the model is asked for
+type generationResult struct {
+	Fields []string                 `json:"fields"`
+	Data   []map[string]interface{} `json:"data"`
+}
+
+/*
+decodeGeneration parses a generation response. Models occasionally ignore
+the envelope and return a bare array of rows; the fields are then the keys
+of the first row, in the order they appear.
+*/
+func decodeGeneration(content []byte, useNumber bool) (generationResult, error) {
+	var result generationResult
+	err := DecodeJSON(content, &result, useNumber)
+	if err == nil {
+		return result, nil
+	}
+
+	var rows []json.RawMessage
+	if DecodeJSON(content, &rows, false) != nil {
+		return result, err
+	}
+
+	result = generationResult{Data: make([]map[string]interface{}, len(rows))}
+	for i, row := range rows {
+		if err := DecodeJSON(row, &result.Data[i], useNumber); err != nil {
+			return result, err
+		}
+	}
+
+	if len(rows) > 0 {
+		fields, err := objectKeys(rows[0])
+		if err != nil {
+			return result, err
+		}
+		result.Fields = fields
+	}
+
+	log.Printf("⚠️ Model returned a bare array of %d rows, fields taken from the first row", len(rows))
+	return result, nil
+}
+
+// ModelName implements ModelNamer
+func (s *OpenAIService) ModelName(opts GenerateOptions) string {
+	return s.modelFor(opts)
+}
+
+// modelFor returns the chat model for a request: its own, else the default
+func (s *OpenAIService) modelFor(opts GenerateOptions) string {
+	if opts.Model != "" && !s.fixedModel {
+		return opts.Model
+	}
+	if s.defaultModel != "" {
+		return s.defaultModel
+	}
+	return openai.GPT3Dot5Turbo // Using GPT-3.5 for cost-efficiency
+}
+
+// complete sends a single user prompt, after the system prompt and optional
+// fixed instructions, and returns the text of the first choice
+func (s *OpenAIService) complete(ctx context.Context, model, instructions, prompt string, opts GenerateOptions) (string, error) {
+	if opts.Temperature == nil {
+		opts.Temperature = s.temperature
+	}
+
+	resp, err := s.createWithRetry(ctx, chatRequest(model, instructions, prompt, opts))
+
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API error: %w", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		return nil, nil, fmt.Errorf("no response from OpenAI")
+		return "", fmt.Errorf("no response from OpenAI")
 	}
 
 	// Extract the generated content
-	content := resp.Choices[0].Message.Content
+	choice := resp.Choices[0]
+	content := choice.Message.Content
 	log.Printf("📥 Received response from OpenAI (%d tokens used)", resp.Usage.TotalTokens)
+	s.recordUsage(ctx, model, resp.Usage)
 
-	// Parse the JSON response
-	var result struct {
-		Fields []string                 `json:"fields"`
-		Data   []map[string]interface{} `json:"data"`
+	// A filtered completion may still carry a (partial or empty) choice
+	if choice.FinishReason == openai.FinishReasonContentFilter {
+		return "", ErrContentFiltered
 	}
 
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse OpenAI response as JSON: %w (response: %s)", err, content)
+	// Cut off mid-JSON; parsing would only report a confusing syntax error
+	if choice.FinishReason == openai.FinishReasonLength {
+		return "", ErrResponseTruncated
 	}
 
-	// Validate the response
-	if len(result.Fields) == 0 {
-		return nil, nil, fmt.Errorf("OpenAI response missing fields")
+	if strings.TrimSpace(content) == "" {
+		return "", ErrEmptyContent
 	}
 
-	if len(result.Data) == 0 {
-		return nil, nil, fmt.Errorf("OpenAI response missing data")
+	return content, nil
+}
+
+/*
+chatRequest builds the completion request for a prompt.
+
+Optional parameters are only set when the model supports them (see
+ModelCapabilities), since unsupported ones make the API reject the call:
+JSON mode is used whenever available, and a requested seed is dropped with a
+warning otherwise.
+
+The fixed instructions are appended to the system prompt and everything
+request-specific is in the final user message.
+*/
+func chatRequest(model, instructions, prompt string, opts GenerateOptions) openai.ChatCompletionRequest {
+	caps := ModelCapabilities(model)
+
+	system := systemPrompt
+	if instructions != "" {
+		system += "\n\n" + instructions
 	}
 
-	log.Printf("✅ Successfully generated %d rows with %d fields", len(result.Data), len(result.Fields))
+	request := openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: system,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+		Temperature: DefaultTemperature, // Balance between creativity and consistency
+		MaxTokens:   4000,               // Limit response size
+	}
 
-	return result.Data, result.Fields, nil
+	if opts.Temperature != nil {
+		request.Temperature = float32(*opts.Temperature)
+	}
+
+	// The client drops a zero temperature (omitempty), which would make the
+	// API use its default of 1; the smallest float32 is sent instead
+	if request.Temperature == 0 {
+		request.Temperature = math.SmallestNonzeroFloat32
+	}
+
+	if caps.JSONMode {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+
+	if opts.Seed != nil {
+		if caps.Seed {
+			request.Seed = opts.Seed
+		} else {
+			log.Printf("⚠️ Model %s does not support seed, generating without it", model)
+		}
+	}
+
+	return request
+}
+
+// recordUsage forwards token usage to the recorder; failures are only logged
+func (s *OpenAIService) recordUsage(ctx context.Context, model string, usage openai.Usage) {
+	if s.usage == nil {
+		return
+	}
+	if err := s.usage.RecordUsage(ctx, model, usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens); err != nil {
+		log.Printf("Failed to record OpenAI usage: %v", err)
+	}
+}
+
+// systemPrompt is the system message of every call
+const systemPrompt = "You are a helpful assistant that generates realistic mock data in JSON format. Always respond with valid JSON only, no additional text."
+
+// generationInstructions are the fixed rules for dataset generation, the
+// same for every request
+const generationInstructions = `Requirements:
+1. Return ONLY a valid JSON object with this structure: {"fields": ["field1", "field2", ...], "data": [{...}, {...}, ...]}
+2. The "fields" array should list all field names
+3. The "data" array should contain exactly the requested number of objects, each with the same fields
+4. Make the data realistic and varied
+5. Use appropriate data types (strings, numbers, booleans)
+6. Do not include any explanation, only the JSON object
+7. Ensure all field names are consistent across all rows
+
+Example for "users with contact info":
+{
+  "fields": ["id", "name", "email", "age", "city"],
+  "data": [
+    {"id": 1, "name": "John Doe", "email": "john@example.com", "age": 28, "city": "New York"},
+    {"id": 2, "name": "Jane Smith", "email": "jane@example.com", "age": 34, "city": "Los Angeles"}
+  ]
+}`
+
+// buildPrompt constructs the request-specific user prompt for a generation
+// request; the fixed rules are in generationInstructions
+func buildPrompt(scenario string, rowCount int, opts GenerateOptions) string {
+	prompt := fmt.Sprintf(`Generate %d rows of realistic mock data based on the following scenario: "%s"
+
+The "data" array must contain %d objects.`, rowCount, scenario, rowCount)
+
+	var extra strings.Builder
+
+	if len(opts.Schema) > 0 {
+		extra.WriteString(schemaPrompt(opts.Schema))
+	} else if len(opts.Fields) > 0 {
+		quoted, _ := json.Marshal(opts.Fields)
+		extra.WriteString(fmt.Sprintf("\n\nUse exactly these fields, in this order: %s.", quoted))
+	}
+
+	if opts.RowOffset > 0 {
+		extra.WriteString(fmt.Sprintf("\n\nThese rows continue an existing dataset of %d rows; ids and other sequence numbers start after it, at %d.", opts.RowOffset, opts.RowOffset+1))
+	}
+
+	if opts.FieldNameLanguage != "" {
+		extra.WriteString(fmt.Sprintf("\n\nWrite every field name in %s. Use exactly the same field names, with identical spelling, as the keys of every object in \"data\". Only the field names are translated; keep the values realistic for the scenario.", opts.FieldNameLanguage))
+	}
+
+	if opts.Locale != "" {
+		extra.WriteString(fmt.Sprintf("\n\nGenerate the values for the %s locale: names, addresses, phone numbers and free text must be what people there would actually write, in its language and formats. Keep the field names as they are.", opts.Locale))
+	}
+
+	if len(opts.ReferenceValues) > 0 {
+		extra.WriteString("\n\nReference values from an existing dataset (the new data must reference these records):")
+
+		// Sorted for a stable prompt
+		fields := make([]string, 0, len(opts.ReferenceValues))
+		for field := range opts.ReferenceValues {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			quoted, _ := json.Marshal(opts.ReferenceValues[field])
+			extra.WriteString(fmt.Sprintf("\n- Include a field named %q whose values are taken only from: %s", field, quoted))
+		}
+	}
+
+	if len(opts.FieldPrompts) > 0 {
+		fields := make([]string, 0, len(opts.FieldPrompts))
+		for field := range opts.FieldPrompts {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		quoted, _ := json.Marshal(fields)
+		extra.WriteString(fmt.Sprintf("\n\nInclude these fields in every row: %s. Their values are refined separately, so simple placeholder values are fine.", quoted))
+	}
+
+	return prompt + extra.String()
 }
 
 /*
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChatClient returns a canned completion
+type fakeChatClient struct {
+	resp openai.ChatCompletionResponse
+	err  error
+}
+
+func (f *fakeChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	return f.resp, f.err
+}
+
+func newFakeService(content string, finish openai.FinishReason) *OpenAIService {
+	return &OpenAIService{
+		client: &fakeChatClient{resp: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+				FinishReason: finish,
+			}},
+		}},
+	}
+}
+
+// TestGenerateMockData tests response handling with a fake client
+func TestGenerateMockData(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Valid response", func(t *testing.T) {
+		svc := newFakeService(`{"fields": ["id"], "data": [{"id": 1}]}`, openai.FinishReasonStop)
+		data, fields, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id"}, fields)
+		assert.Len(t, data, 1)
+	})
+
+	t.Run("Empty content", func(t *testing.T) {
+		svc := newFakeService("", openai.FinishReasonStop)
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrEmptyContent)
+	})
+
+	t.Run("Blank content", func(t *testing.T) {
+		svc := newFakeService("  \n ", openai.FinishReasonStop)
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrEmptyContent)
+	})
+
+	t.Run("Content filtered", func(t *testing.T) {
+		svc := newFakeService("", openai.FinishReasonContentFilter)
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrContentFiltered)
+	})
+
+	t.Run("Truncated at the token limit", func(t *testing.T) {
+		svc := newFakeService(`{"fields": ["id"], "data": [{"id": 1}, {"id`, openai.FinishReasonLength)
+		_, _, err := svc.GenerateMockData(ctx, "ids", 2, GenerateOptions{})
+		assert.ErrorIs(t, err, ErrResponseTruncated)
+		assert.NotContains(t, err.Error(), "failed to parse", "No JSON parse error for partial output")
+	})
+
+	t.Run("Bare array", func(t *testing.T) {
+		svc := newFakeService(`[{"name": "Ann", "id": 1, "active": true}, {"name": "Bob", "id": 2, "active": false, "extra": 1}]`, openai.FinishReasonStop)
+		data, fields, err := svc.GenerateMockData(ctx, "users", 2, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name", "id", "active"}, fields, "Fields follow the key order of the first row")
+		require.Len(t, data, 2)
+		assert.Equal(t, map[string]interface{}{"name": "Ann", "id": float64(1), "active": true}, data[0])
+	})
+
+	t.Run("Bare array of non-objects", func(t *testing.T) {
+		svc := newFakeService(`[1, 2, 3]`, openai.FinishReasonStop)
+		_, _, err := svc.GenerateMockData(ctx, "ids", 3, GenerateOptions{})
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+
+	t.Run("Empty bare array", func(t *testing.T) {
+		svc := newFakeService(`[]`, openai.FinishReasonStop)
+		_, _, err := svc.GenerateMockData(ctx, "ids", 3, GenerateOptions{})
+		assert.ErrorContains(t, err, "missing fields")
+	})
+
+	t.Run("No choices", func(t *testing.T) {
+		svc := &OpenAIService{client: &fakeChatClient{}}
+		_, _, err := svc.GenerateMockData(ctx, "ids", 1, GenerateOptions{})
+		assert.Error(t, err)
+	})
+}
+
+// TestBuildPrompt_Locale tests that a locale asks for localized values
+func TestBuildPrompt_Locale(t *testing.T) {
+	prompt := buildPrompt("customers", 5, GenerateOptions{Locale: "ja-JP"})
+	assert.Contains(t, prompt, "Generate the values for the ja-JP locale: names, addresses, phone numbers and free text")
+
+	plain := buildPrompt("customers", 5, GenerateOptions{})
+	assert.NotContains(t, plain, "locale", "No locale instruction by default")
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// MaxPatternRepeat caps unbounded repetitions (*, + and {n,}) in generated
+// pattern values
+const MaxPatternRepeat = 10
+
+/*
+GenerateFromPattern returns a random string matching a regular expression.
+
+Unbounded repetitions repeat at most MaxPatternRepeat times beyond their
+minimum, "." and negated classes prefer printable ASCII, and anchors and
+word boundaries produce nothing, so patterns are best written as a full
+match (e.g. "[A-Z]{3}-[0-9]{4}").
+*/
+func GenerateFromPattern(pattern string, rng *rand.Rand) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	var out strings.Builder
+	if err := writePattern(&out, re.Simplify(), rng); err != nil {
+		return "", fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+	return out.String(), nil
+}
+
+/*
+ApplyPatterns replaces every value of the pattern fields with a generated
+match and returns the field names, with pattern fields the data did not
+have appended in sorted order.
+*/
+func ApplyPatterns(data []map[string]interface{}, fieldNames []string, patterns map[string]string, rng *rand.Rand) ([]string, error) {
+	fields := make([]string, 0, len(patterns))
+	for field := range patterns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		for _, row := range data {
+			value, err := GenerateFromPattern(patterns[field], rng)
+			if err != nil {
+				return nil, err
+			}
+			row[field] = value
+		}
+
+		if !containsString(fieldNames, field) {
+			fieldNames = append(fieldNames, field)
+		}
+	}
+
+	return fieldNames, nil
+}
+
+// writePattern appends a random match of a simplified regexp
+func writePattern(out *strings.Builder, re *syntax.Regexp, rng *rand.Rand) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return nil
+
+	case syntax.OpNoMatch:
+		return fmt.Errorf("no string can match")
+
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			out.WriteRune(r)
+		}
+		return nil
+
+	case syntax.OpCharClass:
+		r, ok := classRune(re.Rune, rng)
+		if !ok {
+			return fmt.Errorf("empty character class")
+		}
+		out.WriteRune(r)
+		return nil
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		out.WriteRune(rune(' ' + rng.Intn('~'-' '+1)))
+		return nil
+
+	case syntax.OpCapture:
+		return writePattern(out, re.Sub[0], rng)
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := writePattern(out, sub, rng); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case syntax.OpAlternate:
+		return writePattern(out, re.Sub[rng.Intn(len(re.Sub))], rng)
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		least, most := repeatBounds(re)
+		count := least + rng.Intn(most-least+1)
+		for i := 0; i < count; i++ {
+			if err := writePattern(out, re.Sub[0], rng); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported construct %s", re)
+}
+
+// repeatBounds returns how often a repetition may repeat, capping unbounded ones
+func repeatBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, MaxPatternRepeat
+	case syntax.OpPlus:
+		return 1, 1 + MaxPatternRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+
+	if re.Max < 0 {
+		return re.Min, re.Min + MaxPatternRepeat
+	}
+	return re.Min, re.Max
+}
+
+/*
+classRune picks a rune from a character class given as lo-hi pairs.
+
+Printable ASCII members are preferred so negated classes like [^,] stay
+readable; other runes are only used when the class has no printable ASCII.
+*/
+func classRune(ranges []rune, rng *rand.Rand) (rune, bool) {
+	if r, ok := pickRange(ranges, ' ', '~', rng); ok {
+		return r, true
+	}
+	return pickRange(ranges, 0, '\U0010FFFF', rng)
+}
+
+// pickRange picks a rune uniformly from the parts of ranges within lo..hi
+func pickRange(ranges []rune, lo, hi rune, rng *rand.Rand) (rune, bool) {
+	total := 0
+	for i := 0; i+1 < len(ranges); i += 2 {
+		from, to := max(ranges[i], lo), min(ranges[i+1], hi)
+		if from <= to {
+			total += int(to-from) + 1
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	n := rng.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		from, to := max(ranges[i], lo), min(ranges[i+1], hi)
+		if from > to {
+			continue
+		}
+		if size := int(to-from) + 1; n >= size {
+			n -= size
+		} else {
+			return from + rune(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateFromPattern tests that generated values match their pattern
+func TestGenerateFromPattern(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	patterns := []string{
+		`[A-Z]{3}-[0-9]{4}`,
+		`^SKU-\d{6}$`,
+		`(RED|GREEN|BLUE)-[a-f0-9]{2,4}`,
+		`[^,]{5}`,
+		`\w+@example\.com`,
+		`(?i)abc`,
+		`ID-.?x*y+`,
+		`[À-ÿ]{3}`,
+		`\bplate\b [A-HJ-NP-Z]{2}[0-9]{2} ?[A-Z]{3}`,
+	}
+
+	for _, pattern := range patterns {
+		full := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for i := 0; i < 50; i++ {
+			value, err := GenerateFromPattern(pattern, rng)
+			require.NoError(t, err, pattern)
+			assert.Regexp(t, full, value, pattern)
+		}
+	}
+
+	// Unbounded repetitions are capped
+	for i := 0; i < 50; i++ {
+		value, err := GenerateFromPattern(`a*`, rng)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(value), MaxPatternRepeat)
+	}
+
+	_, err := GenerateFromPattern(`[a-`, rng)
+	assert.Error(t, err, "Invalid patterns are rejected")
+
+	_, err = GenerateFromPattern(`[^\x00-\x{10FFFF}]`, rng)
+	assert.Error(t, err, "Patterns nothing can match are rejected")
+}
+
+// TestGenerateFromPattern_Deterministic tests that a seed reproduces values
+func TestGenerateFromPattern_Deterministic(t *testing.T) {
+	first, err := GenerateFromPattern(`[A-Z]{8}`, rand.New(rand.NewSource(42)))
+	require.NoError(t, err)
+	second, err := GenerateFromPattern(`[A-Z]{8}`, rand.New(rand.NewSource(42)))
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+// TestApplyPatterns tests overriding and adding pattern fields
+func TestApplyPatterns(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "plate": "not a plate"},
+		{"id": float64(2), "plate": nil},
+	}
+	patterns := map[string]string{"plate": `[A-Z]{3}-[0-9]{3}`, "code": `P[0-9]{2}`}
+
+	fields, err := ApplyPatterns(data, []string{"id", "plate"}, patterns, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "plate", "code"}, fields)
+
+	for _, row := range data {
+		assert.Regexp(t, `^[A-Z]{3}-[0-9]{3}$`, row["plate"])
+		assert.Regexp(t, `^P[0-9]{2}$`, row["code"])
+	}
+}
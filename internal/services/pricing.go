@@ -0,0 +1,41 @@
+package services
+
+import "strings"
+
+// modelPrice is the USD price per million tokens for a model family
+type modelPrice struct {
+	prompt     float64
+	completion float64
+}
+
+/*
+modelPrices lists list prices per million tokens, keyed by model prefix so
+dated snapshots (e.g. gpt-4o-2024-08-06) share their family's price.
+These are estimates for budgeting, not billing.
+*/
+var modelPrices = map[string]modelPrice{
+	"gpt-3.5-turbo": {prompt: 0.50, completion: 1.50},
+	"gpt-4":         {prompt: 30.00, completion: 60.00},
+	"gpt-4-turbo":   {prompt: 10.00, completion: 30.00},
+	"gpt-4o":        {prompt: 2.50, completion: 10.00},
+	"gpt-4o-mini":   {prompt: 0.15, completion: 0.60},
+}
+
+// EstimateCost returns the estimated USD cost of a call and whether the
+// model's price is known. The longest matching prefix wins.
+func EstimateCost(model string, promptTokens, completionTokens int) (float64, bool) {
+	best := ""
+	for prefix := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+
+	if best == "" {
+		return 0, false
+	}
+
+	price := modelPrices[best]
+	cost := (float64(promptTokens)*price.prompt + float64(completionTokens)*price.completion) / 1_000_000
+	return cost, true
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEstimateCost tests cost estimation per model family
+func TestEstimateCost(t *testing.T) {
+	cost, ok := EstimateCost("gpt-3.5-turbo", 1_000_000, 1_000_000)
+	assert.True(t, ok)
+	assert.InDelta(t, 2.00, cost, 1e-9)
+
+	// Longest prefix wins so gpt-4o-mini isn't priced as gpt-4o or gpt-4
+	cost, ok = EstimateCost("gpt-4o-mini-2024-07-18", 1_000_000, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.15, cost, 1e-9)
+
+	cost, ok = EstimateCost("gpt-4-turbo-preview", 0, 1_000_000)
+	assert.True(t, ok)
+	assert.InDelta(t, 30.00, cost, 1e-9)
+
+	cost, ok = EstimateCost("gpt-4-0613", 1000, 1000)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.09, cost, 1e-9)
+
+	_, ok = EstimateCost("some-local-model", 1000, 1000)
+	assert.False(t, ok, "Unknown models have no price")
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+/*
+promptCacheTransport logs how much of each chat completion prompt was served
+from OpenAI's prompt cache.
+
+OpenAI caches prompt prefixes automatically (for prompts of 1024 tokens or
+more); there is no cache-control annotation to set. The cached token count is
+reported in usage.prompt_tokens_details, which the client library does not
+decode, so it is read from the response body here.
+*/
+type promptCacheTransport struct {
+	base http.RoundTripper
+}
+
+func (t promptCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if usage, ok := PromptCacheUsage(body); ok {
+		log.Printf("🗄️ Prompt cache: %d of %d prompt tokens cached (%s)", usage.CachedTokens, usage.PromptTokens, usage.Model)
+	}
+
+	return resp, nil
+}
+
+// CachedPromptUsage is the prompt cache usage of one completion
+type CachedPromptUsage struct {
+	Model        string
+	PromptTokens int
+	CachedTokens int
+}
+
+// PromptCacheUsage extracts the prompt cache usage from a chat completion
+// response body; ok is false when the body carries no usage
+func PromptCacheUsage(body []byte) (CachedPromptUsage, bool) {
+	var resp struct {
+		Model string `json:"model"`
+		Usage *struct {
+			PromptTokens        int `json:"prompt_tokens"`
+			PromptTokensDetails struct {
+				CachedTokens int `json:"cached_tokens"`
+			} `json:"prompt_tokens_details"`
+		} `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Usage == nil {
+		return CachedPromptUsage{}, false
+	}
+
+	return CachedPromptUsage{
+		Model:        resp.Model,
+		PromptTokens: resp.Usage.PromptTokens,
+		CachedTokens: resp.Usage.PromptTokensDetails.CachedTokens,
+	}, true
+}
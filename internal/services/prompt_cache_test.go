@@ -0,0 +1,71 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPromptCacheUsage tests reading cached tokens from a completion response
+func TestPromptCacheUsage(t *testing.T) {
+	usage, ok := PromptCacheUsage([]byte(`{"model": "gpt-4o", "usage": {"prompt_tokens": 2006, "completion_tokens": 300, "prompt_tokens_details": {"cached_tokens": 1920}}}`))
+	require.True(t, ok)
+	assert.Equal(t, CachedPromptUsage{Model: "gpt-4o", PromptTokens: 2006, CachedTokens: 1920}, usage)
+
+	// Older responses have no details: nothing cached
+	usage, ok = PromptCacheUsage([]byte(`{"model": "gpt-3.5-turbo", "usage": {"prompt_tokens": 500}}`))
+	require.True(t, ok)
+	assert.Equal(t, 0, usage.CachedTokens)
+
+	_, ok = PromptCacheUsage([]byte(`{"error": {"message": "rate limited"}}`))
+	assert.False(t, ok)
+
+	_, ok = PromptCacheUsage([]byte(`not json`))
+	assert.False(t, ok)
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TestPromptCacheTransport tests that the response body is still readable after logging
+func TestPromptCacheTransport(t *testing.T) {
+	body := `{"model": "gpt-4o", "usage": {"prompt_tokens": 10, "prompt_tokens_details": {"cached_tokens": 0}}}`
+	transport := promptCacheTransport{base: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	read, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(read))
+}
+
+// TestChatRequest_Instructions tests that fixed instructions go in the system message
+func TestChatRequest_Instructions(t *testing.T) {
+	first := chatRequest("gpt-4o", generationInstructions, buildPrompt("users", 5, GenerateOptions{}), GenerateOptions{})
+	second := chatRequest("gpt-4o", generationInstructions, buildPrompt("orders", 20, GenerateOptions{}), GenerateOptions{})
+
+	require.Len(t, first.Messages, 2)
+	assert.Equal(t, openai.ChatMessageRoleSystem, first.Messages[0].Role)
+	assert.Contains(t, first.Messages[0].Content, "Requirements:")
+	assert.Equal(t, first.Messages[0], second.Messages[0], "The system message should not depend on the request")
+	assert.Contains(t, first.Messages[1].Content, `"users"`)
+	assert.NotContains(t, first.Messages[1].Content, "Requirements:")
+
+	// Follow-up calls only get the system prompt
+	followUp := chatRequest("gpt-4o", "", "prompt", GenerateOptions{})
+	assert.Equal(t, systemPrompt, followUp.Messages[0].Content)
+}
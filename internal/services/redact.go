@@ -0,0 +1,28 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+/*
+LogRedactor hides prompt and response text in logs.
+
+When enabled, text is replaced by its length and a short hash so log lines
+can still be correlated ("was it the same scenario?") without exposing the
+content to shared log systems.
+*/
+type LogRedactor struct {
+	Enabled bool
+}
+
+// Text returns s unchanged, or a redacted summary when redaction is enabled
+func (r LogRedactor) Text(s string) string {
+	if !r.Enabled {
+		return s
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("[redacted len=%d sha256=%s]", len(s), hex.EncodeToString(sum[:4]))
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLogRedactor tests redaction of sensitive log text
+func TestLogRedactor(t *testing.T) {
+	scenario := "patients with diagnoses at St. Mary's"
+
+	assert.Equal(t, scenario, LogRedactor{}.Text(scenario), "Disabled redactor should pass text through")
+
+	redacted := LogRedactor{Enabled: true}.Text(scenario)
+	assert.NotContains(t, redacted, "patients")
+	assert.Contains(t, redacted, "len=37")
+	assert.Regexp(t, `sha256=[0-9a-f]{8}\]$`, redacted)
+
+	// Same input gives the same hash so log lines can be correlated
+	assert.Equal(t, redacted, LogRedactor{Enabled: true}.Text(scenario))
+	assert.NotEqual(t, redacted, LogRedactor{Enabled: true}.Text("users"))
+}
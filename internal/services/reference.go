@@ -0,0 +1,40 @@
+package services
+
+// MaxReferenceValues caps how many distinct values per field are injected
+// into the prompt, keeping it well below the model's context window
+const MaxReferenceValues = 50
+
+/*
+ExtractReferenceValues collects the distinct values of the given fields from
+an existing dataset, in order of first appearance. Null values are skipped
+and each field keeps at most limit values.
+*/
+func ExtractReferenceValues(data []map[string]interface{}, fields []string, limit int) map[string][]string {
+	values := make(map[string][]string, len(fields))
+
+	for _, field := range fields {
+		seen := make(map[string]bool)
+		values[field] = []string{}
+
+		for _, row := range data {
+			if len(values[field]) >= limit {
+				break
+			}
+
+			value, ok := row[field]
+			if !ok || value == nil {
+				continue
+			}
+
+			formatted := formatValue(value)
+			if formatted == "" || seen[formatted] {
+				continue
+			}
+
+			seen[formatted] = true
+			values[field] = append(values[field], formatted)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExtractReferenceValues tests distinct value extraction from a dataset
+func TestExtractReferenceValues(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "Alice", "tier": "gold"},
+		{"id": float64(2), "name": "Bob", "tier": "silver"},
+		{"id": float64(3), "name": "Alice", "tier": nil},
+		{"id": float64(4), "name": "Carol", "tier": "gold"},
+	}
+
+	values := ExtractReferenceValues(data, []string{"name", "id", "tier"}, 10)
+
+	assert.Equal(t, []string{"Alice", "Bob", "Carol"}, values["name"], "Should dedupe and keep order")
+	assert.Equal(t, []string{"1", "2", "3", "4"}, values["id"], "Should format numbers")
+	assert.Equal(t, []string{"gold", "silver"}, values["tier"], "Should skip nulls")
+
+	limited := ExtractReferenceValues(data, []string{"id"}, 2)
+	assert.Equal(t, []string{"1", "2"}, limited["id"], "Should respect the limit")
+}
+
+// TestBuildPrompt_ReferenceValues tests that reference values are injected into the prompt
+func TestBuildPrompt_ReferenceValues(t *testing.T) {
+	opts := GenerateOptions{
+		ReferenceValues: map[string][]string{
+			"customer_name": {"Alice", "Bob \"The Builder\""},
+		},
+	}
+
+	prompt := buildPrompt("orders placed by customers", 5, opts)
+
+	assert.Contains(t, prompt, "orders placed by customers")
+	assert.Contains(t, prompt, `field named "customer_name"`)
+	assert.Contains(t, prompt, `["Alice","Bob \"The Builder\""]`, "Values should be JSON-quoted")
+
+	plain := buildPrompt("orders", 5, GenerateOptions{})
+	assert.NotContains(t, plain, "Reference values", "No reference section without values")
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultMaxAttempts is how often an OpenAI call is tried when the
+// service is not configured otherwise
+const DefaultMaxAttempts = 3
+
+// Backoff between attempts: the delay doubles from retryBaseDelay up to
+// retryMaxDelay, and half of it is random jitter
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 8 * time.Second
+)
+
+/*
+createWithRetry sends a chat completion, retrying rate limits (429) and
+server errors (5xx) with exponential backoff until maxAttempts calls were
+made. Other errors and a cancelled context end the loop immediately; the
+error of the last attempt is returned.
+*/
+func (s *OpenAIService) createWithRetry(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	attempts := s.maxAttempts
+	if attempts < 1 {
+		attempts = DefaultMaxAttempts
+	}
+
+	sleep := s.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := s.client.CreateChatCompletion(ctx, request)
+		if err == nil || attempt == attempts || !isRetryable(err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := retryDelay(attempt, s.retryDelay)
+		log.Printf("⏳ OpenAI call failed (%v), retrying in %s (attempt %d of %d)", err, delay.Round(time.Millisecond), attempt+1, attempts)
+
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return resp, err
+		}
+	}
+}
+
+// isRetryable reports whether an OpenAI error is a rate limit or server error
+func isRetryable(err error) bool {
+	status := 0
+
+	var apiErr *openai.APIError
+	var requestErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &requestErr):
+		status = requestErr.HTTPStatusCode
+	}
+
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryDelay returns the backoff before the attempt after the given one
+func retryDelay(attempt int, base time.Duration) time.Duration {
+	if base <= 0 {
+		base = retryBaseDelay
+	}
+
+	delay := base << (attempt - 1)
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
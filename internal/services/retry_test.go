@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingChatClient fails the first calls with errs before answering with content
+type failingChatClient struct {
+	errs    []error
+	content string
+	calls   int
+}
+
+func (f *failingChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return openai.ChatCompletionResponse{}, err
+	}
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: f.content},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}, nil
+}
+
+func apiError(status int) error {
+	return &openai.APIError{Message: http.StatusText(status), HTTPStatusCode: status}
+}
+
+// retryService returns a service that records its backoff delays instead of sleeping
+func retryService(client chatClient, maxAttempts int) (*OpenAIService, *[]time.Duration) {
+	delays := &[]time.Duration{}
+	return &OpenAIService{
+		client:      client,
+		maxAttempts: maxAttempts,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*delays = append(*delays, d)
+			return ctx.Err()
+		},
+	}, delays
+}
+
+// TestGenerateMockData_Retry tests retrying rate limits and server errors
+func TestGenerateMockData_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Succeeds after two failures", func(t *testing.T) {
+		client := &failingChatClient{errs: []error{apiError(http.StatusTooManyRequests), apiError(http.StatusBadGateway)}, content: idRows(1, 2)}
+		svc, delays := retryService(client, 0)
+
+		data, _, err := svc.GenerateMockData(ctx, "users", 2, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Len(t, data, 2)
+		assert.Equal(t, 3, client.calls)
+
+		require.Len(t, *delays, 2)
+		assert.GreaterOrEqual(t, (*delays)[0], retryBaseDelay/2)
+		assert.LessOrEqual(t, (*delays)[0], retryBaseDelay)
+		assert.GreaterOrEqual(t, (*delays)[1], retryBaseDelay, "The delay doubles")
+		assert.LessOrEqual(t, (*delays)[1], 2*retryBaseDelay)
+	})
+
+	t.Run("Surfaces the last error", func(t *testing.T) {
+		client := &failingChatClient{errs: []error{apiError(500), apiError(503), apiError(429), apiError(500)}}
+		svc, _ := retryService(client, 3)
+
+		_, _, err := svc.GenerateMockData(ctx, "users", 2, GenerateOptions{})
+		var apiErr *openai.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.HTTPStatusCode)
+		assert.Equal(t, 3, client.calls)
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		client := &failingChatClient{errs: []error{apiError(http.StatusBadRequest)}, content: idRows(1, 2)}
+		svc, _ := retryService(client, 3)
+
+		_, _, err := svc.GenerateMockData(ctx, "users", 2, GenerateOptions{})
+		assert.Error(t, err)
+		assert.Equal(t, 1, client.calls)
+	})
+
+	t.Run("Stops when the context is cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		client := &failingChatClient{errs: []error{apiError(503)}, content: idRows(1, 2)}
+		svc, delays := retryService(client, 3)
+
+		_, _, err := svc.GenerateMockData(cancelled, "users", 2, GenerateOptions{})
+		assert.Error(t, err)
+		assert.Equal(t, 1, client.calls)
+		assert.Empty(t, *delays)
+	})
+}
+
+// TestIsRetryable tests which errors are retried
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(apiError(429)))
+	assert.True(t, isRetryable(apiError(500)))
+	assert.True(t, isRetryable(&openai.RequestError{HTTPStatusCode: 502, Err: errors.New("bad gateway")}))
+	assert.True(t, isRetryable(errors.Join(errors.New("wrapped"), apiError(503))))
+	assert.False(t, isRetryable(apiError(400)))
+	assert.False(t, isRetryable(apiError(401)))
+	assert.False(t, isRetryable(context.DeadlineExceeded))
+	assert.False(t, isRetryable(errors.New("connection refused")))
+}
+
+// TestRetryDelay tests the backoff cap
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt < 70; attempt++ {
+		delay := retryDelay(attempt, 0)
+		assert.Greater(t, delay, time.Duration(0), attempt)
+		assert.LessOrEqual(t, delay, retryMaxDelay, attempt)
+	}
+}
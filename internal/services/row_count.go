@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"log"
+)
+
+// MaxTopUpAttempts bounds the follow-up generations used to reach the
+// requested row count
+const MaxTopUpAttempts = 3
+
+/*
+TopUpRows asks the generator for the rows missing from data until rowCount
+is reached or MaxTopUpAttempts calls were made, and drops any extras.
+
+Follow-up requests use the existing field names and continue the row
+numbering, and their rows are conformed to fieldNames (unknown keys dropped,
+missing ones null). A failed follow-up keeps the rows generated so far, so
+the result may still be short; callers compare its length to rowCount.
+*/
+func TopUpRows(ctx context.Context, generator Generator, scenario string, data []map[string]interface{}, fieldNames []string, rowCount int, opts GenerateOptions) []map[string]interface{} {
+	opts.Fields = fieldNames
+
+	for attempt := 0; attempt < MaxTopUpAttempts && len(data) < rowCount; attempt++ {
+		missing := rowCount - len(data)
+		opts.RowOffset = len(data)
+
+		log.Printf("Topping up %d of %d rows (attempt %d)", missing, rowCount, attempt+1)
+
+		extra, _, err := generator.GenerateMockData(ctx, scenario, missing, opts)
+		if err != nil {
+			log.Printf("Failed to top up rows: %v", err)
+			break
+		}
+
+		for _, row := range extra {
+			data = append(data, conformRow(row, fieldNames))
+		}
+	}
+
+	return TruncateRows(data, rowCount)
+}
+
+// TruncateRows drops the rows past rowCount
+func TruncateRows(data []map[string]interface{}, rowCount int) []map[string]interface{} {
+	if len(data) > rowCount {
+		return data[:rowCount]
+	}
+	return data
+}
+
+// conformRow keeps exactly the listed fields of a row, null when missing
+func conformRow(row map[string]interface{}, fieldNames []string) map[string]interface{} {
+	conformed := make(map[string]interface{}, len(fieldNames))
+	for _, field := range fieldNames {
+		conformed[field] = row[field]
+	}
+	return conformed
+}
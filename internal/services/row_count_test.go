@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceChatClient answers calls with the next canned content and records prompts and models
+type sequenceChatClient struct {
+	contents []string
+	prompts  []string
+	models   []string
+}
+
+func (f *sequenceChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.prompts = append(f.prompts, request.Messages[len(request.Messages)-1].Content)
+	f.models = append(f.models, request.Model)
+	if len(f.contents) == 0 {
+		return openai.ChatCompletionResponse{}, errors.New("no more responses")
+	}
+
+	content := f.contents[0]
+	f.contents = f.contents[1:]
+	return openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}, nil
+}
+
+// idRows returns a generation response with ids from..to
+func idRows(from, to int) string {
+	rows := []string{}
+	for id := from; id <= to; id++ {
+		rows = append(rows, fmt.Sprintf(`{"id": %d, "name": "user %d", "extra": true}`, id, id))
+	}
+	return `{"fields": ["id", "name", "extra"], "data": [` + strings.Join(rows, ",") + `]}`
+}
+
+func idData(n int) []map[string]interface{} {
+	data := make([]map[string]interface{}, n)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": float64(i + 1), "name": fmt.Sprintf("user %d", i+1)}
+	}
+	return data
+}
+
+// TestTopUpRows tests generating the missing rows of a short dataset
+func TestTopUpRows(t *testing.T) {
+	ctx := context.Background()
+	fields := []string{"id", "name"}
+
+	t.Run("Tops up in several calls", func(t *testing.T) {
+		client := &sequenceChatClient{contents: []string{idRows(8, 9), idRows(10, 10)}}
+		svc := &OpenAIService{client: client}
+
+		data := TopUpRows(ctx, svc, "users", idData(7), fields, 10, GenerateOptions{})
+		require.Len(t, data, 10)
+		assert.Equal(t, map[string]interface{}{"id": float64(10), "name": "user 10"}, data[9], "Rows are conformed to the existing fields")
+
+		require.Len(t, client.prompts, 2)
+		assert.Contains(t, client.prompts[0], "Generate 3 rows")
+		assert.Contains(t, client.prompts[0], `Use exactly these fields, in this order: ["id","name"].`)
+		assert.Contains(t, client.prompts[0], "existing dataset of 7 rows")
+		assert.Contains(t, client.prompts[1], "Generate 1 rows")
+	})
+
+	t.Run("Drops overshoot", func(t *testing.T) {
+		client := &sequenceChatClient{contents: []string{idRows(2, 6)}}
+		data := TopUpRows(ctx, &OpenAIService{client: client}, "users", idData(1), fields, 3, GenerateOptions{})
+		assert.Len(t, data, 3)
+	})
+
+	t.Run("Keeps partial data when the follow-up fails", func(t *testing.T) {
+		client := &sequenceChatClient{}
+		data := TopUpRows(ctx, &OpenAIService{client: client}, "users", idData(4), fields, 10, GenerateOptions{})
+		assert.Len(t, data, 4)
+		assert.Len(t, client.prompts, 1, "A failed follow-up is not retried")
+	})
+
+	t.Run("Gives up after MaxTopUpAttempts", func(t *testing.T) {
+		client := &sequenceChatClient{contents: []string{idRows(2, 2), idRows(3, 3), idRows(4, 4), idRows(5, 5)}}
+		data := TopUpRows(ctx, &OpenAIService{client: client}, "users", idData(1), fields, 10, GenerateOptions{})
+		assert.Len(t, data, 1+MaxTopUpAttempts)
+		assert.Len(t, client.prompts, MaxTopUpAttempts)
+	})
+
+	t.Run("Complete data needs no calls", func(t *testing.T) {
+		client := &sequenceChatClient{}
+		data := TopUpRows(ctx, &OpenAIService{client: client}, "users", idData(5), fields, 5, GenerateOptions{})
+		assert.Len(t, data, 5)
+		assert.Empty(t, client.prompts)
+	})
+}
+
+// TestTruncateRows tests dropping extra rows
+func TestTruncateRows(t *testing.T) {
+	assert.Len(t, TruncateRows(idData(12), 10), 10)
+	assert.Len(t, TruncateRows(idData(8), 10), 8, "Short data is left as is")
+}
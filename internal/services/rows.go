@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+FilterRowsSince returns the rows changed after since.
+
+rowTimes holds the last-modified time of each row, aligned by index. Rows
+without an entry (datasets stored before per-row tracking existed) fall back
+to the dataset's creation time.
+*/
+func FilterRowsSince(data []map[string]interface{}, rowTimes []time.Time, fallback, since time.Time) []map[string]interface{} {
+	filtered := []map[string]interface{}{}
+
+	for i, row := range data {
+		changedAt := fallback
+		if i < len(rowTimes) {
+			changedAt = rowTimes[i]
+		}
+
+		if changedAt.After(since) {
+			filtered = append(filtered, row)
+		}
+	}
+
+	return filtered
+}
+
+/*
+SelectRows returns the rows at the given indices, in that order, together
+with their modification times so the result can still be filtered with
+FilterRowsSince. Rows without a recorded time get fallback. Indices must be
+valid for data.
+*/
+func SelectRows(data []map[string]interface{}, rowTimes []time.Time, fallback time.Time, indices []int) ([]map[string]interface{}, []time.Time) {
+	selected := make([]map[string]interface{}, len(indices))
+	times := make([]time.Time, len(indices))
+
+	for i, index := range indices {
+		selected[i] = data[index]
+		times[i] = fallback
+		if index < len(rowTimes) {
+			times[i] = rowTimes[index]
+		}
+	}
+
+	return selected, times
+}
+
+// SelectFields returns copies of the rows with only the given fields; fields
+// a row lacks stay missing rather than becoming null
+func SelectFields(data []map[string]interface{}, fields []string) []map[string]interface{} {
+	projected := make([]map[string]interface{}, len(data))
+
+	for i, row := range data {
+		projected[i] = make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := row[field]; ok {
+				projected[i][field] = value
+			}
+		}
+	}
+
+	return projected
+}
+
+// RowFilter keeps the rows whose Field is Value
+type RowFilter struct {
+	Field string
+	Value string
+}
+
+// ParseRowFilters parses field=value filters; every field must be one of fieldNames
+func ParseRowFilters(raw []string, fieldNames []string) ([]RowFilter, error) {
+	filters := make([]RowFilter, 0, len(raw))
+	for _, filter := range raw {
+		field, value, ok := strings.Cut(filter, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("filter '%s' must be field=value", filter)
+		}
+		if !containsString(fieldNames, field) {
+			return nil, fmt.Errorf("filter field '%s' is not in the dataset (fields: %s)", field, strings.Join(fieldNames, ", "))
+		}
+		filters = append(filters, RowFilter{Field: field, Value: value})
+	}
+	return filters, nil
+}
+
+/*
+FilterRowsByValue returns the rows matching every filter.
+
+Values are compared as they appear in CSV exports, so numbers match without
+trailing zeros (42, 9.5), booleans as true/false, and an empty value matches
+nulls and missing fields as well as empty strings.
+*/
+func FilterRowsByValue(data []map[string]interface{}, filters []RowFilter) []map[string]interface{} {
+	filtered := []map[string]interface{}{}
+
+rows:
+	for _, row := range data {
+		for _, filter := range filters {
+			if formatValue(row[filter.Field]) != filter.Value {
+				continue rows
+			}
+		}
+		filtered = append(filtered, row)
+	}
+
+	return filtered
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilterRowsSince tests incremental row filtering by modification time
+func TestFilterRowsSince(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	data := []map[string]interface{}{
+		{"id": float64(1)},
+		{"id": float64(2)},
+		{"id": float64(3)},
+		{"id": float64(4)},
+	}
+	rowTimes := []time.Time{
+		base,
+		base.Add(2 * time.Hour),
+		base.Add(30 * time.Minute),
+	}
+
+	// Row 4 has no timestamp and falls back to the dataset creation time
+	filtered := FilterRowsSince(data, rowTimes, base, base.Add(15*time.Minute))
+	assert.Equal(t, []map[string]interface{}{{"id": float64(2)}, {"id": float64(3)}}, filtered)
+
+	// Strictly after: a row changed exactly at since is excluded
+	filtered = FilterRowsSince(data, rowTimes, base, base.Add(2*time.Hour))
+	assert.Empty(t, filtered)
+
+	// Everything changed after a point before creation
+	filtered = FilterRowsSince(data, rowTimes, base, base.Add(-time.Minute))
+	assert.Len(t, filtered, 4)
+
+	// Legacy datasets without row timestamps use the fallback for all rows
+	filtered = FilterRowsSince(data, nil, base, base.Add(-time.Minute))
+	assert.Len(t, filtered, 4)
+	filtered = FilterRowsSince(data, nil, base, base)
+	assert.Empty(t, filtered)
+}
+
+// TestSelectRows tests projecting rows by index
+func TestSelectRows(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	data := []map[string]interface{}{
+		{"id": float64(1)},
+		{"id": float64(2)},
+		{"id": float64(3)},
+	}
+	rowTimes := []time.Time{base, base.Add(time.Hour)}
+
+	selected, times := SelectRows(data, rowTimes, base.Add(-time.Hour), []int{2, 0})
+	assert.Equal(t, []map[string]interface{}{{"id": float64(3)}, {"id": float64(1)}}, selected, "Rows follow the requested order")
+	assert.Equal(t, []time.Time{base.Add(-time.Hour), base}, times, "Rows without a time get the fallback")
+
+	// The selection can still be filtered incrementally
+	assert.Equal(t, []map[string]interface{}{{"id": float64(1)}}, FilterRowsSince(selected, times, base, base.Add(-time.Minute)))
+}
+
+// TestSelectFields tests projecting rows onto a subset of fields
+func TestSelectFields(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "Ann", "email": "ann@example.com"},
+		{"id": float64(2), "email": nil},
+	}
+
+	projected := SelectFields(data, []string{"email", "name"})
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "Ann", "email": "ann@example.com"},
+		{"email": nil},
+	}, projected)
+	assert.Len(t, data[0], 3, "The original rows are unchanged")
+}
+
+// TestParseRowFilters tests parsing field=value filters
+func TestParseRowFilters(t *testing.T) {
+	fields := []string{"id", "city", "note"}
+
+	filters, err := ParseRowFilters([]string{"city=London", "note=a=b", "id="}, fields)
+	require.NoError(t, err)
+	assert.Equal(t, []RowFilter{{"city", "London"}, {"note", "a=b"}, {"id", ""}}, filters)
+
+	_, err = ParseRowFilters([]string{"country=UK"}, fields)
+	assert.EqualError(t, err, "filter field 'country' is not in the dataset (fields: id, city, note)")
+
+	for _, raw := range []string{"city", "=London"} {
+		_, err = ParseRowFilters([]string{raw}, fields)
+		assert.Error(t, err, raw)
+	}
+}
+
+// TestFilterRowsByValue tests filtering rows by field values
+func TestFilterRowsByValue(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "city": "London", "active": true},
+		{"id": json.Number("2"), "city": "Paris", "active": false},
+		{"id": float64(3), "city": "London", "active": false},
+		{"id": float64(4), "city": nil},
+	}
+
+	assert.Equal(t, []map[string]interface{}{data[0], data[2]}, FilterRowsByValue(data, []RowFilter{{"city", "London"}}))
+	assert.Equal(t, []map[string]interface{}{data[2]}, FilterRowsByValue(data, []RowFilter{{"city", "London"}, {"active", "false"}}), "Every filter must match")
+	assert.Equal(t, []map[string]interface{}{data[1]}, FilterRowsByValue(data, []RowFilter{{"id", "2"}}), "Numbers match as exported")
+	assert.Equal(t, []map[string]interface{}{data[3]}, FilterRowsByValue(data, []RowFilter{{"city", ""}}), "Empty values match nulls")
+	assert.Empty(t, FilterRowsByValue(data, []RowFilter{{"city", "london"}}), "Comparisons are case-sensitive")
+	assert.Equal(t, data, FilterRowsByValue(data, nil))
+}
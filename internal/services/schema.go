@@ -0,0 +1,319 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// ErrSchemaMismatch is returned when generated data breaks the requested schema
+var ErrSchemaMismatch = errors.New("generated data does not match the requested fields")
+
+// schemaDateLayout is the format of date schema fields
+const schemaDateLayout = "2006-01-02"
+
+// schemaPrompt tells the model the exact columns and value types to use,
+// one "- "name": type[, constraints]" line per field
+func schemaPrompt(schema []models.SchemaField) string {
+	var prompt strings.Builder
+	prompt.WriteString("\n\nUse exactly these fields, in this order, with values of these types:")
+
+	for _, field := range schema {
+		prompt.WriteString(fmt.Sprintf("\n- %q: %s", field.Name, field.Type))
+		if field.Type == models.SchemaTypeDate {
+			prompt.WriteString(` (a "YYYY-MM-DD" string)`)
+		}
+		switch {
+		case field.Min != nil && field.Max != nil:
+			prompt.WriteString(fmt.Sprintf(", from %g to %g", *field.Min, *field.Max))
+		case field.Min != nil:
+			prompt.WriteString(fmt.Sprintf(", at least %g", *field.Min))
+		case field.Max != nil:
+			prompt.WriteString(fmt.Sprintf(", at most %g", *field.Max))
+		}
+		if field.Unique {
+			prompt.WriteString(", unique across all rows")
+		}
+	}
+
+	return prompt.String()
+}
+
+// SchemaFieldNames returns the field names of schema in order
+func SchemaFieldNames(schema []models.SchemaField) []string {
+	names := make([]string, len(schema))
+	for i, field := range schema {
+		names[i] = field.Name
+	}
+	return names
+}
+
+/*
+CheckSchema verifies generated rows against an explicit schema: the fields
+must be exactly the schema's, in any order, and every row must have a
+value of the field's type for each of them, within the field's range and
+not repeated when the field is unique. Nulls do not match any type.
+*/
+func CheckSchema(data []map[string]interface{}, fieldNames []string, schema []models.SchemaField) error {
+	expected := SchemaFieldNames(schema)
+	if len(fieldNames) != len(expected) {
+		return fmt.Errorf("%w: got fields %s, want %s", ErrSchemaMismatch, strings.Join(fieldNames, ", "), strings.Join(expected, ", "))
+	}
+	for _, name := range expected {
+		if !containsString(fieldNames, name) {
+			return fmt.Errorf("%w: got fields %s, want %s", ErrSchemaMismatch, strings.Join(fieldNames, ", "), strings.Join(expected, ", "))
+		}
+	}
+
+	seen := make(map[string]map[string]bool, len(schema))
+	for i, row := range data {
+		for _, field := range schema {
+			value, ok := row[field.Name]
+			if !ok {
+				return fmt.Errorf("%w: row %d has no field '%s'", ErrSchemaMismatch, i+1, field.Name)
+			}
+			encoded, _ := json.Marshal(value)
+			if !matchesSchemaType(value, field.Type) {
+				return fmt.Errorf("%w: field '%s' of row %d is %s, want %s", ErrSchemaMismatch, field.Name, i+1, encoded, field.Type)
+			}
+
+			if number, ok := schemaNumber(value); ok {
+				if field.Min != nil && number < *field.Min {
+					return fmt.Errorf("%w: field '%s' of row %d is %s, below the min %g", ErrSchemaMismatch, field.Name, i+1, encoded, *field.Min)
+				}
+				if field.Max != nil && number > *field.Max {
+					return fmt.Errorf("%w: field '%s' of row %d is %s, above the max %g", ErrSchemaMismatch, field.Name, i+1, encoded, *field.Max)
+				}
+			}
+
+			if field.Unique {
+				if seen[field.Name] == nil {
+					seen[field.Name] = map[string]bool{}
+				}
+				key := uniqueKey(value)
+				if seen[field.Name][key] {
+					return fmt.Errorf("%w: field '%s' of row %d repeats %s, its values must be unique", ErrSchemaMismatch, field.Name, i+1, encoded)
+				}
+				seen[field.Name][key] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+EnforceSchemaConstraints fixes generated rows that break the schema's
+constraints and returns how many rows it changed. Numbers outside a
+field's range are clamped to it. Repeated values of unique fields are
+replaced: numbers by an unused whole number within the range, dates by
+the next unused day and strings by adding " 2", " 3"... Values of the wrong
+type are left for CheckSchema to report, as are repeats that no unused
+value within the range can replace.
+*/
+func EnforceSchemaConstraints(data []map[string]interface{}, schema []models.SchemaField) int {
+	adjusted := make(map[int]bool)
+
+	for _, field := range schema {
+		if field.Min != nil || field.Max != nil {
+			for i, row := range data {
+				number, ok := schemaNumber(row[field.Name])
+				if !ok {
+					continue
+				}
+				if field.Min != nil && number < *field.Min {
+					row[field.Name] = withNumber(row[field.Name], *field.Min)
+					adjusted[i] = true
+				} else if field.Max != nil && number > *field.Max {
+					row[field.Name] = withNumber(row[field.Name], *field.Max)
+					adjusted[i] = true
+				}
+			}
+		}
+
+		if field.Unique {
+			dedupeField(data, field, adjusted)
+		}
+	}
+
+	return len(adjusted)
+}
+
+// dedupeField replaces repeated values of a unique field, recording the
+// changed rows in adjusted
+func dedupeField(data []map[string]interface{}, field models.SchemaField, adjusted map[int]bool) {
+	used := make(map[string]bool, len(data))
+	for _, row := range data {
+		if matchesSchemaType(row[field.Name], field.Type) {
+			used[uniqueKey(row[field.Name])] = true
+		}
+	}
+
+	seen := make(map[string]bool, len(data))
+	for i, row := range data {
+		value := row[field.Name]
+		if !matchesSchemaType(value, field.Type) {
+			continue
+		}
+		key := uniqueKey(value)
+		if !seen[key] {
+			seen[key] = true
+			continue
+		}
+
+		replacement, ok := unusedValue(value, field, used)
+		if !ok {
+			continue
+		}
+		row[field.Name] = replacement
+		used[uniqueKey(replacement)] = true
+		seen[uniqueKey(replacement)] = true
+		adjusted[i] = true
+	}
+}
+
+// unusedValue finds a value of the field's type that is not in used,
+// derived from the repeated value
+func unusedValue(value interface{}, field models.SchemaField, used map[string]bool) (interface{}, bool) {
+	// Each attempt skips at most one used value, so this many always suffice
+	attempts := len(used) + 1
+
+	switch field.Type {
+	case models.SchemaTypeNumber:
+		low, high := math.Inf(-1), math.Inf(1)
+		if field.Min != nil {
+			low = math.Ceil(*field.Min)
+		}
+		if field.Max != nil {
+			high = math.Floor(*field.Max)
+		}
+
+		// Upwards from the repeated value, then downwards from it
+		start, _ := schemaNumber(value)
+		start = math.Max(math.Min(math.Floor(start), high), low)
+		for _, step := range []float64{1, -1} {
+			for n, tries := start, 0; n >= low && n <= high && tries <= attempts; n, tries = n+step, tries+1 {
+				if candidate := withNumber(value, n); !used[uniqueKey(candidate)] {
+					return candidate, true
+				}
+			}
+		}
+		return nil, false
+
+	case models.SchemaTypeDate:
+		day, _ := time.Parse(schemaDateLayout, value.(string))
+		for tries := 0; tries <= attempts; tries++ {
+			day = day.AddDate(0, 0, 1)
+			if candidate := day.Format(schemaDateLayout); !used[candidate] {
+				return candidate, true
+			}
+		}
+
+	case models.SchemaTypeString:
+		for n := 2; n <= attempts+1; n++ {
+			if candidate := fmt.Sprintf("%s %d", value, n); !used[candidate] {
+				return candidate, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// schemaNumber returns a numeric schema value as a float64
+func schemaNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// withNumber returns n in the representation of like, so json.Number
+// values stay json.Number
+func withNumber(like interface{}, n float64) interface{} {
+	if _, ok := like.(json.Number); ok {
+		return json.Number(strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	return n
+}
+
+// uniqueKey identifies a value for uniqueness checks; numbers compare by
+// value, so 7 and 7.0 are the same
+func uniqueKey(value interface{}) string {
+	if number, ok := schemaNumber(value); ok {
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// matchesSchemaType reports whether a decoded JSON value has the schema type
+func matchesSchemaType(value interface{}, fieldType string) bool {
+	switch fieldType {
+	case models.SchemaTypeString:
+		_, ok := value.(string)
+		return ok
+	case models.SchemaTypeNumber:
+		switch value.(type) {
+		case float64, json.Number, int, int64:
+			return true
+		}
+		return false
+	case models.SchemaTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case models.SchemaTypeDate:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.Parse(schemaDateLayout, s)
+		return err == nil
+	}
+	return false
+}
+
+// schemaValue returns a placeholder value of the field's type for row,
+// taken from the template heuristics when they produce the right type
+func schemaValue(rng *rand.Rand, row int, field models.SchemaField, references []string) interface{} {
+	if value := templateValue(rng, row, field.Name, references); matchesSchemaType(value, field.Type) {
+		return value
+	}
+
+	switch field.Type {
+	case models.SchemaTypeNumber:
+		return float64(rng.Intn(1000))
+	case models.SchemaTypeBoolean:
+		return rng.Intn(2) == 0
+	case models.SchemaTypeDate:
+		return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, rng.Intn(5*365)).Format(schemaDateLayout)
+	}
+	return fmt.Sprintf("%s %d", field.Name, row)
+}
+
+// schemaRows generates rowCount placeholder rows following schema, numbered
+// after offset, for the generators that don't use a model
+func schemaRows(rng *rand.Rand, schema []models.SchemaField, rowCount, offset int, references map[string][]string) ([]map[string]interface{}, []string) {
+	data := make([]map[string]interface{}, rowCount)
+	for i := range data {
+		data[i] = make(map[string]interface{}, len(schema))
+		for _, field := range schema {
+			data[i][field.Name] = schemaValue(rng, offset+i+1, field, references[field.Name])
+		}
+	}
+	return data, SchemaFieldNames(schema)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = []models.SchemaField{
+	{Name: "id", Type: models.SchemaTypeNumber},
+	{Name: "name", Type: models.SchemaTypeString},
+	{Name: "active", Type: models.SchemaTypeBoolean},
+	{Name: "signed_up", Type: models.SchemaTypeDate},
+}
+
+// TestBuildPrompt_Schema tests that the prompt lists the schema's columns and types
+func TestBuildPrompt_Schema(t *testing.T) {
+	prompt := buildPrompt("users", 5, GenerateOptions{Schema: testSchema, Fields: []string{"id", "name", "active", "signed_up"}})
+
+	assert.Contains(t, prompt, "Use exactly these fields, in this order, with values of these types:\n"+
+		`- "id": number`+"\n"+
+		`- "name": string`+"\n"+
+		`- "active": boolean`+"\n"+
+		`- "signed_up": date (a "YYYY-MM-DD" string)`)
+	assert.NotContains(t, prompt, `["id","name","active","signed_up"]`, "The schema replaces the plain field list")
+
+	schema := mockSchema(prompt)
+	assert.Equal(t, testSchema, schema, "The mock client reads the schema back")
+}
+
+// TestCheckSchema tests detecting generated data that breaks the schema
+func TestCheckSchema(t *testing.T) {
+	fields := []string{"signed_up", "active", "name", "id"}
+	row := func() map[string]interface{} {
+		return map[string]interface{}{"id": json.Number("1"), "name": "Ada", "active": true, "signed_up": "2024-02-29"}
+	}
+
+	assert.NoError(t, CheckSchema([]map[string]interface{}{row(), row()}, fields, testSchema), "Field order does not matter")
+
+	tests := []struct {
+		name     string
+		fields   []string
+		change   func(row map[string]interface{})
+		expected string
+	}{
+		{"Missing field", []string{"id", "name", "active"}, nil, "got fields id, name, active, want id, name, active, signed_up"},
+		{"Other field", []string{"id", "name", "active", "email"}, nil, "got fields id, name, active, email, want id, name, active, signed_up"},
+		{"Missing value", fields, func(row map[string]interface{}) { delete(row, "name") }, "row 2 has no field 'name'"},
+		{"Number as string", fields, func(row map[string]interface{}) { row["id"] = "1" }, `field 'id' of row 2 is "1", want number`},
+		{"Boolean as string", fields, func(row map[string]interface{}) { row["active"] = "yes" }, `field 'active' of row 2 is "yes", want boolean`},
+		{"Null", fields, func(row map[string]interface{}) { row["name"] = nil }, "field 'name' of row 2 is null, want string"},
+		{"Date with time", fields, func(row map[string]interface{}) { row["signed_up"] = "2024-02-29T10:00:00Z" }, `field 'signed_up' of row 2 is "2024-02-29T10:00:00Z", want date`},
+		{"Invalid date", fields, func(row map[string]interface{}) { row["signed_up"] = "2023-02-29" }, `field 'signed_up' of row 2 is "2023-02-29", want date`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []map[string]interface{}{row(), row()}
+			if tt.change != nil {
+				tt.change(data[1])
+			}
+
+			err := CheckSchema(data, tt.fields, testSchema)
+			assert.ErrorIs(t, err, ErrSchemaMismatch)
+			assert.EqualError(t, err, ErrSchemaMismatch.Error()+": "+tt.expected)
+		})
+	}
+}
+
+// TestSchemaGenerators tests that the local generators follow the schema
+func TestSchemaGenerators(t *testing.T) {
+	var slept []time.Duration
+	fixed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, generator := range map[string]Generator{
+		"Template": NewTemplateService(),
+		"Faker":    &FakerService{now: func() time.Time { return fixed }},
+		"Mock":     newMockService(MockOptions{}, &slept),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, fields, err := generator.GenerateMockData(context.Background(), "users", 5, GenerateOptions{Schema: testSchema})
+			require.NoError(t, err)
+			assert.Len(t, data, 5)
+			assert.Equal(t, SchemaFieldNames(testSchema), fields)
+			assert.NoError(t, CheckSchema(data, fields, testSchema))
+		})
+	}
+}
+
+// TestBuildPrompt_SchemaConstraints tests that constraints are spelled out in the prompt
+func TestBuildPrompt_SchemaConstraints(t *testing.T) {
+	low, high := 18.0, 65.5
+	prompt := buildPrompt("users", 5, GenerateOptions{Schema: []models.SchemaField{
+		{Name: "id", Type: models.SchemaTypeNumber, Unique: true, Min: &low},
+		{Name: "age", Type: models.SchemaTypeNumber, Min: &low, Max: &high},
+		{Name: "score", Type: models.SchemaTypeNumber, Max: &high},
+	}})
+
+	assert.Contains(t, prompt, `- "id": number, at least 18, unique across all rows`)
+	assert.Contains(t, prompt, `- "age": number, from 18 to 65.5`)
+	assert.Contains(t, prompt, `- "score": number, at most 65.5`)
+	assert.Len(t, mockSchema(prompt), 3, "The mock client still reads constrained fields")
+}
+
+// TestEnforceSchemaConstraints_Unique tests deduplicating unique columns of a generated batch
+func TestEnforceSchemaConstraints_Unique(t *testing.T) {
+	low, high := 1.0, 10.0
+	schema := []models.SchemaField{
+		{Name: "id", Type: models.SchemaTypeNumber, Unique: true, Min: &low, Max: &high},
+		{Name: "email", Type: models.SchemaTypeString, Unique: true},
+		{Name: "joined", Type: models.SchemaTypeDate, Unique: true},
+		{Name: "plan", Type: models.SchemaTypeString},
+	}
+
+	// Models like to restart ids and reuse sample values
+	data := make([]map[string]interface{}, 8)
+	for i := range data {
+		data[i] = map[string]interface{}{
+			"id":     json.Number(strconv.Itoa(i%3 + 1)),
+			"email":  "ada@example.com",
+			"joined": "2024-01-31",
+			"plan":   "pro",
+		}
+	}
+	data[7]["email"] = "ada@example.com 2"
+
+	assert.Equal(t, 7, EnforceSchemaConstraints(data, schema), "Every row but the first repeats a value")
+	require.NoError(t, CheckSchema(data, SchemaFieldNames(schema), schema))
+
+	assert.Equal(t, json.Number("1"), data[0]["id"], "First occurrences are kept")
+	assert.Equal(t, json.Number("4"), data[3]["id"], "Repeats take the next unused number")
+	assert.Equal(t, "ada@example.com 3", data[1]["email"], "Suffixes skip values already in use")
+	assert.Equal(t, "ada@example.com 2", data[7]["email"], "Only repeats are changed")
+	assert.Equal(t, "2024-02-01", data[1]["joined"])
+	for _, row := range data {
+		assert.Equal(t, "pro", row["plan"], "Fields without the constraint keep their repeats")
+	}
+
+	assert.Equal(t, 0, EnforceSchemaConstraints(data, schema), "Enforcing again changes nothing")
+}
+
+// TestEnforceSchemaConstraints_Range tests clamping and range-bound deduplication
+func TestEnforceSchemaConstraints_Range(t *testing.T) {
+	low, high := 18.0, 20.0
+	age := models.SchemaField{Name: "age", Type: models.SchemaTypeNumber, Min: &low, Max: &high}
+
+	data := []map[string]interface{}{{"age": 12.0}, {"age": 19.0}, {"age": json.Number("70")}, {"age": "old"}}
+	assert.Equal(t, 2, EnforceSchemaConstraints(data, []models.SchemaField{age}))
+	assert.Equal(t, []interface{}{18.0, 19.0, json.Number("20"), "old"}, []interface{}{data[0]["age"], data[1]["age"], data[2]["age"], data[3]["age"]})
+
+	unique := age
+	unique.Unique = true
+	data = []map[string]interface{}{{"age": 20.0}, {"age": 20.0}, {"age": 20.0}, {"age": 20.0}}
+	assert.Equal(t, 2, EnforceSchemaConstraints(data, []models.SchemaField{unique}))
+	assert.Equal(t, []interface{}{20.0, 19.0, 18.0, 20.0}, []interface{}{data[0]["age"], data[1]["age"], data[2]["age"], data[3]["age"]})
+
+	err := CheckSchema(data, []string{"age"}, []models.SchemaField{unique})
+	assert.EqualError(t, err, ErrSchemaMismatch.Error()+": field 'age' of row 4 repeats 20, its values must be unique", "Three values cannot fill four rows")
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SQL dialects accepted by ExportOptions.Dialect; empty means PostgreSQL
+const (
+	SQLDialectPostgres = "postgres"
+	SQLDialectMySQL    = "mysql"
+)
+
+// sqlDialectAliases maps accepted dialect names to their canonical name
+var sqlDialectAliases = map[string]string{
+	"":                 SQLDialectPostgres,
+	SQLDialectPostgres: SQLDialectPostgres,
+	"postgresql":       SQLDialectPostgres,
+	SQLDialectMySQL:    SQLDialectMySQL,
+}
+
+// ParseSQLDialect returns the canonical name of a dialect
+func ParseSQLDialect(name string) (string, error) {
+	dialect, ok := sqlDialectAliases[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown SQL dialect '%s' (use one of %v)", name, SQLDialects())
+	}
+	return dialect, nil
+}
+
+// SQLDialects returns the canonical names of the supported SQL dialects
+func SQLDialects() []string {
+	seen := map[string]bool{}
+	dialects := []string{}
+	for _, dialect := range sqlDialectAliases {
+		if !seen[dialect] {
+			seen[dialect] = true
+			dialects = append(dialects, dialect)
+		}
+	}
+	sort.Strings(dialects)
+	return dialects
+}
+
+func (o ExportOptions) mysql() bool {
+	return o.Dialect == SQLDialectMySQL
+}
+
+// quoteIdent quotes a column name for the dialect: MySQL names are always
+// backtick-quoted, PostgreSQL names only when needed (see quoteIdent)
+func (o ExportOptions) quoteIdent(name string) string {
+	if o.mysql() {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return quoteIdent(name)
+}
+
+// quoteIdents quotes each name and joins them into a column list
+func (o ExportOptions) quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = o.quoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteTable quotes the table name for MySQL; PostgreSQL table names are
+// written as given
+func (o ExportOptions) quoteTable(name string) string {
+	if o.mysql() {
+		return o.quoteIdent(name)
+	}
+	return name
+}
+
+// sqlString writes a string literal; MySQL also treats backslashes as escapes
+func (o ExportOptions) sqlString(value string) string {
+	escaped := strings.ReplaceAll(value, "'", "''")
+	if o.mysql() {
+		escaped = strings.ReplaceAll(escaped, `\`, `\\`)
+	}
+	return "'" + escaped + "'"
+}
+
+// mysqlTypes maps PostgreSQL column types to plain MySQL equivalents;
+// unlisted types are written as is
+var mysqlTypes = map[string]string{
+	"NUMERIC":     "DOUBLE", // MySQL NUMERIC without precision is DECIMAL(10,0)
+	"DECIMAL":     "DOUBLE",
+	"TIMESTAMPTZ": "TIMESTAMP",
+	"UUID":        "CHAR(36)",
+	"JSONB":       "JSON",
+	"SERIAL":      "INT",
+	"BIGSERIAL":   "BIGINT",
+	"SMALLSERIAL": "SMALLINT",
+	"BYTEA":       "BLOB",
+}
+
+// columnType adapts a stored or inferred column type to the dialect. MySQL
+// cannot index TEXT without a length, so a TEXT key column (primary or
+// unique) becomes VARCHAR(255).
+func (o ExportOptions) columnType(colType string, key bool) string {
+	if !o.mysql() {
+		return colType
+	}
+
+	if mapped, ok := mysqlTypes[strings.ToUpper(strings.TrimSpace(colType))]; ok {
+		colType = mapped
+	}
+	if key && strings.EqualFold(colType, "TEXT") {
+		colType = "VARCHAR(255)"
+	}
+	return colType
+}
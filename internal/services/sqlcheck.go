@@ -0,0 +1,209 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+)
+
+// uuidPattern matches canonical textual UUIDs
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// sqlTypeLength extracts n from VARCHAR(n) / CHARACTER VARYING(n) / CHAR(n)
+var sqlTypeLength = regexp.MustCompile(`^(VARCHAR|CHARACTER VARYING|CHAR|CHARACTER)\s*\(\s*(\d+)\s*\)$`)
+
+/*
+CheckSQLCompatibility statically checks whether the SQL export of a dataset
+can be inserted into a table with the given columns, without connecting to
+that database.
+
+Column names are matched case-insensitively, like unquoted PostgreSQL
+identifiers. Every value of a field is checked against its column: nulls
+against NOT NULL, numbers against numeric types (integers for INT types),
+booleans, dates and timestamps, UUIDs and VARCHAR(n) lengths. TEXT, JSON and
+unrecognized types accept anything. Column types must look like SQL type
+names; anything else is an ErrInvalidExportOption.
+*/
+func CheckSQLCompatibility(data []map[string]interface{}, fieldNames []string, columns []models.TargetColumn) ([]models.SQLMismatch, error) {
+	byName := make(map[string]models.TargetColumn, len(columns))
+	for _, column := range columns {
+		if strings.TrimSpace(column.Name) == "" {
+			return nil, fmt.Errorf("%w: every column needs a name", ErrInvalidExportOption)
+		}
+		if !sqlTypePattern.MatchString(strings.TrimSpace(column.Type)) {
+			return nil, fmt.Errorf("%w: '%s' is not a valid SQL type for column '%s'", ErrInvalidExportOption, column.Type, column.Name)
+		}
+		byName[strings.ToLower(column.Name)] = column
+	}
+
+	mismatches := []models.SQLMismatch{}
+	present := make(map[string]bool, len(fieldNames))
+
+	for _, field := range fieldNames {
+		column, ok := byName[strings.ToLower(field)]
+		if !ok {
+			mismatches = append(mismatches, models.SQLMismatch{
+				Field:  field,
+				Issue:  models.SQLIssueUnknownColumn,
+				Detail: "the target table has no column with this name",
+			})
+			continue
+		}
+		present[strings.ToLower(field)] = true
+
+		mismatches = append(mismatches, checkColumnValues(data, field, column)...)
+	}
+
+	for _, column := range columns {
+		if column.Nullable || column.HasDefault || present[strings.ToLower(column.Name)] {
+			continue
+		}
+		mismatches = append(mismatches, models.SQLMismatch{
+			Field:  column.Name,
+			Issue:  models.SQLIssueMissingRequired,
+			Detail: "NOT NULL column without a default is not part of the dataset",
+		})
+	}
+
+	return mismatches, nil
+}
+
+// checkColumnValues reports null, type and length problems of one field
+func checkColumnValues(data []map[string]interface{}, field string, column models.TargetColumn) []models.SQLMismatch {
+	colType := strings.ToUpper(strings.Join(strings.Fields(column.Type), " "))
+	accepts, expected := sqlTypeCheck(colType)
+
+	maxLength := 0
+	if match := sqlTypeLength.FindStringSubmatch(colType); match != nil {
+		maxLength, _ = strconv.Atoi(match[2])
+	}
+
+	nulls, wrong, long := 0, 0, 0
+	var wrongExample, longExample string
+
+	for _, row := range data {
+		value := row[field]
+		if value == nil {
+			nulls++
+			continue
+		}
+
+		if accepts != nil && !accepts(value) {
+			if wrong == 0 {
+				wrongExample = formatValue(value)
+			}
+			wrong++
+		}
+
+		if text, ok := value.(string); ok && maxLength > 0 && utf8.RuneCountInString(text) > maxLength {
+			if long == 0 {
+				longExample = text
+			}
+			long++
+		}
+	}
+
+	var mismatches []models.SQLMismatch
+	if nulls > 0 && !column.Nullable {
+		mismatches = append(mismatches, models.SQLMismatch{
+			Field:  field,
+			Issue:  models.SQLIssueNotNull,
+			Detail: fmt.Sprintf("column %s is NOT NULL but the field has null values", column.Name),
+			Rows:   nulls,
+		})
+	}
+	if wrong > 0 {
+		mismatches = append(mismatches, models.SQLMismatch{
+			Field:   field,
+			Issue:   models.SQLIssueType,
+			Detail:  fmt.Sprintf("column %s is %s and expects %s", column.Name, colType, expected),
+			Rows:    wrong,
+			Example: wrongExample,
+		})
+	}
+	if long > 0 {
+		mismatches = append(mismatches, models.SQLMismatch{
+			Field:   field,
+			Issue:   models.SQLIssueTooLong,
+			Detail:  fmt.Sprintf("column %s allows at most %d characters", column.Name, maxLength),
+			Rows:    long,
+			Example: longExample,
+		})
+	}
+	return mismatches
+}
+
+// sqlTypeCheck returns a value check and a description for a column type;
+// a nil check accepts every value
+func sqlTypeCheck(colType string) (func(interface{}) bool, string) {
+	base := colType
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+
+	switch base {
+	case "SMALLINT", "INTEGER", "INT", "INT2", "INT4", "INT8", "BIGINT", "SERIAL", "BIGSERIAL", "SMALLSERIAL":
+		return isIntegerValue, "integers"
+	case "NUMERIC", "DECIMAL", "REAL", "DOUBLE PRECISION", "FLOAT", "FLOAT4", "FLOAT8", "MONEY":
+		return isNumberValue, "numbers"
+	case "BOOLEAN", "BOOL":
+		return func(v interface{}) bool { _, ok := v.(bool); return ok }, "booleans"
+	case "DATE":
+		return isDateValue, "YYYY-MM-DD dates"
+	case "TIMESTAMP", "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE":
+		return isTimestampValue, "timestamps"
+	case "UUID":
+		return func(v interface{}) bool { s, ok := v.(string); return ok && uuidPattern.MatchString(s) }, "UUIDs"
+	default:
+		return nil, "any value"
+	}
+}
+
+func isNumberValue(value interface{}) bool {
+	switch value.(type) {
+	case float64, json.Number:
+		return true
+	}
+	return false
+}
+
+func isIntegerValue(value interface{}) bool {
+	switch v := value.(type) {
+	case float64:
+		return v == math.Trunc(v)
+	case json.Number:
+		_, err := v.Int64()
+		return err == nil
+	}
+	return false
+}
+
+func isDateValue(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// isTimestampValue accepts RFC3339, "YYYY-MM-DD HH:MM:SS" and plain dates
+func isTimestampValue(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
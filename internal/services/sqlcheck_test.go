@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kennyg37/wrapperX/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckSQLCompatibility tests static checks against a target table
+func TestCheckSQLCompatibility(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "Name": "Jane", "score": float64(9.5), "active": true, "born": "1990-04-01", "ref": "123e4567-e89b-12d3-a456-426614174000"},
+		{"id": json.Number("2"), "Name": "Bartholomew", "score": nil, "active": "yes", "born": "soon", "ref": "not-a-uuid"},
+	}
+	fields := []string{"id", "Name", "score", "active", "born", "ref", "nickname"}
+
+	t.Run("Compatible table", func(t *testing.T) {
+		mismatches, err := CheckSQLCompatibility(data[:1], fields[:6], []models.TargetColumn{
+			{Name: "id", Type: "BIGINT"},
+			{Name: "name", Type: "varchar(20)"},
+			{Name: "score", Type: "numeric(4, 1)", Nullable: true},
+			{Name: "active", Type: "BOOLEAN"},
+			{Name: "born", Type: "date"},
+			{Name: "ref", Type: "UUID"},
+			{Name: "created_at", Type: "TIMESTAMP", HasDefault: true},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, mismatches)
+	})
+
+	t.Run("Mismatches", func(t *testing.T) {
+		mismatches, err := CheckSQLCompatibility(data, fields, []models.TargetColumn{
+			{Name: "id", Type: "INTEGER"},
+			{Name: "name", Type: "VARCHAR(5)"},
+			{Name: "score", Type: "REAL"},
+			{Name: "active", Type: "BOOLEAN"},
+			{Name: "born", Type: "DATE"},
+			{Name: "ref", Type: "uuid"},
+			{Name: "email", Type: "TEXT"},
+		})
+		require.NoError(t, err)
+
+		issues := map[string]string{}
+		for _, m := range mismatches {
+			issues[m.Field] = m.Issue
+		}
+		assert.Equal(t, map[string]string{
+			"Name":     models.SQLIssueTooLong,
+			"score":    models.SQLIssueNotNull,
+			"active":   models.SQLIssueType,
+			"born":     models.SQLIssueType,
+			"ref":      models.SQLIssueType,
+			"nickname": models.SQLIssueUnknownColumn,
+			"email":    models.SQLIssueMissingRequired,
+		}, issues)
+
+		for _, m := range mismatches {
+			if m.Field == "active" {
+				assert.Equal(t, 1, m.Rows)
+				assert.Equal(t, "yes", m.Example)
+			}
+		}
+	})
+
+	t.Run("Integer columns reject fractions", func(t *testing.T) {
+		mismatches, err := CheckSQLCompatibility([]map[string]interface{}{{"n": float64(1.5)}}, []string{"n"}, []models.TargetColumn{{Name: "n", Type: "int"}})
+		require.NoError(t, err)
+		require.Len(t, mismatches, 1)
+		assert.Equal(t, models.SQLIssueType, mismatches[0].Issue)
+	})
+
+	t.Run("Invalid column type", func(t *testing.T) {
+		_, err := CheckSQLCompatibility(data, fields, []models.TargetColumn{{Name: "id", Type: "INT; DROP TABLE x"}})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+	})
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	_ "modernc.org/sqlite" // registers the pure Go "sqlite" driver
+)
+
+/*
+ToSQLite exports data as a SQLite database file with a single table.
+
+Column types are the stored field types or inferred from every row like
+ToSQL (NUMERIC, BOOLEAN, TEXT); whole numbers are stored as integers,
+booleans as 0/1 and objects or arrays as JSON text. Rows keep their order as
+rowids 1..n.
+
+The database is built with modernc.org/sqlite, a pure Go port of SQLite, so
+no cgo is needed. The driver only writes files, so the database is built in
+a temporary file that is removed once it has been read back.
+*/
+func (s *ExportService) ToSQLite(data []map[string]interface{}, fieldNames []string, tableName string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	inferred := InferFieldTypes(data, fieldNames)
+	columns := make([]string, len(fieldNames))
+	for i, field := range fieldNames {
+		colType, ok := s.options.FieldTypes[field]
+		if !ok {
+			colType = inferred[field]
+		}
+		columns[i] = fmt.Sprintf("%s %s", sqliteIdent(field), colType)
+	}
+
+	file, err := os.CreateTemp("", "export-*.sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write SQLite file: %w", err)
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	createSQL := fmt.Sprintf("CREATE TABLE %s (%s)", sqliteIdent(tableName), strings.Join(columns, ", "))
+	insertSQL := fmt.Sprintf("INSERT INTO %s VALUES (%s)", sqliteIdent(tableName), strings.TrimSuffix(strings.Repeat("?, ", len(fieldNames)), ", "))
+
+	if err := writeSQLite(path, createSQL, insertSQL, data, fieldNames); err != nil {
+		return nil, fmt.Errorf("failed to write SQLite file: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write SQLite file: %w", err)
+	}
+	return content, nil
+}
+
+// writeSQLite creates the table in the database at path and inserts every
+// row in one transaction
+func writeSQLite(path, createSQL, insertSQL string, data []map[string]interface{}, fieldNames []string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(createSQL); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(insertSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	values := make([]interface{}, len(fieldNames))
+	for _, row := range data {
+		for i, field := range fieldNames {
+			if values[i], err = sqliteValue(row[field]); err != nil {
+				return err
+			}
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// sqliteValue converts a field value for storage: whole numbers become
+// integers, booleans 0/1 and objects or arrays JSON text
+func sqliteValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string, int, int64:
+		return v, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+		return v, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+		return v.String(), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode SQLite value: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
+
+// sqliteIdent double-quotes an identifier for SQLite
+func sqliteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSQLite writes an exported database to a file and opens it with the
+// SQLite driver
+func openSQLite(t *testing.T, content []byte) *sql.DB {
+	t.Helper()
+	require.Equal(t, "SQLite format 3\x00", string(content[:16]))
+
+	path := filepath.Join(t.TempDir(), "export.sqlite")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// sqliteCreateSQL returns the create statement of a table
+func sqliteCreateSQL(t *testing.T, db *sql.DB, table string) string {
+	var createSQL string
+	require.NoError(t, db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&createSQL))
+	return createSQL
+}
+
+// TestExportService_ToSQLite tests reopening the database file
+func TestExportService_ToSQLite(t *testing.T) {
+	service := NewExportService()
+	fieldNames := []string{"id", "name", "score", "active", "tags", "note"}
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "Jane", "score": 9.5, "active": true, "tags": []interface{}{"a", "b"}, "note": nil},
+		{"id": float64(300), "name": "John", "score": float64(-70000), "active": false, "tags": []interface{}{}, "note": nil},
+	}
+
+	out, err := service.ToSQLite(data, fieldNames, "hospital patients")
+	require.NoError(t, err)
+
+	db := openSQLite(t, out)
+	assert.Equal(t, `CREATE TABLE "hospital patients" ("id" NUMERIC, "name" TEXT, "score" NUMERIC, "active" BOOLEAN, "tags" TEXT, "note" TEXT)`, sqliteCreateSQL(t, db, "hospital patients"))
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM "hospital patients"`).Scan(&count))
+	assert.Equal(t, 2, count)
+
+	rows, err := db.Query(`SELECT id, typeof(id), name, score, active, tags, note FROM "hospital patients" ORDER BY rowid`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var got [][]interface{}
+	for rows.Next() {
+		var id, active int64
+		var idType, name, tags string
+		var score float64
+		var note sql.NullString
+		require.NoError(t, rows.Scan(&id, &idType, &name, &score, &active, &tags, &note))
+		got = append(got, []interface{}{id, idType, name, score, active, tags, note.Valid})
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, [][]interface{}{
+		{int64(1), "integer", "Jane", 9.5, int64(1), `["a","b"]`, false},
+		{int64(300), "integer", "John", float64(-70000), int64(0), `[]`, false},
+	}, got, "Whole numbers are integers, booleans 0/1, arrays JSON and nulls NULL")
+}
+
+// TestExportService_ToSQLite_LargeData tests many rows and long values
+func TestExportService_ToSQLite_LargeData(t *testing.T) {
+	data := make([]map[string]interface{}, 20000)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": float64(i + 1), "name": fmt.Sprintf("user %d", i+1)}
+		if i%500 == 0 {
+			data[i]["name"] = strings.Repeat("x", 10000+i)
+		}
+	}
+
+	out, err := NewExportService().ToSQLite(data, []string{"id", "name"}, "users")
+	require.NoError(t, err)
+
+	db := openSQLite(t, out)
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count))
+	assert.Equal(t, len(data), count)
+
+	for _, i := range []int{0, 1, 500, 19999} {
+		var id int64
+		var name string
+		require.NoError(t, db.QueryRow(`SELECT id, name FROM users WHERE rowid = ?`, i+1).Scan(&id, &name))
+		assert.Equal(t, int64(i+1), id)
+		assert.Equal(t, data[i]["name"], name)
+	}
+}
+
+// TestExportService_ToSQLite_Options tests field types and empty data
+func TestExportService_ToSQLite_Options(t *testing.T) {
+	data := []map[string]interface{}{{"id": float64(1), "email": "a@example.com"}}
+
+	opts := DefaultExportOptions()
+	opts.FieldTypes = map[string]string{"email": "VARCHAR(255)"}
+	service := NewExportService().WithOptions(opts)
+	out, err := service.ToSQLite(data, []string{"id", "email"}, "users")
+	require.NoError(t, err)
+
+	assert.Contains(t, sqliteCreateSQL(t, openSQLite(t, out), "users"), `"email" VARCHAR(255)`)
+
+	_, err = service.ToSQLite(nil, []string{"id"}, "users")
+	assert.ErrorIs(t, err, ErrNoData)
+
+	result, err := service.Export("sqlite", data, []string{"id", "email"}, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-sqlite3", result.ContentType)
+}
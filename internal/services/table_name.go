@@ -0,0 +1,59 @@
+package services
+
+import (
+	"strings"
+)
+
+// DefaultTableName is the SQL export table when none can be derived
+const DefaultTableName = "mock_data"
+
+// latinFolds spells common accented Latin letters in ASCII
+var latinFolds = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// MaxTableNameLength is PostgreSQL's identifier limit (NAMEDATALEN - 1)
+const MaxTableNameLength = 63
+
+/*
+TableNameFromScenario derives a snake_case SQL table name from a scenario,
+e.g. "Hospital patients" -> "hospital_patients".
+
+Common accented Latin letters are folded to ASCII ("Café" -> "cafe"); after
+that only ASCII letters and digits are kept and every other run of
+characters becomes a single underscore. Names starting with a digit get a
+"t_" prefix, and long names are cut at a word boundary to fit
+MaxTableNameLength. Scenarios without any usable characters give
+DefaultTableName.
+*/
+func TableNameFromScenario(scenario string) string {
+	words := strings.FieldsFunc(latinFolds.Replace(strings.ToLower(scenario)), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(words) == 0 {
+		return DefaultTableName
+	}
+
+	prefix := ""
+	if words[0][0] >= '0' && words[0][0] <= '9' {
+		prefix = "t_"
+	}
+
+	name := prefix + words[0]
+	if len(name) > MaxTableNameLength {
+		return name[:MaxTableNameLength]
+	}
+
+	for _, word := range words[1:] {
+		if len(name)+1+len(word) > MaxTableNameLength {
+			break
+		}
+		name += "_" + word
+	}
+
+	return name
+}
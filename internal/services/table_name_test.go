@@ -0,0 +1,39 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTableNameFromScenario tests deriving SQL table names from scenarios
+func TestTableNameFromScenario(t *testing.T) {
+	tests := map[string]string{
+		"hospital patients":               "hospital_patients",
+		"  Hospital   Patients  ":         "hospital_patients",
+		"E-commerce orders (2024)!":       "e_commerce_orders_2024",
+		"users' contact-info & addresses": "users_contact_info_addresses",
+		"2024 sales":                      "t_2024_sales",
+		"Café menü items":                 "cafe_menu_items",
+		"":                                DefaultTableName,
+		"!!! ???":                         DefaultTableName,
+		"日本の顧客":                           DefaultTableName,
+		"snake_case already":              "snake_case_already",
+		"Straße 名前 list":                  "strasse_list",
+	}
+
+	for scenario, expected := range tests {
+		assert.Equal(t, expected, TableNameFromScenario(scenario), scenario)
+	}
+}
+
+// TestTableNameFromScenario_Length tests that long scenarios are cut at a word boundary
+func TestTableNameFromScenario_Length(t *testing.T) {
+	name := TableNameFromScenario(strings.Repeat("patients with insurance ", 10))
+	assert.LessOrEqual(t, len(name), MaxTableNameLength)
+	assert.False(t, strings.HasSuffix(name, "_"))
+	assert.True(t, strings.HasSuffix(name, "patients") || strings.HasSuffix(name, "with") || strings.HasSuffix(name, "insurance"), name)
+
+	assert.Len(t, TableNameFromScenario(strings.Repeat("x", 100)), MaxTableNameLength)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Limits for user-supplied export templates
+const (
+	MaxTemplateLength  = 8 << 10  // per template, in bytes
+	MaxTemplateOutput  = 10 << 20 // rendered export, in bytes
+	TemplateTimeLimit  = 5 * time.Second
+	defaultTemplateSep = "\n"
+)
+
+// ErrTemplateLimit is returned when a template exceeds its output or time limit
+var ErrTemplateLimit = errors.New("template export exceeded its limits")
+
+/*
+TemplateSpec describes a custom export built from Go text/templates.
+
+Row is executed once per row with .Row (the row map), .Fields, .Index
+(zero-based) and .Count in scope; results are joined with Separator
+(default a newline). Header and Footer are optional and see .Fields and
+.Count only.
+*/
+type TemplateSpec struct {
+	Row       string
+	Header    string
+	Footer    string
+	Separator *string
+}
+
+// templateRow is the data passed to the row template
+type templateRow struct {
+	Row    map[string]interface{}
+	Fields []string
+	Index  int
+	Count  int
+}
+
+// templateFrame is the data passed to the header and footer templates
+type templateFrame struct {
+	Fields []string
+	Count  int
+}
+
+/*
+ToTemplate renders data with a user-supplied template.
+
+Templates only get the helpers from templateFuncs; the "call" builtin is
+replaced so a template cannot invoke function values. Output is capped at
+MaxTemplateOutput bytes and rendering stops once TemplateTimeLimit has
+passed.
+*/
+func (s *ExportService) ToTemplate(data []map[string]interface{}, fieldNames []string, spec TemplateSpec) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.parseTemplate("row", spec.Row, true)
+	if err != nil {
+		return nil, err
+	}
+	header, err := s.parseTemplate("header", spec.Header, false)
+	if err != nil {
+		return nil, err
+	}
+	footer, err := s.parseTemplate("footer", spec.Footer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	separator := defaultTemplateSep
+	if spec.Separator != nil {
+		separator = *spec.Separator
+	}
+
+	out := &limitedBuffer{limit: MaxTemplateOutput}
+	deadline := time.Now().Add(TemplateTimeLimit)
+	frame := templateFrame{Fields: fieldNames, Count: len(data)}
+
+	if err := executeTemplate(header, out, frame); err != nil {
+		return nil, err
+	}
+
+	for i, values := range data {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: rendering took longer than %s", ErrTemplateLimit, TemplateTimeLimit)
+		}
+		if i > 0 {
+			out.WriteString(separator)
+		}
+		if err := executeTemplate(row, out, templateRow{Row: values, Fields: fieldNames, Index: i, Count: len(data)}); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	if err := executeTemplate(footer, out, frame); err != nil {
+		return nil, err
+	}
+
+	if out.exceeded {
+		return nil, fmt.Errorf("%w: output is larger than %d bytes", ErrTemplateLimit, MaxTemplateOutput)
+	}
+
+	return out.Bytes(), nil
+}
+
+// parseTemplate compiles one template; optional templates may be empty (nil result)
+func (s *ExportService) parseTemplate(name, text string, required bool) (*template.Template, error) {
+	if text == "" {
+		if required {
+			return nil, fmt.Errorf("%w: a row template is required", ErrInvalidExportOption)
+		}
+		return nil, nil
+	}
+
+	if len(text) > MaxTemplateLength {
+		return nil, fmt.Errorf("%w: %s template is longer than %d bytes", ErrInvalidExportOption, name, MaxTemplateLength)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(s.templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExportOption, err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate runs tmpl into out; a nil template writes nothing
+func executeTemplate(tmpl *template.Template, out *limitedBuffer, data interface{}) error {
+	if tmpl == nil {
+		return nil
+	}
+	if err := tmpl.Execute(out, data); err != nil {
+		if out.exceeded {
+			return fmt.Errorf("%w: output is larger than %d bytes", ErrTemplateLimit, MaxTemplateOutput)
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidExportOption, err)
+	}
+	return nil
+}
+
+// templateFuncs are the helpers available to export templates
+func (s *ExportService) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Shadow the builtin so templates cannot invoke function values
+		"call": func(...interface{}) (string, error) {
+			return "", errors.New("call is not available in export templates")
+		},
+
+		"value": s.options.formatValue,
+		"sql":   s.options.formatSQLValue,
+		"ident": s.options.quoteIdent,
+		"json": func(value interface{}) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"replace": strings.ReplaceAll,
+		"join":    strings.Join,
+		"last": func(index, count int) bool {
+			return index == count-1
+		},
+	}
+}
+
+// limitedBuffer fails writes once more than limit bytes would be written
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.exceeded || b.Len()+len(p) > b.limit {
+		b.exceeded = true
+		return 0, ErrTemplateLimit
+	}
+	return b.Buffer.Write(p)
+}
+
+func (b *limitedBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+TemplateService generates data without any model, for demos and CI where
+OpenAI is not configured (GENERATOR_PROVIDER=template).
+
+Unlike the FakerService fallback it is deterministic: values depend only on
+the scenario, the row number and the request seed, so the same request
+always yields the same rows and continued requests (RowOffset) extend them
+consistently. Fields are the ones the scenario names ("users with name, email
+and age"), or a default set when it names none; each value comes from the
+first heuristic matching its field name.
+*/
+type TemplateService struct{}
+
+// NewTemplateService creates a new template-based generator
+func NewTemplateService() *TemplateService {
+	return &TemplateService{}
+}
+
+/*
+templateHeuristic produces values for field names containing one of its
+words, delimited by underscores: "name" matches "full_name" but not
+"username". Words ending in an underscore only match as a prefix ("is_")
+and words starting with one only as a suffix ("_at").
+*/
+type templateHeuristic struct {
+	words []string
+	value func(rng *rand.Rand, row int, field string) interface{}
+}
+
+var templateEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// templateHeuristics are tried in order, so specific words come before
+// the generic ones they contain ("first_name" before "name")
+var templateHeuristics = []templateHeuristic{
+	{[]string{"email"}, func(rng *rand.Rand, row int, field string) interface{} {
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(pick(rng, fakerFirstNames)), strings.ToLower(pick(rng, fakerLastNames)), row)
+	}},
+	{[]string{"first_name", "firstname"}, func(rng *rand.Rand, row int, field string) interface{} { return pick(rng, fakerFirstNames) }},
+	{[]string{"last_name", "lastname", "surname"}, func(rng *rand.Rand, row int, field string) interface{} { return pick(rng, fakerLastNames) }},
+	{[]string{"product"}, func(rng *rand.Rand, row int, field string) interface{} { return pick(rng, fakerProducts) }},
+	{[]string{"name"}, func(rng *rand.Rand, row int, field string) interface{} {
+		return pick(rng, fakerFirstNames) + " " + pick(rng, fakerLastNames)
+	}},
+	{[]string{"age", "years"}, func(rng *rand.Rand, row int, field string) interface{} { return float64(18 + rng.Intn(63)) }},
+	{[]string{"city"}, func(rng *rand.Rand, row int, field string) interface{} { return pick(rng, fakerCities) }},
+	{[]string{"country"}, func(rng *rand.Rand, row int, field string) interface{} { return pick(rng, fakerCountries) }},
+	{[]string{"phone"}, func(rng *rand.Rand, row int, field string) interface{} {
+		return fmt.Sprintf("+1-555-%03d-%04d", rng.Intn(1000), rng.Intn(10000))
+	}},
+	{[]string{"price", "amount", "total", "salary", "cost"}, func(rng *rand.Rand, row int, field string) interface{} {
+		return float64(rng.Intn(100000)+100) / 100
+	}},
+	{[]string{"status"}, func(rng *rand.Rand, row int, field string) interface{} { return pick(rng, fakerStatuses) }},
+	{[]string{"created_at", "updated_at", "timestamp", "time"}, func(rng *rand.Rand, row int, field string) interface{} {
+		return templateEpoch.Add(time.Duration(rng.Intn(365*24*3600)) * time.Second).Format(time.RFC3339)
+	}},
+	{[]string{"date", "birthday", "dob", "_at", "_on"}, func(rng *rand.Rand, row int, field string) interface{} {
+		return templateEpoch.AddDate(0, 0, rng.Intn(365)).Format("2006-01-02")
+	}},
+	{[]string{"is_", "has_", "active", "enabled"}, func(rng *rand.Rand, row int, field string) interface{} { return rng.Intn(2) == 1 }},
+	{[]string{"count", "quantity", "stock"}, func(rng *rand.Rand, row int, field string) interface{} { return float64(rng.Intn(100) + 1) }},
+}
+
+// templateDefaultFields are used when the scenario names no known field
+var templateDefaultFields = []string{"id", "name", "email", "age", "city", "created_at"}
+
+// templateWord splits a scenario into candidate field names
+var templateWord = regexp.MustCompile(`[a-z][a-z0-9_]*`)
+
+// TemplateModelName is recorded as the model of template generations
+const TemplateModelName = "template"
+
+// ModelName implements ModelNamer
+func (s *TemplateService) ModelName(opts GenerateOptions) string {
+	return TemplateModelName
+}
+
+// GenerateMockData implements Generator
+func (s *TemplateService) GenerateMockData(ctx context.Context, scenario string, rowCount int, opts GenerateOptions) ([]map[string]interface{}, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	fields := opts.Fields
+	if len(opts.Schema) > 0 {
+		fields = SchemaFieldNames(opts.Schema)
+	} else if len(fields) == 0 {
+		fields = templateFieldsFor(scenario, opts.ReferenceValues)
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(scenario))
+	if opts.Seed != nil {
+		fmt.Fprintf(hash, "\x00%d", *opts.Seed)
+	}
+	seed := int64(hash.Sum64())
+
+	data := make([]map[string]interface{}, rowCount)
+	for i := range data {
+		row := opts.RowOffset + i + 1
+		rng := rand.New(rand.NewSource(seed + int64(row)))
+
+		data[i] = make(map[string]interface{}, len(fields))
+		for j, field := range fields {
+			if len(opts.Schema) > 0 {
+				data[i][field] = schemaValue(rng, row, opts.Schema[j], opts.ReferenceValues[field])
+			} else {
+				data[i][field] = templateValue(rng, row, field, opts.ReferenceValues[field])
+			}
+		}
+	}
+
+	return data, append([]string{}, fields...), nil
+}
+
+// templateFieldsFor picks the fields for a scenario: an id, then every
+// scenario word a heuristic recognizes (singular, "emails" → "email"),
+// then reference fields
+func templateFieldsFor(scenario string, references map[string][]string) []string {
+	fields := []string{"id"}
+
+	for _, word := range templateWord.FindAllString(strings.ToLower(scenario), -1) {
+		if templateHeuristicFor(word) == nil {
+			word = strings.TrimSuffix(word, "s")
+			if templateHeuristicFor(word) == nil {
+				continue
+			}
+		}
+		if !containsString(fields, word) {
+			fields = append(fields, word)
+		}
+	}
+	if len(fields) == 1 {
+		fields = append([]string{}, templateDefaultFields...)
+	}
+
+	for _, name := range sortedKeys(references) {
+		if len(references[name]) > 0 && !containsString(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+// templateHeuristicFor returns the first heuristic matching a field name, if any
+func templateHeuristicFor(field string) *templateHeuristic {
+	name := strings.ToLower(field)
+	for i, heuristic := range templateHeuristics {
+		for _, word := range heuristic.words {
+			var match bool
+			switch {
+			case strings.HasSuffix(word, "_"):
+				match = strings.HasPrefix(name, word)
+			case strings.HasPrefix(word, "_"):
+				match = strings.HasSuffix(name, word)
+			default:
+				match = strings.Contains("_"+name+"_", "_"+word+"_")
+			}
+			if match {
+				return &templateHeuristics[i]
+			}
+		}
+	}
+	return nil
+}
+
+// templateValue returns the value of one field in the given (one-based) row
+func templateValue(rng *rand.Rand, row int, field string, references []string) interface{} {
+	switch {
+	case len(references) > 0:
+		return pick(rng, references)
+	case field == "id" || strings.HasSuffix(field, "_id"):
+		return float64(row)
+	}
+
+	if heuristic := templateHeuristicFor(field); heuristic != nil {
+		return heuristic.value(rng, row, field)
+	}
+	return fmt.Sprintf("%s %d", field, row)
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplateService_GenerateMockData tests row counts, fields and determinism
+func TestTemplateService_GenerateMockData(t *testing.T) {
+	ctx := context.Background()
+	svc := NewTemplateService()
+
+	t.Run("Fields named in the scenario", func(t *testing.T) {
+		data, fields, err := svc.GenerateMockData(ctx, "Users with names, emails, age, city and signup date", 25, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name", "email", "age", "city", "date"}, fields)
+		require.Len(t, data, 25)
+
+		for i, row := range data {
+			assert.Len(t, row, len(fields), "Every row has exactly the fields")
+			assert.Equal(t, float64(i+1), row["id"])
+			assert.Contains(t, row["email"], "@example.com")
+			assert.Contains(t, fakerCities, row["city"])
+			assert.GreaterOrEqual(t, row["age"], float64(18))
+			assert.Regexp(t, `^\d{4}-\d{2}-\d{2}$`, row["date"])
+		}
+	})
+
+	t.Run("Default fields", func(t *testing.T) {
+		data, fields, err := svc.GenerateMockData(ctx, "Something entirely unspecific", 3, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, templateDefaultFields, fields)
+		assert.Len(t, data, 3)
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		first, _, err := svc.GenerateMockData(ctx, "customers", 10, GenerateOptions{})
+		require.NoError(t, err)
+		second, _, err := svc.GenerateMockData(ctx, "customers", 10, GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		continued, _, err := svc.GenerateMockData(ctx, "customers", 4, GenerateOptions{RowOffset: 6})
+		require.NoError(t, err)
+		assert.Equal(t, first[6:], continued, "Continued rows match the same rows of a single request")
+	})
+
+	t.Run("Given fields and references", func(t *testing.T) {
+		opts := GenerateOptions{
+			Fields:          []string{"id", "customer_id", "is_active", "notes"},
+			ReferenceValues: map[string][]string{"notes": {"a", "b"}},
+		}
+		data, fields, err := svc.GenerateMockData(ctx, "orders", 5, opts)
+		require.NoError(t, err)
+		assert.Equal(t, opts.Fields, fields)
+		for i, row := range data {
+			assert.Equal(t, float64(i+1), row["customer_id"])
+			assert.IsType(t, true, row["is_active"])
+			assert.Contains(t, []string{"a", "b"}, row["notes"])
+		}
+	})
+
+	t.Run("Respects cancellation", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _, err := svc.GenerateMockData(cancelled, "users", 5, GenerateOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestTemplateHeuristicFor tests matching field names to heuristics
+func TestTemplateHeuristicFor(t *testing.T) {
+	same := func(field, other string) bool { return templateHeuristicFor(field) == templateHeuristicFor(other) }
+
+	assert.True(t, same("full_name", "name"))
+	assert.True(t, same("created_at", "timestamp"))
+	assert.True(t, same("shipped_at", "date"))
+	assert.False(t, same("first_name", "name"), "Specific heuristics come first")
+	assert.Nil(t, templateHeuristicFor("username"), "Words match whole parts only")
+	assert.Nil(t, templateHeuristicFor("management"))
+	assert.Nil(t, templateHeuristicFor("is"))
+}
+
+// TestTemplateService_Seed tests reproducing and varying output with a seed
+func TestTemplateService_Seed(t *testing.T) {
+	ctx := context.Background()
+	svc := NewTemplateService()
+	generate := func(seed int) []map[string]interface{} {
+		data, _, err := svc.GenerateMockData(ctx, "customers with name, email and city", 20, GenerateOptions{Seed: &seed})
+		require.NoError(t, err)
+		return data
+	}
+
+	assert.Equal(t, generate(7), generate(7), "The same seed, scenario and count give the same data")
+	assert.NotEqual(t, generate(7), generate(8))
+
+	unseeded, _, err := svc.GenerateMockData(ctx, "customers with name, email and city", 20, GenerateOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, unseeded, generate(0), "A zero seed differs from no seed")
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToTemplate tests rendering with a sample INSERT template
+func TestToTemplate(t *testing.T) {
+	service := NewExportService()
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "O'Brien", "active": true},
+		{"id": float64(2), "name": "Smith", "active": false},
+	}
+	fields := []string{"id", "name", "active"}
+
+	t.Run("Rows with header, footer and separator", func(t *testing.T) {
+		separator := ",\n"
+		result, err := service.ToTemplate(data, fields, TemplateSpec{
+			Header:    "INSERT INTO {{ident \"people\"}} ({{join .Fields \", \"}}) VALUES\n",
+			Row:       "({{sql .Row.id}}, {{sql .Row.name}}, {{sql .Row.active}})",
+			Footer:    ";\n-- {{.Count}} rows",
+			Separator: &separator,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO people (id, name, active) VALUES\n(1, 'O''Brien', TRUE),\n(2, 'Smith', FALSE);\n-- 2 rows", string(result))
+	})
+
+	t.Run("Default separator and helpers", func(t *testing.T) {
+		result, err := service.ToTemplate(data, fields, TemplateSpec{
+			Row: "{{.Index}}:{{upper (value .Row.name)}}:{{json .Row.active}}{{if last .Index .Count}}.{{end}}",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "0:O'BRIEN:true\n1:SMITH:false.", string(result))
+	})
+
+	t.Run("Row template is required", func(t *testing.T) {
+		_, err := service.ToTemplate(data, fields, TemplateSpec{Header: "x"})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+	})
+
+	t.Run("Parse errors", func(t *testing.T) {
+		_, err := service.ToTemplate(data, fields, TemplateSpec{Row: "{{.Row.id"})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+	})
+
+	t.Run("Template too long", func(t *testing.T) {
+		_, err := service.ToTemplate(data, fields, TemplateSpec{Row: strings.Repeat("x", MaxTemplateLength+1)})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+	})
+
+	t.Run("Call is disabled", func(t *testing.T) {
+		_, err := service.ToTemplate(data, fields, TemplateSpec{Row: "{{call .Row.id}}"})
+		assert.ErrorIs(t, err, ErrInvalidExportOption)
+		assert.ErrorContains(t, err, "call is not available")
+	})
+
+	t.Run("Output is capped", func(t *testing.T) {
+		// Each row expands to far more than the output limit
+		row := `{{define "x4"}}{{.}}{{.}}{{.}}{{.}}{{end}}{{define "x16"}}{{template "x4" .}}{{template "x4" .}}{{template "x4" .}}{{template "x4" .}}{{end}}` +
+			`{{template "x16" (printf "%0*d" 900000 0)}}`
+		_, err := service.ToTemplate(data, fields, TemplateSpec{Row: row})
+		assert.ErrorIs(t, err, ErrTemplateLimit)
+	})
+
+	t.Run("No data", func(t *testing.T) {
+		_, err := service.ToTemplate(nil, fields, TemplateSpec{Row: "x"})
+		assert.ErrorIs(t, err, ErrNoData)
+	})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+InferFieldTypes infers a SQL column type for every field by scanning the
+whole column rather than just the first row.
+
+Nulls and missing values are ignored. A column whose non-null values are all
+numbers is NUMERIC, all booleans is BOOLEAN; anything mixed, textual or
+entirely null is TEXT.
+*/
+func InferFieldTypes(data []map[string]interface{}, fieldNames []string) map[string]string {
+	return inferColumns(data, fieldNames, inferSQLType, "TEXT")
+}
+
+// Value types reported to clients by InferValueTypes
+const (
+	ValueTypeString   = "string"
+	ValueTypeNumber   = "number"
+	ValueTypeBoolean  = "boolean"
+	ValueTypeDate     = "date"
+	ValueTypeDateTime = "datetime"
+)
+
+/*
+InferValueTypes infers a display type per field for clients: number,
+boolean, date (YYYY-MM-DD strings), datetime (RFC3339 strings) or string.
+
+It scans whole columns like InferFieldTypes; mixed or entirely null columns
+are string.
+*/
+func InferValueTypes(data []map[string]interface{}, fieldNames []string) map[string]string {
+	return inferColumns(data, fieldNames, valueType, ValueTypeString)
+}
+
+/*
+StoredValueTypes reports the display type per field like InferValueTypes,
+but prefers the stored SQL column types of a dataset (set by coercion or
+type inference) over what the values look like.
+
+Fields without a stored type, or with one that has no display type (e.g.
+JSONB), are inferred from their values.
+*/
+func StoredValueTypes(data []map[string]interface{}, fieldNames []string, stored map[string]string) map[string]string {
+	types := InferValueTypes(data, fieldNames)
+	for _, field := range fieldNames {
+		if valueType, ok := sqlValueType(stored[field]); ok {
+			types[field] = valueType
+		}
+	}
+	return types
+}
+
+// sqlValueType maps a SQL column type to the display type of its values
+func sqlValueType(colType string) (string, bool) {
+	base := strings.ToUpper(strings.TrimSpace(colType))
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+
+	switch base {
+	case "SMALLINT", "INTEGER", "INT", "INT2", "INT4", "INT8", "BIGINT", "SERIAL", "BIGSERIAL", "SMALLSERIAL",
+		"NUMERIC", "DECIMAL", "REAL", "DOUBLE PRECISION", "FLOAT", "FLOAT4", "FLOAT8", "MONEY":
+		return ValueTypeNumber, true
+	case "BOOLEAN", "BOOL":
+		return ValueTypeBoolean, true
+	case "DATE":
+		return ValueTypeDate, true
+	case "TIMESTAMP", "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE":
+		return ValueTypeDateTime, true
+	case "TEXT", "VARCHAR", "CHARACTER VARYING", "CHAR", "CHARACTER", "UUID":
+		return ValueTypeString, true
+	default:
+		return "", false
+	}
+}
+
+// valueType classifies a single non-null value for InferValueTypes
+func valueType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return ValueTypeBoolean
+	case float64, json.Number, int, int64:
+		return ValueTypeNumber
+	case string:
+		if _, err := time.Parse("2006-01-02", v); err == nil {
+			return ValueTypeDate
+		}
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			return ValueTypeDateTime
+		}
+	}
+	return ValueTypeString
+}
+
+// inferColumns classifies every non-null value of each field and keeps the
+// common class, falling back to fallback for mixed or empty columns
+func inferColumns(data []map[string]interface{}, fieldNames []string, classify func(interface{}) string, fallback string) map[string]string {
+	types := make(map[string]string, len(fieldNames))
+
+	for _, field := range fieldNames {
+		colType := ""
+		for _, row := range data {
+			value := row[field]
+			if value == nil {
+				continue
+			}
+
+			valueType := classify(value)
+			if colType == "" {
+				colType = valueType
+			} else if colType != valueType {
+				colType = fallback
+				break
+			}
+		}
+
+		if colType == "" {
+			colType = fallback
+		}
+		types[field] = colType
+	}
+
+	return types
+}
+
+// sqlTypePattern accepts type names like TEXT, DOUBLE PRECISION or NUMERIC(10,2)
+var sqlTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*\d+\s*(,\s*\d+\s*)?\))?$`)
+
+/*
+ValidateFieldTypes checks user-supplied type overrides.
+
+Every field must exist in the dataset and every type must look like a plain
+SQL type name, since it is written verbatim into CREATE TABLE. Type names are
+returned upper-cased.
+*/
+func ValidateFieldTypes(overrides map[string]string, fieldNames []string) (map[string]string, error) {
+	result := make(map[string]string, len(overrides))
+
+	for field, colType := range overrides {
+		if !containsString(fieldNames, field) {
+			return nil, fmt.Errorf("%w: '%s' is not a field of this dataset", ErrInvalidExportOption, field)
+		}
+
+		colType = strings.TrimSpace(colType)
+		if !sqlTypePattern.MatchString(colType) {
+			return nil, fmt.Errorf("%w: '%s' is not a valid SQL type for field '%s'", ErrInvalidExportOption, colType, field)
+		}
+		result[field] = strings.ToUpper(colType)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInferFieldTypes tests whole-column type inference
+func TestInferFieldTypes(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": nil, "active": true, "code": float64(7), "empty": nil},
+		{"id": float64(2), "name": "Jane", "active": false, "code": "A7"},
+	}
+	fieldNames := []string{"id", "name", "active", "code", "empty"}
+
+	types := InferFieldTypes(data, fieldNames)
+
+	assert.Equal(t, map[string]string{
+		"id":     "NUMERIC",
+		"name":   "TEXT",
+		"active": "BOOLEAN",
+		"code":   "TEXT",
+		"empty":  "TEXT",
+	}, types)
+}
+
+// TestInferValueTypes tests the display types reported to clients
+func TestInferValueTypes(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "big": json.Number("9007199254740993"), "active": true, "born": "1990-04-01", "seen": "2024-01-15T10:30:00Z", "name": "Jane", "mixed": "2024-01-15", "empty": nil},
+		{"id": float64(2), "big": json.Number("2"), "active": nil, "born": "1985-12-24", "seen": "2024-01-16T08:00:00+02:00", "name": "2024", "mixed": float64(3)},
+	}
+	fieldNames := []string{"id", "big", "active", "born", "seen", "name", "mixed", "empty"}
+
+	types := InferValueTypes(data, fieldNames)
+
+	assert.Equal(t, map[string]string{
+		"id":     ValueTypeNumber,
+		"big":    ValueTypeNumber,
+		"active": ValueTypeBoolean,
+		"born":   ValueTypeDate,
+		"seen":   ValueTypeDateTime,
+		"name":   ValueTypeString,
+		"mixed":  ValueTypeString,
+		"empty":  ValueTypeString,
+	}, types)
+
+	// Every field gets a type, and the map covers nothing else
+	assert.Len(t, types, len(fieldNames))
+}
+
+// TestStoredValueTypes tests that stored column types win over inference
+func TestStoredValueTypes(t *testing.T) {
+	data := []map[string]interface{}{
+		{"zip": "02134", "price": "12.50", "active": "yes", "born": "1990-04-01", "meta": float64(1), "name": "Jane"},
+	}
+	fieldNames := []string{"zip", "price", "active", "born", "meta", "name"}
+	stored := map[string]string{
+		"zip":    "TEXT",
+		"price":  "NUMERIC(10,2)",
+		"active": "BOOLEAN",
+		"born":   "timestamp",
+		"meta":   "JSONB",
+	}
+
+	types := StoredValueTypes(data, fieldNames, stored)
+
+	assert.Equal(t, map[string]string{
+		"zip":    ValueTypeString,
+		"price":  ValueTypeNumber,
+		"active": ValueTypeBoolean,
+		"born":   ValueTypeDateTime,
+		"meta":   ValueTypeNumber, // no display type for JSONB, so inferred
+		"name":   ValueTypeString, // no stored type, so inferred
+	}, types)
+
+	assert.Equal(t, InferValueTypes(data, fieldNames), StoredValueTypes(data, fieldNames, nil), "Without stored types everything is inferred")
+}
+
+// TestValidateFieldTypes tests validation of type overrides
+func TestValidateFieldTypes(t *testing.T) {
+	fieldNames := []string{"id", "price"}
+
+	types, err := ValidateFieldTypes(map[string]string{"id": "integer", "price": "numeric(10, 2)"}, fieldNames)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"id": "INTEGER", "price": "NUMERIC(10, 2)"}, types)
+
+	types, err = ValidateFieldTypes(map[string]string{"price": "double precision"}, fieldNames)
+	require.NoError(t, err)
+	assert.Equal(t, "DOUBLE PRECISION", types["price"])
+
+	_, err = ValidateFieldTypes(map[string]string{"email": "TEXT"}, fieldNames)
+	assert.ErrorIs(t, err, ErrInvalidExportOption, "Unknown field")
+
+	_, err = ValidateFieldTypes(map[string]string{"id": "INT); DROP TABLE users; --"}, fieldNames)
+	assert.ErrorIs(t, err, ErrInvalidExportOption, "Injection attempt")
+
+	_, err = ValidateFieldTypes(map[string]string{"id": ""}, fieldNames)
+	assert.ErrorIs(t, err, ErrInvalidExportOption, "Empty type")
+}
+
+// TestExportService_ToSQL_FieldTypes tests that stored field types override inference
+func TestExportService_ToSQL_FieldTypes(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": float64(1), "price": nil},
+		{"id": float64(2), "price": 9.99},
+	}
+
+	opts := DefaultExportOptions()
+	opts.FieldTypes = map[string]string{"id": "INTEGER", "price": "NUMERIC(10,2)"}
+	result, err := NewExportService().WithOptions(opts).ToSQL(data, []string{"id", "price"}, "items")
+	require.NoError(t, err)
+
+	sql := string(result)
+	assert.Contains(t, sql, "  id INTEGER PRIMARY KEY,\n")
+	assert.Contains(t, sql, "  price NUMERIC(10,2)\n")
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultTypeName names generated TypeScript interfaces when no name is given
+const DefaultTypeName = "MockData"
+
+// tsIdentifier matches property names that need no quotes
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+/*
+ToTypeScript exports the shape of the data as a TypeScript interface:
+
+	export interface MockData {
+	  id: number;
+	  email: string | null;
+	}
+
+Each field is typed number, boolean or string from all of its values, and
+unknown when it mixes types or holds objects. Fields with a null or missing
+value in any row get "| null"; entirely null fields are typed null. The
+name is turned into a PascalCase identifier, DefaultTypeName when empty.
+*/
+func (s *ExportService) ToTypeScript(data []map[string]interface{}, fieldNames []string, name string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	types := inferColumns(data, fieldNames, tsType, "unknown")
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("export interface %s {\n", typeName(name)))
+
+	for _, field := range fieldNames {
+		property := field
+		if !tsIdentifier.MatchString(field) {
+			quoted, _ := json.Marshal(field)
+			property = string(quoted)
+		}
+
+		nulls := 0
+		for _, row := range data {
+			if row[field] == nil {
+				nulls++
+			}
+		}
+
+		fieldType := types[field]
+		switch {
+		case nulls == len(data):
+			fieldType = "null"
+		case nulls > 0:
+			fieldType += " | null"
+		}
+
+		buf.WriteString(fmt.Sprintf("  %s: %s;\n", property, fieldType))
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// tsType classifies a single non-null value as a TypeScript type
+func tsType(value interface{}) string {
+	switch value.(type) {
+	case float64, json.Number, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	return "unknown"
+}
+
+// typeName turns a name like "hospital patients" into HospitalPatients
+func typeName(name string) string {
+	var out strings.Builder
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if out.Len() == 0 && unicode.IsDigit(r) {
+			out.WriteByte('_')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		out.WriteRune(r)
+	}
+
+	if out.Len() == 0 {
+		return DefaultTypeName
+	}
+	return out.String()
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// TestExportService_ToXLSX tests Excel export functionality
+func TestExportService_ToXLSX(t *testing.T) {
+	service := NewExportService()
+
+	data := []map[string]interface{}{
+		{"id": float64(1), "name": "John & Sons", "age": float64(30), "active": true, "score": 9.5},
+		{"id": float64(2), "name": "Jane", "age": nil, "active": false, "score": float64(8)},
+	}
+	fieldNames := []string{"id", "name", "age", "active", "score"}
+
+	result, err := service.ToXLSX(data, fieldNames)
+	require.NoError(t, err, "ToXLSX should not return an error")
+
+	f, err := excelize.OpenReader(bytes.NewReader(result))
+	require.NoError(t, err, "Export should be a valid workbook")
+	defer f.Close()
+
+	assert.Equal(t, []string{xlsxSheet}, f.GetSheetList())
+
+	rows, err := f.GetRows(xlsxSheet)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "name", "age", "active", "score"},
+		{"1", "John & Sons", "30", "TRUE", "9.5"},
+		{"2", "Jane", "", "FALSE", "8"},
+	}, rows, "The null age is left empty")
+
+	style, err := f.GetCellStyle(xlsxSheet, "A1")
+	require.NoError(t, err)
+	header, err := f.GetStyle(style)
+	require.NoError(t, err)
+	require.NotNil(t, header.Font)
+	assert.True(t, header.Font.Bold, "Header should be bold")
+
+	cellType, err := f.GetCellType(xlsxSheet, "D2")
+	require.NoError(t, err)
+	assert.Equal(t, excelize.CellTypeBool, cellType, "Booleans are boolean cells")
+
+	cellType, err = f.GetCellType(xlsxSheet, "E2")
+	require.NoError(t, err)
+	assert.NotEqual(t, excelize.CellTypeSharedString, cellType, "Numbers are numeric cells")
+
+	width, err := f.GetColWidth(xlsxSheet, "A")
+	require.NoError(t, err)
+	assert.Equal(t, float64(xlsxMinColumnWidth), width)
+	width, err = f.GetColWidth(xlsxSheet, "B")
+	require.NoError(t, err)
+	assert.Equal(t, float64(len("John & Sons")+2), width, "Columns fit their longest value")
+}
+
+// TestExportService_ToXLSX_NullAs tests writing nulls as the null_as text
+func TestExportService_ToXLSX_NullAs(t *testing.T) {
+	service := NewExportService().WithOptions(ExportOptions{NullAs: "N/A"})
+
+	result, err := service.ToXLSX([]map[string]interface{}{{"name": nil}}, []string{"name"})
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(result))
+	require.NoError(t, err)
+	defer f.Close()
+
+	value, err := f.GetCellValue(xlsxSheet, "A2")
+	require.NoError(t, err)
+	assert.Equal(t, "N/A", value)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+ToYAML exports the same {fields, data, count} document as ToJSON in YAML.
+
+Row keys follow fieldNames, and whole-number floats are written as integers
+(30 rather than 30.0), matching the CSV output. Generation metadata becomes
+leading "#" comments.
+*/
+func (s *ExportService) ToYAML(data []map[string]interface{}, fieldNames []string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrNoData
+	}
+
+	fieldNames, err := s.options.resolveFields(data, fieldNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if replacement, ok := s.options.jsonNull(); ok {
+		data = replaceNulls(data, fieldNames, replacement)
+	}
+
+	rows := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, row := range data {
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, field := range fieldNames {
+			value := &yaml.Node{}
+			if err := value.Encode(yamlValue(row[field])); err != nil {
+				return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+			}
+			mapping.Content = append(mapping.Content, yamlString(field), value)
+		}
+		rows.Content = append(rows.Content, mapping)
+	}
+
+	fields := &yaml.Node{}
+	if err := fields.Encode(fieldNames); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	document := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		yamlString("fields"), fields,
+		yamlString("data"), rows,
+		yamlString("count"), {Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(len(data))},
+	}}
+
+	var buf bytes.Buffer
+	if s.options.Metadata != nil {
+		buf.WriteString(s.options.Metadata.forRows(data).commentLines("# "))
+	}
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// yamlString returns a plain string scalar node
+func yamlString(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// yamlValue converts JSON-decoded numbers so whole numbers encode as integers
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+	return value
+}